@@ -4,6 +4,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 
@@ -12,6 +13,11 @@ import (
 )
 
 func main() {
+	// Chamado pelo make como SHELL (ver bmt make)
+	if filepath.Base(os.Args[0]) == commands.MakeShellName {
+		os.Exit(commands.RunMakeShell(os.Args[1:]))
+	}
+
 	flag.Usage = func() {
 		fmt.Println("BMT - Build Metric Tool")
 		fmt.Println("\nComandos disponíveis:")
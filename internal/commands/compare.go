@@ -0,0 +1,202 @@
+package commands
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"strings"
+
+	"dev-metrics/internal/metrics"
+	"dev-metrics/internal/runner"
+	"dev-metrics/internal/stats"
+)
+
+const (
+	compareSeparator   = ":::"
+	compareConfidence  = 0.95  // Nível do intervalo de confiança do speedup
+	compareResamples   = 10000 // Reamostragens do bootstrap
+	compareSignificant = 0.05  // p-valor abaixo do qual a diferença é significativa
+)
+
+// CompareCommand mede dois comandos de forma intercalada e compara as durações.
+type CompareCommand struct {
+	Out io.Writer
+	// Exec mede e registra cada execução; seus campos permitem substituir o
+	// runner, o -prepare e o salvamento nos testes
+	Exec *ExecCommand
+}
+
+func (c *CompareCommand) Name() string { return "compare" }
+func (c *CompareCommand) Description() string {
+	return "Compara a duração de dois comandos (A/B) com execuções intercaladas e teste estatístico"
+}
+
+func (c *CompareCommand) Run(args []string) error {
+	c.ensureDefaults()
+	fs := flag.NewFlagSet("compare", flag.ContinueOnError)
+	fs.SetOutput(c.Out)
+	logFlag := fs.String("log", "", "Caminho customizado para o arquivo de log")
+	nFlag := fs.Int("n", 10, "Execuções medidas de cada comando")
+	warmupFlag := fs.Int("warmup", 0, "Execuções de aquecimento de cada comando, não registradas")
+	prepareFlag := fs.String("prepare", "", "Comando de shell executado antes de cada execução (ex.: \"make clean\")")
+
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Uso: bmt compare [-n N] [-warmup K] [-prepare cmd] [-log path] -- <comando A> ::: <comando B>\n")
+		fs.PrintDefaults()
+		fmt.Fprintf(fs.Output(), "%s", `As execuções são intercaladas (A B B A A B ...) para compensar aquecimento
+da máquina e de caches. Exemplo:
+  bmt compare -n 10 -prepare "rm -rf build" -- cmake --build build ::: ninja -C build
+`)
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	cmdA, cmdB, err := splitCompareArgs(fs.Args())
+	if err != nil {
+		fs.Usage()
+		return err
+	}
+	if *nFlag < 2 || *warmupFlag < 0 {
+		return errors.New("-n deve ser pelo menos 2 e -warmup não pode ser negativo")
+	}
+
+	logPath, err := metrics.GetLogFilePath(*logFlag)
+	if err != nil {
+		return fmt.Errorf("erro ao resolver caminho do log: %v", err)
+	}
+
+	ctx, cancel := interruptContext()
+	defer cancel()
+
+	cfg := c.Exec.loadConfig()
+	settings := runSettings{
+		logPath:   logPath,
+		runner:    runner.Options{OutputTail: runner.DefaultOutputTail},
+		probes:    cfg.Probes,
+		toolchain: true,
+	}
+	settings.branch, settings.commit, settings.project = c.Exec.GitInfo()
+	variants := []struct {
+		name string
+		args []string
+	}{{"A", cmdA}, {"B", cmdB}}
+
+	for i := 1; i <= *warmupFlag; i++ {
+		for _, v := range variants {
+			if err := c.Exec.runPrepare(ctx, *prepareFlag); err != nil {
+				return err
+			}
+			result := c.Exec.Runner(ctx, v.args, settings.runner)
+			if ctx.Err() != nil {
+				return errors.New("comparação interrompida durante o aquecimento")
+			}
+			if result.ExitCode != 0 {
+				return fmt.Errorf("aquecimento %d/%d de %s falhou (código %d)", i, *warmupFlag, v.name, result.ExitCode)
+			}
+		}
+	}
+
+	settings.benchmarkID = c.Exec.NewID()
+	durations := make([][]float64, len(variants))
+	for round := 1; round <= *nFlag; round++ {
+		// Alterna a ordem a cada rodada (A B, B A, ...) para que uma tendência
+		// ao longo do tempo afete os dois comandos por igual
+		order := []int{0, 1}
+		if round%2 == 0 {
+			order = []int{1, 0}
+		}
+		for _, i := range order {
+			v := variants[i]
+			if err := c.Exec.runPrepare(ctx, *prepareFlag); err != nil {
+				return err
+			}
+			s := settings
+			s.iteration, s.variant = round, v.name
+			m := c.Exec.measure(ctx, v.args, cfg, s)
+			fmt.Fprintf(c.Out, "%s %d/%d: %s\n", v.name, round, *nFlag, metrics.FormatDuration(m.DurationSec, metrics.DurationAuto, true))
+			if m.Status != "success" {
+				return fmt.Errorf("%s %d/%d terminou com status %s (código %d); comparação interrompida", v.name, round, *nFlag, m.Status, m.ReturnCode)
+			}
+			durations[i] = append(durations[i], m.DurationSec)
+		}
+	}
+
+	c.printComparison(cmdA, cmdB, durations[0], durations[1], settings.benchmarkID)
+	return nil
+}
+
+// splitCompareArgs separa os dois comandos em volta de ":::".
+func splitCompareArgs(args []string) ([]string, []string, error) {
+	sep := -1
+	for i, a := range args {
+		if a != compareSeparator {
+			continue
+		}
+		if sep >= 0 {
+			return nil, nil, fmt.Errorf("use %q uma única vez, entre os dois comandos", compareSeparator)
+		}
+		sep = i
+	}
+	if sep <= 0 || sep == len(args)-1 {
+		return nil, nil, fmt.Errorf("informe dois comandos separados por %q", compareSeparator)
+	}
+	return args[:sep], args[sep+1:], nil
+}
+
+// printComparison mostra a distribuição de cada comando, o speedup de B em
+// relação a A (razão das medianas, com intervalo de confiança por bootstrap) e
+// o teste de Mann-Whitney U.
+func (c *CompareCommand) printComparison(cmdA, cmdB []string, a, b []float64, benchmarkID string) {
+	format := func(sec float64) string { return metrics.FormatDuration(sec, metrics.DurationAuto, true) }
+
+	fmt.Fprintf(c.Out, "\n------BMT compare------\n")
+	for _, v := range []struct {
+		name      string
+		args      []string
+		durations []float64
+	}{{"A", cmdA, a}, {"B", cmdB, b}} {
+		summary := stats.Summarize(v.durations)
+		fmt.Fprintf(c.Out, "- %s: %s\n  mediana %s, média %s ± %s, faixa %s … %s (%d execuções)\n",
+			v.name, strings.Join(v.args, " "), format(summary.Median), format(summary.Mean),
+			format(stats.StdDev(v.durations)), format(summary.Min), format(summary.Max), summary.Count)
+	}
+
+	speedup := stats.Median(a) / stats.Median(b)
+	lo, hi := stats.BootstrapRatioCI(a, b, stats.Median, compareResamples, compareConfidence, rand.New(rand.NewSource(1)))
+	verdict := "mais rápido"
+	if speedup < 1 {
+		speedup, lo, hi = 1/speedup, 1/hi, 1/lo
+		verdict = "mais lento"
+	}
+	fmt.Fprintf(c.Out, "- B é %.2fx %s que A (IC %.0f%%: %.2fx … %.2fx)\n", speedup, verdict, compareConfidence*100, lo, hi)
+
+	u, p := stats.MannWhitneyU(a, b)
+	significance := "diferença significativa"
+	if p >= compareSignificant {
+		significance = "sem diferença significativa"
+	}
+	fmt.Fprintf(c.Out, "- Mann-Whitney U = %.1f, p = %.4f: %s (α = %.2f)\n", u, p, significance, compareSignificant)
+	fmt.Fprintf(c.Out, "- Comparação %s; veja com: bmt report --benchmarks only\n", benchmarkID)
+	fmt.Fprintf(c.Out, "---------------\n")
+}
+
+func (c *CompareCommand) ensureDefaults() {
+	if c.Out == nil {
+		c.Out = os.Stdout
+	}
+	if c.Exec == nil {
+		c.Exec = &ExecCommand{}
+	}
+	c.Exec.ensureDefaults()
+}
+
+func (c *CompareCommand) Aliases() []string {
+	return []string{}
+}
+
+func init() {
+	Register(&CompareCommand{})
+}
@@ -0,0 +1,128 @@
+package commands_test
+
+import (
+	"bytes"
+	"context"
+	"dev-metrics/internal/commands"
+	"dev-metrics/internal/metrics"
+	"dev-metrics/internal/runner"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newCompareExec devolve um ExecCommand que não toca a máquina: o runner
+// responde com a duração de cada comando em durations.
+func newCompareExec(t *testing.T, durations map[string][]float64, saved *[]metrics.BuildMetric, order *[]string) *commands.ExecCommand {
+	calls := map[string]int{}
+	exec := newTestExecCommand(t)
+	exec.Runner = func(ctx context.Context, args []string, opts runner.Options) runner.Result {
+		name := args[0]
+		*order = append(*order, name)
+		d := durations[name][calls[name]%len(durations[name])]
+		calls[name]++
+		return runner.Result{DurationSec: d}
+	}
+	exec.MetricsSaver = func(m metrics.BuildMetric, filePath string) error { *saved = append(*saved, m); return nil }
+	return exec
+}
+
+func TestCompareCommand_Run(t *testing.T) {
+	tests := []struct {
+		name        string
+		durations   map[string][]float64
+		wantVerdict string
+	}{
+		{
+			name:        "B mais rápido",
+			durations:   map[string][]float64{"old": {20, 21, 19, 20.5}, "new": {10, 10.5, 9.5, 10.2}},
+			wantVerdict: "mais rápido que A",
+		},
+		{
+			name:        "B mais lento",
+			durations:   map[string][]float64{"old": {10, 10.5, 9.5, 10.2}, "new": {20, 21, 19, 20.5}},
+			wantVerdict: "mais lento que A",
+		},
+		{
+			name:        "Sem diferença",
+			durations:   map[string][]float64{"old": {10, 12, 11, 13}, "new": {10.5, 11.5, 12.5, 11}},
+			wantVerdict: "sem diferença significativa",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var saved []metrics.BuildMetric
+			var order []string
+			var stdout bytes.Buffer
+			cmd := &commands.CompareCommand{Out: &stdout, Exec: newCompareExec(t, tt.durations, &saved, &order)}
+			err := cmd.Run([]string{"-log", filepath.Join(t.TempDir(), "log.jsonl"), "-n", "4", "--", "old", "-j8", ":::", "new"})
+			if err != nil {
+				t.Fatalf("Run() erro: %v", err)
+			}
+			if want := []string{"old", "new", "new", "old", "old", "new", "new", "old"}; !reflect.DeepEqual(order, want) {
+				t.Errorf("ordem = %v, want %v", order, want)
+			}
+			if len(saved) != 8 {
+				t.Fatalf("%d métricas salvas, want 8", len(saved))
+			}
+			for _, m := range saved {
+				wantVariant := map[string]string{"old": "A", "new": "B"}[m.Args[0]]
+				if m.Variant != wantVariant || m.BenchmarkID == "" || m.BenchmarkID != saved[0].BenchmarkID {
+					t.Errorf("métrica %v: variante %q benchmark %q, want %q e o mesmo benchmark", m.Args, m.Variant, m.BenchmarkID, wantVariant)
+				}
+			}
+			if !reflect.DeepEqual(saved[0].Args, []string{"old", "-j8"}) {
+				t.Errorf("args de A = %v, want [old -j8]", saved[0].Args)
+			}
+			if !strings.Contains(stdout.String(), tt.wantVerdict) {
+				t.Errorf("saída sem %q:\n%s", tt.wantVerdict, stdout.String())
+			}
+		})
+	}
+}
+
+func TestCompareCommand_InvalidArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"Sem separador", []string{"--", "make"}},
+		{"Sem comando B", []string{"--", "make", ":::"}},
+		{"Separador repetido", []string{"--", "a", ":::", "b", ":::", "c"}},
+		{"Poucas execuções", []string{"-n", "1", "--", "a", ":::", "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var saved []metrics.BuildMetric
+			var order []string
+			cmd := &commands.CompareCommand{Out: &bytes.Buffer{}, Exec: newCompareExec(t, nil, &saved, &order)}
+			if err := cmd.Run(tt.args); err == nil {
+				t.Errorf("Run(%v) sem erro", tt.args)
+			}
+			if len(order) != 0 {
+				t.Errorf("comandos executados com argumentos inválidos: %v", order)
+			}
+		})
+	}
+}
+
+func TestCompareCommand_Failure(t *testing.T) {
+	var saved []metrics.BuildMetric
+	var order []string
+	exec := newCompareExec(t, map[string][]float64{"a": {1}, "b": {1}}, &saved, &order)
+	exec.Runner = func(ctx context.Context, args []string, opts runner.Options) runner.Result {
+		if args[0] == "b" {
+			return runner.Result{DurationSec: 1, ExitCode: 2}
+		}
+		return runner.Result{DurationSec: 1}
+	}
+	cmd := &commands.CompareCommand{Out: &bytes.Buffer{}, Exec: exec}
+	err := cmd.Run([]string{"-log", filepath.Join(t.TempDir(), "log.jsonl"), "-n", "3", "--", "a", ":::", "b"})
+	if err == nil || !strings.Contains(err.Error(), "B 1/3") {
+		t.Errorf("Run() erro = %v, want falha de B na primeira rodada", err)
+	}
+	if len(saved) != 2 {
+		t.Errorf("%d métricas salvas, want 2 (a falha também é registrada)", len(saved))
+	}
+}
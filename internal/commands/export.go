@@ -13,8 +13,9 @@ type ExportCommand struct {
 	Out           io.Writer
 	Err           io.Writer
 	MetricsOpener func(string) (io.ReadCloser, error)
-	MetricsSaver  func(io.Reader, io.Writer, bool) (metrics.ScanResult, error)
+	MetricsSaver  func([]metrics.LogFile, io.Writer, bool) (metrics.ScanResult, error)
 	FileCreator   func(string) (io.WriteCloser, error)
+	LoadHosts     func(logPath string) (map[string]metrics.HostProfile, error)
 }
 
 func (c *ExportCommand) Name() string { return "export" }
@@ -27,29 +28,31 @@ func (c *ExportCommand) Run(args []string) error {
 	fs := flag.NewFlagSet("export", flag.ContinueOnError)
 	fs.SetOutput(c.Out)
 
-	logOverride := fs.String("log", "", "Caminho do arquivo JSONL de log (ou use BUILD_METRICS_LOG)")
+	var logOverride stringList
+	fs.Var(&logOverride, "log", "Caminho do arquivo JSONL de log, pode ser repetido (ou use BUILD_METRICS_LOG)")
 	outPath := fs.String("out", "-", "Caminho do arquivo CSV de saída (ou '-' para stdout)")
 	strict := fs.Bool("strict", false, "Falha ao encontrar linhas inválidas no JSONL")
+	hostsFlag := fs.Bool("hosts", false, "Acrescenta as colunas do perfil da máquina (CPU, memória, kernel, sistema de arquivos)")
 
 	fs.Usage = func() {
 		fmt.Fprintf(fs.Output(), "Uso: export [-out path] [-log path] \n")
 		fs.PrintDefaults()
-		metrics.PrintResolvedLogPath(fs.Output(), "Arquivo de log: ", fs.Lookup("log").Value.String())
+		resolveLogPaths(fs.Output(), "Arquivo de log: ", logOverride)
 	}
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
 
-	logPath, err := metrics.GetLogFilePath(*logOverride)
+	logPaths, err := resolveLogPaths(nil, "", logOverride)
 	if err != nil {
 		return fmt.Errorf("erro ao resolver log: %v\n", err)
 	}
 
-	in, err := c.MetricsOpener(logPath)
+	logs, err := openLogs(c.MetricsOpener, logPaths)
 	if err != nil {
-		return fmt.Errorf("erro ao abrir log %s: %v\n", logPath, err)
+		return fmt.Errorf("erro ao abrir log %v\n", err)
 	}
-	defer in.Close()
+	defer closeLogs(logs)
 
 	var out io.Writer
 	if *outPath == "-" {
@@ -66,7 +69,17 @@ func (c *ExportCommand) Run(args []string) error {
 		out = f
 	}
 
-	res, err := c.MetricsSaver(in, out, *strict)
+	export := c.MetricsSaver
+	if *hostsFlag {
+		hosts, err := loadStores(logPaths, c.LoadHosts)
+		if err != nil {
+			return fmt.Errorf("erro ao ler perfis de máquina: %v\n", err)
+		}
+		export = func(logs []metrics.LogFile, out io.Writer, strict bool) (metrics.ScanResult, error) {
+			return metrics.ExportCSVWithHosts(logs, out, strict, hosts)
+		}
+	}
+	res, err := export(logs, out, *strict)
 	if err != nil {
 		return fmt.Errorf("erro ao exportar: %v\n", err)
 	}
@@ -94,10 +107,13 @@ func (c *ExportCommand) ensureDefaults() {
 	}
 	if c.MetricsSaver == nil {
 		// Wrapper function to match signature if necessary, or direct assignment
-		c.MetricsSaver = func(in io.Reader, out io.Writer, strict bool) (metrics.ScanResult, error) {
-			return metrics.ExportCSVFromJSONL(in, out, strict)
+		c.MetricsSaver = func(logs []metrics.LogFile, out io.Writer, strict bool) (metrics.ScanResult, error) {
+			return metrics.ExportCSVFromJSONL(logs, out, strict)
 		}
 	}
+	if c.LoadHosts == nil {
+		c.LoadHosts = metrics.LoadHostProfiles
+	}
 	if c.FileCreator == nil {
 		c.FileCreator = func(name string) (io.WriteCloser, error) {
 			return os.Create(name)
@@ -6,6 +6,7 @@ import (
 	"dev-metrics/internal/metrics"
 	"errors"
 	"io"
+	"strings"
 	"testing"
 )
 
@@ -97,7 +98,7 @@ func TestExportCommand_Run(t *testing.T) {
 					}
 					return &mockWriteCloser{Writer: &bytes.Buffer{}, closeFunc: nil}, nil
 				},
-				MetricsSaver: func(in io.Reader, out io.Writer, strict bool) (metrics.ScanResult, error) {
+				MetricsSaver: func(logs []metrics.LogFile, out io.Writer, strict bool) (metrics.ScanResult, error) {
 					if tt.mockExportErr != nil {
 						return metrics.ScanResult{}, tt.mockExportErr
 					}
@@ -121,6 +122,26 @@ func TestExportCommand_Run(t *testing.T) {
 	}
 }
 
+func TestExportCommand_StrictMultipleLogs(t *testing.T) {
+	logs := map[string]string{
+		"a.jsonl": `{"project":"A","timestamp":"2024-01-01T00:00:00Z","duration_sec":1}` + "\n",
+		"b.jsonl": `{"project":"B","timestamp":"2024-01-01T00:00:00Z","duration_sec":1}` + "\nINVALID\n",
+	}
+	var stdout bytes.Buffer
+	c := &commands.ExportCommand{
+		Out: &stdout,
+		Err: &bytes.Buffer{},
+		MetricsOpener: func(name string) (io.ReadCloser, error) {
+			return &mockReadCloser{Reader: strings.NewReader(logs[name])}, nil
+		},
+	}
+	err := c.Run([]string{"-out", "-", "-strict", "-log", "a.jsonl", "-log", "b.jsonl"})
+	// A linha é contada dentro do arquivo que a contém
+	if err == nil || !strings.Contains(err.Error(), "b.jsonl: jsonl parse error on line 2") {
+		t.Errorf("Run() erro = %v, want arquivo e linha da entrada inválida", err)
+	}
+}
+
 func TestExportCommand_Metadata(t *testing.T) {
 	c := &commands.ExportCommand{}
 	if c.Name() != "export" {
@@ -154,3 +175,29 @@ func TestExportCommand_EnsureDefaults(t *testing.T) {
 		t.Error("FileCreator is not set")
 	}
 }
+
+func TestExportCommand_Hosts(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	var loaded []string
+	c := &commands.ExportCommand{
+		Out: &stdout,
+		Err: &stderr,
+		MetricsOpener: func(name string) (io.ReadCloser, error) {
+			return &mockReadCloser{Reader: bytes.NewBufferString(`{"timestamp":"2024-01-01T00:00:00Z","project":"p","host_profile":"h1"}` + "\n")}, nil
+		},
+		LoadHosts: func(logPath string) (map[string]metrics.HostProfile, error) {
+			loaded = append(loaded, logPath)
+			return map[string]metrics.HostProfile{"h1": {ID: "h1", CPUModel: "Apple M2", LogicalCores: 8, FSType: "apfs"}}, nil
+		},
+	}
+	if err := c.Run([]string{"-hosts", "-log", "a.jsonl", "-log", "b.jsonl"}); err != nil {
+		t.Fatalf("Run() erro: %v", err)
+	}
+	if len(loaded) != 2 {
+		t.Errorf("perfis lidos de %v, want os dois logs", loaded)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[0], ",cpu_model,") || !strings.HasSuffix(lines[1], ",h1,Apple M2,0,8,0,0,,apfs") {
+		t.Errorf("CSV = %q", stdout.String())
+	}
+}
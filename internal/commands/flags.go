@@ -0,0 +1,119 @@
+package commands
+
+import (
+	"dev-metrics/internal/metrics"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// stringList é uma flag que pode ser repetida (ex.: --log a.jsonl --log b.jsonl).
+type stringList []string
+
+func (s *stringList) String() string {
+	if s == nil {
+		return ""
+	}
+	return strings.Join(*s, ",")
+}
+
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// parseInterspersed faz o parse de flags que podem aparecer depois dos
+// argumentos posicionais (ex.: bmt merge a.jsonl b.jsonl --out merged.jsonl).
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := fs.Args()
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		// Após "--" todo o restante é posicional.
+		if len(args) > len(rest) && args[len(args)-len(rest)-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+// openLogs abre todos os caminhos com open, na ordem dada, para metrics.ScanLogs.
+func openLogs(open func(string) (io.ReadCloser, error), paths []string) ([]metrics.LogFile, error) {
+	var logs []metrics.LogFile
+	for _, p := range paths {
+		f, err := open(p)
+		if err != nil {
+			closeLogs(logs)
+			return nil, fmt.Errorf("%s: %w", p, err)
+		}
+		logs = append(logs, metrics.LogFile{Name: p, R: f})
+	}
+	return logs, nil
+}
+
+// closeLogs fecha os logs abertos por openLogs.
+func closeLogs(logs []metrics.LogFile) {
+	for _, l := range logs {
+		if c, ok := l.R.(io.Closer); ok {
+			c.Close()
+		}
+	}
+}
+
+// resolveLogPaths resolve os logs informados via --log (repetível). Sem nenhum,
+// usa o log padrão (GetLogFilePath). Se w não for nil, imprime cada caminho com label.
+func resolveLogPaths(w io.Writer, label string, paths []string) ([]string, error) {
+	if len(paths) == 0 {
+		if w == nil {
+			w = io.Discard
+		}
+		p, err := metrics.PrintResolvedLogPath(w, label, "")
+		if err != nil {
+			return nil, err
+		}
+		return []string{p}, nil
+	}
+	if w != nil {
+		for _, p := range paths {
+			fmt.Fprintf(w, "%s%s\n", label, p)
+		}
+	}
+	return paths, nil
+}
+
+// loadStores lê um registro auxiliar (toolchains.jsonl, hosts.jsonl) ao lado
+// de cada log e junta as entradas; para chaves repetidas, vale a do primeiro log.
+func loadStores[T any](logPaths []string, load func(logPath string) (map[string]T, error)) (map[string]T, error) {
+	merged := make(map[string]T)
+	for _, p := range logPaths {
+		entries, err := load(p)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range entries {
+			if _, ok := merged[k]; !ok {
+				merged[k] = v
+			}
+		}
+	}
+	return merged, nil
+}
+
+// flagWasSet informa se a flag name foi passada na linha de comando, para
+// distinguir um valor explícito do padrão vindo da configuração.
+func flagWasSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
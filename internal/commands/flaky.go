@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"dev-metrics/internal/metrics"
+	"dev-metrics/internal/ui"
+)
+
+type FlakyCommand struct {
+	FileOpener func(name string) (io.ReadCloser, error)
+	Out        io.Writer
+}
+
+func (c *FlakyCommand) Name() string { return "flaky" }
+func (c *FlakyCommand) Description() string {
+	return "Lista comandos que falharam e passaram no mesmo commit, pelo tempo perdido"
+}
+
+func (c *FlakyCommand) Run(args []string) error {
+	c.ensureDefaults()
+	fs := flag.NewFlagSet("flaky", flag.ContinueOnError)
+	var logFlag stringList
+	fs.Var(&logFlag, "log", "Caminho do arquivo de log (pode ser repetido)")
+	sinceFlag := fs.String("since", "", "Data de início (YYYY-MM-DD)")
+	untilFlag := fs.String("until", "", "Data de fim (YYYY-MM-DD)")
+	unitFlag := fs.String("unit", "auto", "Unidade do tempo perdido (auto|s|min|h)")
+	topFlag := fs.Int("top", 20, "Quantos comandos listar (0 lista todos)")
+	fs.SetOutput(c.Out)
+	fs.Usage = func() {
+		fs.PrintDefaults()
+		fmt.Fprintf(fs.Output(), "%s", `Um comando é instável quando, no mesmo projeto e commit, o mesmo argv
+falhou e passou (ex.: tentativas de 'bmt run --retries'). Exemplo:
+  bmt flaky --since 2024-01-01 --top 10
+`)
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	logPaths, err := resolveLogPaths(c.Out, "Usando arquivo de log: ", logFlag)
+	if err != nil {
+		return fmt.Errorf("Erro ao obter path do arquivo de log: %v\n", err)
+	}
+	logs, err := openLogs(c.FileOpener, logPaths)
+	if err != nil {
+		return fmt.Errorf("Erro ao abrir log: %v", err)
+	}
+	defer closeLogs(logs)
+
+	opts := metrics.ReportOptions{}
+	if *sinceFlag != "" {
+		if opts.Since, err = time.ParseInLocation("2006-01-02", *sinceFlag, time.Local); err != nil {
+			return fmt.Errorf("formato de data inválido para --since (use YYYY-MM-DD): %v", err)
+		}
+	}
+	if *untilFlag != "" {
+		if opts.Until, err = time.ParseInLocation("2006-01-02", *untilFlag, time.Local); err != nil {
+			return fmt.Errorf("formato de data inválido para --until (use YYYY-MM-DD): %v", err)
+		}
+	}
+	unit, err := metrics.ParseDurationUnit(*unitFlag)
+	if err != nil {
+		return err
+	}
+
+	report, err := metrics.GenerateFlakyReport(logs, opts)
+	if err != nil {
+		return fmt.Errorf("Erro ao processar dados: %v", err)
+	}
+	total := len(report.Entries)
+	if *topFlag > 0 && total > *topFlag {
+		// Os totais passam a valer só para os comandos listados
+		report.Entries = report.Entries[:*topFlag]
+		report.WastedSec = 0
+		for _, e := range report.Entries {
+			report.WastedSec += e.WastedSec
+		}
+	}
+	ui.RenderFlakyTable(c.Out, report, unit)
+	if len(report.Entries) < total {
+		fmt.Fprintf(c.Out, "\nMostrando %d de %d comandos instáveis (use --top 0 para ver todos).\n", len(report.Entries), total)
+	}
+	return nil
+}
+
+func (c *FlakyCommand) ensureDefaults() {
+	if c.FileOpener == nil {
+		c.FileOpener = func(name string) (io.ReadCloser, error) {
+			return os.Open(name)
+		}
+	}
+	if c.Out == nil {
+		c.Out = os.Stdout
+	}
+}
+
+func (c *FlakyCommand) Aliases() []string {
+	return []string{}
+}
+
+func init() {
+	Register(&FlakyCommand{})
+}
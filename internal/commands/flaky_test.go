@@ -0,0 +1,57 @@
+package commands_test
+
+import (
+	"bytes"
+	"dev-metrics/internal/commands"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestFlakyCommand_Run(t *testing.T) {
+	log := `{"project":"A","commit":"abc","timestamp":"2024-01-01T00:00:00Z","duration_sec":30,"status":"failure","command":"[ctest]","args":["ctest"]}
+{"project":"A","commit":"abc","timestamp":"2024-01-01T00:01:00Z","duration_sec":25,"status":"success","command":"[ctest]","args":["ctest"]}
+{"project":"A","commit":"abc","timestamp":"2024-01-01T00:02:00Z","duration_sec":5,"status":"failure","command":"[lint]","args":["lint"]}
+{"project":"A","commit":"abc","timestamp":"2024-01-01T00:03:00Z","duration_sec":5,"status":"success","command":"[lint]","args":["lint"]}
+`
+	tests := []struct {
+		name        string
+		args        []string
+		openErr     error
+		wantErr     bool
+		wantOut     []string
+		wantMissing string
+	}{
+		{name: "Lista os instáveis", args: []string{"-log", "a.jsonl"}, wantOut: []string{"[ctest]", "[lint]"}},
+		{name: "Top 1", args: []string{"-log", "a.jsonl", "-top", "1"}, wantOut: []string{"[ctest]", "Mostrando 1 de 2"}, wantMissing: "[lint]"},
+		{name: "Log inexistente", args: []string{"-log", "a.jsonl"}, openErr: os.ErrNotExist, wantErr: true},
+		{name: "Data inválida", args: []string{"-log", "a.jsonl", "-since", "ontem"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			cmd := &commands.FlakyCommand{
+				Out: &out,
+				FileOpener: func(name string) (io.ReadCloser, error) {
+					if tt.openErr != nil {
+						return nil, tt.openErr
+					}
+					return &mockReadCloser{Reader: strings.NewReader(log)}, nil
+				},
+			}
+			err := cmd.Run(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Run() erro = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, s := range tt.wantOut {
+				if !strings.Contains(out.String(), s) {
+					t.Errorf("saída sem %q:\n%s", s, out.String())
+				}
+			}
+			if tt.wantMissing != "" && strings.Contains(out.String(), tt.wantMissing) {
+				t.Errorf("saída não deveria ter %q:\n%s", tt.wantMissing, out.String())
+			}
+		})
+	}
+}
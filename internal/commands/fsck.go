@@ -0,0 +1,159 @@
+package commands
+
+import (
+	"bytes"
+	metrics "dev-metrics/internal/metrics"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+)
+
+type FsckCommand struct {
+	Out        io.Writer
+	FileOpener func(string) (io.ReadCloser, error)
+	Now        func() time.Time
+	RewriteLog func(path, backupPath string, fn func(r io.Reader, w io.Writer) error) error
+}
+
+func (c *FsckCommand) Name() string { return "fsck" }
+func (c *FsckCommand) Description() string {
+	return "Verifica (e opcionalmente repara) o arquivo de log"
+}
+
+func (c *FsckCommand) Run(args []string) error {
+	c.ensureDefaults()
+	fs := flag.NewFlagSet("fsck", flag.ContinueOnError)
+	fs.SetOutput(c.Out)
+
+	logFlag := fs.String("log", "", "Caminho do arquivo de log")
+	repair := fs.Bool("repair", false, "Reescreve o log sem as linhas rejeitadas (faz backup antes)")
+	quarantineFlag := fs.String("quarantine", "", "Arquivo que recebe as linhas rejeitadas no reparo (padrão: <log>.quarantine)")
+	maxIssues := fs.Int("max", 50, "Número máximo de problemas listados (0 = todos)")
+
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Uso: bmt fsck [-log path] [-repair]\n")
+		fs.PrintDefaults()
+		metrics.PrintResolvedLogPath(fs.Output(), "Arquivo de log: ", fs.Lookup("log").Value.String())
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	logPath, err := metrics.PrintResolvedLogPath(c.Out, "Verificando: ", *logFlag)
+	if err != nil {
+		return fmt.Errorf("erro ao resolver log: %v", err)
+	}
+
+	in, err := c.FileOpener(logPath)
+	if err != nil {
+		return fmt.Errorf("erro ao abrir log %s: %v", logPath, err)
+	}
+	report, err := metrics.CheckJSONL(in, metrics.FsckOptions{})
+	in.Close()
+	if err != nil {
+		return fmt.Errorf("erro ao verificar log: %v", err)
+	}
+
+	printFsckReport(c.Out, report, *maxIssues)
+	if len(report.Issues) == 0 {
+		return nil
+	}
+	if !*repair {
+		return fmt.Errorf("%d problemas encontrados (use -repair para corrigir)", len(report.Issues))
+	}
+
+	quarantinePath := *quarantineFlag
+	if quarantinePath == "" {
+		quarantinePath = logPath + ".quarantine"
+	}
+	backupPath := fmt.Sprintf("%s.bak-%s", logPath, c.Now().Format("20060102-150405"))
+
+	// Verifica novamente sob o lock: registros podem ter sido gravados desde a primeira leitura.
+	// As linhas rejeitadas só vão para a quarentena depois que o log reparado
+	// substituir o original; se a reescrita falhar, elas continuam no log.
+	var repaired *metrics.FsckReport
+	var rejected bytes.Buffer
+	err = c.RewriteLog(logPath, backupPath, func(r io.Reader, w io.Writer) error {
+		var cerr error
+		rejected.Reset()
+		repaired, cerr = metrics.CheckJSONL(r, metrics.FsckOptions{Clean: w, Quarantine: &rejected})
+		return cerr
+	})
+	if err != nil {
+		return fmt.Errorf("erro ao reparar log: %v", err)
+	}
+	if rejected.Len() > 0 {
+		if err := appendQuarantine(quarantinePath, &rejected); err != nil {
+			return fmt.Errorf("erro ao gravar quarentena %s (as linhas continuam no backup %s): %v", quarantinePath, backupPath, err)
+		}
+	}
+
+	fmt.Fprintf(c.Out, "\nLog reparado: %d registros mantidos, %d linhas rejeitadas.\n", repaired.Valid, repaired.Rejected)
+	fmt.Fprintf(c.Out, "Backup: %s\n", backupPath)
+	if repaired.Rejected > 0 {
+		fmt.Fprintf(c.Out, "Quarentena: %s\n", quarantinePath)
+	}
+	return nil
+}
+
+// appendQuarantine acrescenta as linhas rejeitadas ao arquivo de quarentena.
+func appendQuarantine(path string, rejected *bytes.Buffer) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := rejected.WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func printFsckReport(w io.Writer, report *metrics.FsckReport, max int) {
+	for i, is := range report.Issues {
+		if max > 0 && i >= max {
+			fmt.Fprintf(w, "... e mais %d problemas\n", len(report.Issues)-max)
+			break
+		}
+		fmt.Fprintf(w, "linha %d: [%s] %s\n", is.Line, is.Kind, is.Detail)
+	}
+
+	fmt.Fprintf(w, "\n%d linhas, %d registros válidos, %d linhas rejeitáveis.\n", report.Lines, report.Valid, report.Rejected)
+	counts := report.CountByKind()
+	kinds := make([]string, 0, len(counts))
+	for k := range counts {
+		kinds = append(kinds, string(k))
+	}
+	sort.Strings(kinds)
+	for _, k := range kinds {
+		fmt.Fprintf(w, "  %-18s %d\n", k, counts[metrics.FsckIssueKind(k)])
+	}
+}
+
+func (c *FsckCommand) ensureDefaults() {
+	if c.Out == nil {
+		c.Out = os.Stdout
+	}
+	if c.FileOpener == nil {
+		c.FileOpener = func(name string) (io.ReadCloser, error) {
+			return os.Open(name)
+		}
+	}
+	if c.Now == nil {
+		c.Now = time.Now
+	}
+	if c.RewriteLog == nil {
+		c.RewriteLog = metrics.RewriteLog
+	}
+}
+
+func (c *FsckCommand) Aliases() []string {
+	return []string{}
+}
+
+func init() {
+	Register(&FsckCommand{})
+}
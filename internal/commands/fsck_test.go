@@ -0,0 +1,133 @@
+package commands_test
+
+import (
+	"bytes"
+	"dev-metrics/internal/commands"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFsckCommand_Run(t *testing.T) {
+	content := `{"project":"A","timestamp":"2024-01-02T10:00:00Z","duration_sec":10}
+INVALID
+{"project":"A","timestamp":"2024-01-02T10:00:00Z","duration_sec":10}
+`
+	tests := []struct {
+		name         string
+		content      string
+		repair       bool
+		wantErr      bool
+		wantOut      []string
+		wantLog      string
+		wantBackup   bool
+		wantQuaranti string
+		noQuarantine bool // o reparo não rejeita linhas e não cria a quarentena
+	}{
+		{
+			name:    "Log válido",
+			content: `{"project":"A","timestamp":"2024-01-02T10:00:00Z","duration_sec":10}` + "\n",
+			wantOut: []string{"1 registros válidos"},
+		},
+		{
+			name:    "Problemas sem reparo",
+			content: content,
+			wantErr: true,
+			wantOut: []string{"linha 2: [invalid_json]", "linha 3: [duplicate] duplicado da linha 1"},
+			wantLog: content,
+		},
+		{
+			name:         "Problemas com reparo",
+			content:      content,
+			repair:       true,
+			wantOut:      []string{"Log reparado: 1 registros mantidos, 2 linhas rejeitadas."},
+			wantLog:      `{"project":"A","timestamp":"2024-01-02T10:00:00Z","duration_sec":10}` + "\n",
+			wantBackup:   true,
+			wantQuaranti: "INVALID\n" + `{"project":"A","timestamp":"2024-01-02T10:00:00Z","duration_sec":10}` + "\n",
+		},
+		{
+			name:         "Reparo só de ordem",
+			content:      `{"project":"A","timestamp":"2024-01-03T10:00:00Z","duration_sec":10}` + "\n" + `{"project":"A","timestamp":"2024-01-02T10:00:00Z","duration_sec":10}` + "\n",
+			repair:       true,
+			wantOut:      []string{"0 linhas rejeitadas"},
+			wantBackup:   true,
+			noQuarantine: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			logPath := filepath.Join(dir, "log.jsonl")
+			os.WriteFile(logPath, []byte(tt.content), 0644)
+
+			var out bytes.Buffer
+			c := &commands.FsckCommand{
+				Out: &out,
+				Now: func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) },
+			}
+			args := []string{"-log", logPath}
+			if tt.repair {
+				args = append(args, "-repair")
+			}
+			err := c.Run(args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, want := range tt.wantOut {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("saída não contém %q:\n%s", want, out.String())
+				}
+			}
+			if tt.wantLog != "" {
+				got, _ := os.ReadFile(logPath)
+				if string(got) != tt.wantLog {
+					t.Errorf("log = %q, want %q", got, tt.wantLog)
+				}
+			}
+			if tt.wantBackup {
+				bak, err := os.ReadFile(logPath + ".bak-20260102-030405")
+				if err != nil || string(bak) != tt.content {
+					t.Errorf("backup = %q (err %v), want conteúdo original", bak, err)
+				}
+			}
+			if tt.wantQuaranti != "" {
+				q, _ := os.ReadFile(logPath + ".quarantine")
+				if string(q) != tt.wantQuaranti {
+					t.Errorf("quarentena = %q, want %q", q, tt.wantQuaranti)
+				}
+			}
+			if _, err := os.Stat(logPath + ".quarantine"); tt.noQuarantine && !os.IsNotExist(err) {
+				t.Errorf("quarentena criada sem linhas rejeitadas (err %v)", err)
+			}
+		})
+	}
+}
+
+func TestFsckCommand_RepairFailureKeepsQuarantineEmpty(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "log.jsonl")
+	content := `{"project":"A","timestamp":"2024-01-02T10:00:00Z","duration_sec":10}` + "\nINVALID\n"
+	os.WriteFile(logPath, []byte(content), 0644)
+
+	c := &commands.FsckCommand{
+		Out: &bytes.Buffer{},
+		RewriteLog: func(path, backupPath string, fn func(r io.Reader, w io.Writer) error) error {
+			if err := fn(strings.NewReader(content), io.Discard); err != nil {
+				return err
+			}
+			return errors.New("rename falhou")
+		},
+	}
+	err := c.Run([]string{"-log", logPath, "-repair"})
+	if err == nil || !strings.Contains(err.Error(), "rename falhou") {
+		t.Fatalf("Run() erro = %v, want falha da reescrita", err)
+	}
+	// A linha rejeitada continua só no log
+	if q, err := os.ReadFile(logPath + ".quarantine"); !os.IsNotExist(err) {
+		t.Errorf("quarentena = %q (err %v), want inexistente", q, err)
+	}
+}
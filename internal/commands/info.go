@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"dev-metrics/internal/config"
 	metrics "dev-metrics/internal/metrics"
 	"fmt"
 	"io"
@@ -30,6 +31,10 @@ func (c *Info) Run(args []string) error {
 		fmt.Fprintf(c.Out, "Build Time: %s\n", buildTime.Local().Format(time.RFC3339))
 	}
 
+	if cfgPath, err := config.Path(); err == nil {
+		fmt.Fprintf(c.Out, "Arquivo de configuração: %s\n", cfgPath)
+	}
+
 	path, err := metrics.GetLogFilePath("")
 	if err != nil {
 		fmt.Fprintf(c.Out, "Erro ao resolver caminho do log: %v\n", err)
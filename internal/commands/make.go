@@ -0,0 +1,348 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"dev-metrics/internal/metrics"
+	"dev-metrics/internal/runner"
+)
+
+// MakeShellName é o nome do link para o bmt usado como SHELL do make. Chamado
+// por esse nome, o bmt mede a receita em vez de interpretar subcomandos.
+const MakeShellName = "bmt-make-shell"
+
+// Variáveis de ambiente trocadas entre bmt make e o shim.
+const (
+	EnvMakeRealShell = "BMT_MAKE_REAL_SHELL" // Shell que de fato executa as receitas
+	EnvMakeTimings   = "BMT_MAKE_TIMINGS"    // Arquivo JSONL onde o shim anota cada receita
+	EnvMakeTarget    = "BMT_MAKE_TARGET"     // $@ da receita, exportado pelo make
+)
+
+// makeRecipe é a linha que o shim grava para cada invocação do shell.
+type makeRecipe struct {
+	Target      string    `json:"target"`
+	Start       time.Time `json:"start"`
+	DurationSec float64   `json:"duration_sec"`
+	ExitCode    int       `json:"exit_code"`
+}
+
+// RunMakeShell é o shim: executa o shell real com args, anota a duração da
+// receita e retorna o código de saída dela.
+func RunMakeShell(args []string) int {
+	shell := os.Getenv(EnvMakeRealShell)
+	if shell == "" {
+		shell = "/bin/sh"
+	}
+	// Ctrl+C chega a todo o grupo; o shim espera a receita terminar para anotá-la
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT)
+	defer signal.Stop(sigChan)
+
+	start := time.Now()
+	duration, exitCode := runner.Run(context.Background(), append([]string{shell}, args...))
+	if path := os.Getenv(EnvMakeTimings); path != "" {
+		rec := makeRecipe{Target: os.Getenv(EnvMakeTarget), Start: start, DurationSec: duration, ExitCode: exitCode}
+		if err := appendMakeRecipe(path, rec); err != nil {
+			fmt.Fprintf(os.Stderr, "[Metrics Error] %v\n", err)
+		}
+	}
+	return exitCode
+}
+
+// appendMakeRecipe grava rec numa única escrita com O_APPEND, para que
+// receitas paralelas (make -j) não intercalem linhas.
+func appendMakeRecipe(path string, rec makeRecipe) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(line, '\n'))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// MakeCommand roda o make com o SHELL apontado para o shim e registra cada
+// alvo como filho da execução do make.
+type MakeCommand struct {
+	Out io.Writer
+	// Exec mede a execução do make; os alvos são gravados com o MetricsSaver dele
+	Exec *ExecCommand
+	// Executable retorna o caminho do bmt, para onde o shim aponta
+	Executable func() (string, error)
+	Getenv     func(string) string
+}
+
+func (c *MakeCommand) Name() string { return "make" }
+func (c *MakeCommand) Description() string {
+	return "Executa o make medindo cada alvo (ex: bmt make -- -j8 all)"
+}
+
+// makeRejectedFlags são flags de bmt run sem sentido numa execução com alvos.
+var makeRejectedFlags = []string{"repeat", "warmup", "prepare", "retries", "retry-on"}
+
+func (c *MakeCommand) Run(args []string) error {
+	c.ensureDefaults()
+	if runtime.GOOS == "windows" {
+		return errors.New("bmt make requer um sistema Unix")
+	}
+	runArgs, makeArgs := args, []string(nil)
+	if i := slices.Index(args, "--"); i >= 0 {
+		runArgs, makeArgs = args[:i], args[i+1:]
+	}
+	shell, top := "/bin/sh", 10
+	var forwarded []string
+	for i := 0; i < len(runArgs); i++ {
+		name, value, hasValue := splitFlag(runArgs[i])
+		switch {
+		case name == "h" || name == "help":
+			c.usage()
+			return nil
+		case slices.Contains(makeRejectedFlags, name):
+			return fmt.Errorf("-%s não pode ser usado com bmt make", name)
+		case name != "shell" && name != "top":
+			forwarded = append(forwarded, runArgs[i])
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(runArgs) {
+				return fmt.Errorf("flag -%s requer um valor", name)
+			}
+			i++
+			value = runArgs[i]
+		}
+		if name == "shell" {
+			shell = value
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return fmt.Errorf("valor inválido para -top: %q", value)
+		}
+		top = n
+	}
+
+	exe, err := c.Executable()
+	if err != nil {
+		return fmt.Errorf("erro ao localizar o executável do bmt: %v", err)
+	}
+	dir, err := os.MkdirTemp("", "bmt-make-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	shim := filepath.Join(dir, MakeShellName)
+	if err := os.Symlink(exe, shim); err != nil {
+		return fmt.Errorf("erro ao criar o shim do SHELL: %v", err)
+	}
+	timings := filepath.Join(dir, "recipes.jsonl")
+
+	// Cópia, para não deixar o Env e o MetricsSaver trocados no ExecCommand
+	exec := *c.Exec
+	exec.ensureDefaults()
+	save := exec.MetricsSaver
+	var run *metrics.BuildMetric
+	var logPath string
+	exec.MetricsSaver = func(m metrics.BuildMetric, path string) error {
+		run, logPath = &m, path
+		return save(m, path)
+	}
+	exec.Env = append(slices.Clip(exec.Env),
+		"MAKEFLAGS="+makeFlags(c.Getenv("MAKEFLAGS"), shim),
+		EnvMakeRealShell+"="+shell,
+		EnvMakeTimings+"="+timings,
+	)
+	if err := exec.Run(append(forwarded, append([]string{"--", "make"}, makeArgs...)...)); err != nil {
+		return err
+	}
+	if run == nil {
+		return nil
+	}
+
+	recipes, err := readMakeRecipes(timings)
+	if err != nil {
+		return fmt.Errorf("erro ao ler as receitas medidas: %v", err)
+	}
+	targets := aggregateTargets(recipes)
+	for _, t := range targets {
+		m := manualMetric(metrics.KindTarget, t.name, t.end, t.durationSec)
+		m.ID, m.ParentID, m.Depth = exec.NewID(), run.ID, run.Depth+1
+		m.Project, m.Branch, m.Commit = run.Project, run.Branch, run.Commit
+		m.User, m.Hostname = run.User, run.Hostname
+		if t.exitCode != 0 {
+			m.Status, m.ReturnCode = "failure", t.exitCode
+		}
+		if err := save(m, logPath); err != nil {
+			return fmt.Errorf("erro ao gravar o alvo %s: %v", t.name, err)
+		}
+	}
+	c.printTargets(targets, len(recipes), run.DurationSec, top)
+	return nil
+}
+
+func (c *MakeCommand) usage() {
+	fmt.Fprintf(c.Out, "%s", `Uso: bmt make [flags de bmt run] [-shell sh] [-top N] -- <args do make>
+  -shell string
+    	Shell que executa as receitas; o SHELL do Makefile é substituído pelo shim (padrão "/bin/sh")
+  -top int
+    	Quantos alvos mostrar no resumo, 0 desativa (padrão 10)
+As demais flags são as de bmt run, exceto -repeat, -warmup, -prepare e -retries.
+Cada alvo é gravado como filho da execução do make (ver bmt show --tree e
+bmt report --by target). Exemplo:
+  bmt make -sample 500ms -- -j8 all
+`)
+}
+
+// splitFlag separa "-name=value" ou "--name" em nome e valor.
+func splitFlag(arg string) (name, value string, hasValue bool) {
+	if !strings.HasPrefix(arg, "-") {
+		return "", "", false
+	}
+	name = strings.TrimLeft(arg, "-")
+	name, value, hasValue = strings.Cut(name, "=")
+	return name, value, hasValue
+}
+
+// makeFlags acrescenta a MAKEFLAGS as definições de SHELL e de EnvMakeTarget.
+// Definições em MAKEFLAGS valem como se viessem da linha de comando, também
+// nos makes recursivos, sem mudar o comando registrado; "$$@" chega a cada
+// receita como o $@ do alvo.
+func makeFlags(existing, shim string) string {
+	defs := "SHELL=" + strings.ReplaceAll(shim, " ", `\ `) + " " + EnvMakeTarget + "=$$@"
+	switch {
+	case existing == "":
+		return "-- " + defs
+	case strings.HasPrefix(existing, "-- ") || strings.Contains(existing, " -- "):
+		return existing + " " + defs
+	}
+	return existing + " -- " + defs
+}
+
+func readMakeRecipes(path string) ([]makeRecipe, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil // Nenhuma receita executada (tudo atualizado)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var recipes []makeRecipe
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var rec makeRecipe
+		if json.Unmarshal(scanner.Bytes(), &rec) == nil {
+			recipes = append(recipes, rec)
+		}
+	}
+	return recipes, scanner.Err()
+}
+
+// makeTarget soma as receitas de um alvo (uma por linha com .ONESHELL
+// desligado, ou várias execuções do mesmo alvo em makes recursivos).
+type makeTarget struct {
+	name        string
+	durationSec float64
+	recipes     int
+	end         time.Time
+	exitCode    int // O da primeira receita que falhou
+}
+
+// aggregateTargets agrupa as receitas por alvo, dos mais lentos aos mais
+// rápidos. Receitas sem alvo ($(shell ...) durante a leitura do Makefile)
+// ficam de fora.
+func aggregateTargets(recipes []makeRecipe) []makeTarget {
+	index := make(map[string]int)
+	var targets []makeTarget
+	for _, rec := range recipes {
+		if rec.Target == "" {
+			continue
+		}
+		i, ok := index[rec.Target]
+		if !ok {
+			i = len(targets)
+			index[rec.Target] = i
+			targets = append(targets, makeTarget{name: rec.Target})
+		}
+		t := &targets[i]
+		t.durationSec += rec.DurationSec
+		t.recipes++
+		if end := rec.Start.Add(time.Duration(rec.DurationSec * float64(time.Second))); end.After(t.end) {
+			t.end = end
+		}
+		if t.exitCode == 0 {
+			t.exitCode = rec.ExitCode
+		}
+	}
+	sort.SliceStable(targets, func(i, j int) bool {
+		return targets[i].durationSec > targets[j].durationSec
+	})
+	return targets
+}
+
+func (c *MakeCommand) printTargets(targets []makeTarget, recipes int, runSec float64, top int) {
+	if top == 0 {
+		return
+	}
+	if len(targets) == 0 {
+		fmt.Fprintln(c.Out, "- Nenhuma receita executada (alvos já atualizados).")
+		return
+	}
+	fmt.Fprintf(c.Out, "- Alvos mais lentos (%d alvos, %d receitas):\n", len(targets), recipes)
+	for _, t := range targets[:min(top, len(targets))] {
+		line := fmt.Sprintf("  %-32s %10s", t.name, metrics.FormatDuration(t.durationSec, metrics.DurationAuto, true))
+		if runSec > 0 {
+			line += fmt.Sprintf(" %5.1f%%", t.durationSec/runSec*100)
+		}
+		if t.recipes > 1 {
+			line += fmt.Sprintf("  (%d receitas)", t.recipes)
+		}
+		if t.exitCode != 0 {
+			line += fmt.Sprintf("  falhou (código %d)", t.exitCode)
+		}
+		fmt.Fprintln(c.Out, line)
+	}
+}
+
+func (c *MakeCommand) Aliases() []string {
+	return []string{}
+}
+
+func (c *MakeCommand) ensureDefaults() {
+	if c.Out == nil {
+		c.Out = os.Stdout
+	}
+	if c.Exec == nil {
+		c.Exec = &ExecCommand{}
+	}
+	if c.Executable == nil {
+		c.Executable = os.Executable
+	}
+	if c.Getenv == nil {
+		c.Getenv = os.Getenv
+	}
+}
+
+func init() {
+	Register(&MakeCommand{})
+}
@@ -0,0 +1,129 @@
+package commands_test
+
+import (
+	"bytes"
+	"context"
+	"dev-metrics/internal/commands"
+	"dev-metrics/internal/metrics"
+	"dev-metrics/internal/runner"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMakeCommand_Run(t *testing.T) {
+	var saved []metrics.BuildMetric
+	var gotArgs []string
+	var gotEnv map[string]string
+	ids := 0
+	var out bytes.Buffer
+	exec := newTestExecCommand(t)
+	// Simula o make: cada receita passa pelo shim, que anota no arquivo de tempos
+	exec.Runner = func(ctx context.Context, args []string, opts runner.Options) runner.Result {
+		gotArgs = args
+		gotEnv = make(map[string]string)
+		for _, kv := range opts.Env {
+			k, v, _ := strings.Cut(kv, "=")
+			gotEnv[k] = v
+		}
+		lines := []string{
+			`{"target":"","start":"2026-10-19T10:00:00Z","duration_sec":0.1,"exit_code":0}`,
+			`{"target":"main.o","start":"2026-10-19T10:00:01Z","duration_sec":3,"exit_code":0}`,
+			`{"target":"util.o","start":"2026-10-19T10:00:01Z","duration_sec":1,"exit_code":0}`,
+			`{"target":"app","start":"2026-10-19T10:00:04Z","duration_sec":0.5,"exit_code":0}`,
+			`{"target":"app","start":"2026-10-19T10:00:04.5Z","duration_sec":0.5,"exit_code":2}`,
+		}
+		os.WriteFile(gotEnv["BMT_MAKE_TIMINGS"], []byte(strings.Join(lines, "\n")+"\n"), 0o644)
+		return runner.Result{DurationSec: 5, ExitCode: 2}
+	}
+	exec.NewID = func() string { ids++; return fmt.Sprintf("01ID%d", ids) }
+	exec.MetricsSaver = func(m metrics.BuildMetric, filePath string) error { saved = append(saved, m); return nil }
+	cmd := &commands.MakeCommand{
+		Out:        &out,
+		Exec:       exec,
+		Executable: func() (string, error) { return "/usr/local/bin/bmt", nil },
+		Getenv:     func(k string) string { return map[string]string{"MAKEFLAGS": "k"}[k] },
+	}
+	logPath := filepath.Join(t.TempDir(), "log.jsonl")
+	if err := cmd.Run([]string{"-log", logPath, "-shell", "/bin/bash", "-top", "2", "--", "-j8", "all"}); err != nil {
+		t.Fatalf("Run() erro: %v", err)
+	}
+
+	if want := []string{"make", "-j8", "all"}; !reflect.DeepEqual(gotArgs, want) {
+		t.Errorf("comando = %q, want %q", gotArgs, want)
+	}
+	shim := filepath.Join(filepath.Dir(gotEnv["BMT_MAKE_TIMINGS"]), commands.MakeShellName)
+	if want := "k -- SHELL=" + shim + " BMT_MAKE_TARGET=$$@"; gotEnv["MAKEFLAGS"] != want {
+		t.Errorf("MAKEFLAGS = %q, want %q", gotEnv["MAKEFLAGS"], want)
+	}
+	if gotEnv["BMT_MAKE_REAL_SHELL"] != "/bin/bash" {
+		t.Errorf("BMT_MAKE_REAL_SHELL = %q", gotEnv["BMT_MAKE_REAL_SHELL"])
+	}
+	if exec.Env != nil {
+		t.Errorf("Env do ExecCommand alterado: %q", exec.Env)
+	}
+
+	// A execução do make e depois os alvos, dos mais lentos aos mais rápidos
+	if len(saved) != 4 {
+		t.Fatalf("gravados %d registros, want 4: %+v", len(saved), saved)
+	}
+	type target struct {
+		Command, Status, ParentID, Timestamp string
+		Duration                             float64
+		Code, Depth                          int
+	}
+	var got []target
+	for _, m := range saved[1:] {
+		if m.Kind != metrics.KindTarget || m.Project != "p" || m.User != "u" {
+			t.Errorf("alvo = %+v", m)
+		}
+		got = append(got, target{m.Command, m.Status, m.ParentID, m.Timestamp, m.DurationSec, m.ReturnCode, m.Depth})
+	}
+	want := []target{
+		{"main.o", "success", "01ID1", "2026-10-19T10:00:04Z", 3, 0, 1},
+		{"util.o", "success", "01ID1", "2026-10-19T10:00:02Z", 1, 0, 1},
+		{"app", "failure", "01ID1", "2026-10-19T10:00:05Z", 1, 2, 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("alvos = %+v\nwant %+v", got, want)
+	}
+
+	s := out.String()
+	for _, w := range []string{"3 alvos, 5 receitas", "main.o", "60.0%", "util.o"} {
+		if !strings.Contains(s, w) {
+			t.Errorf("resumo sem %q:\n%s", w, s)
+		}
+	}
+	if strings.Contains(s, "app") {
+		t.Errorf("resumo com -top 2 mostrou o terceiro alvo:\n%s", s)
+	}
+}
+
+func TestMakeCommand_RejectsBenchmarkFlags(t *testing.T) {
+	cmd := &commands.MakeCommand{Out: &bytes.Buffer{}}
+	err := cmd.Run([]string{"-repeat", "3", "--", "all"})
+	if err == nil || !strings.Contains(err.Error(), "-repeat") {
+		t.Errorf("Run() erro = %v, want erro sobre -repeat", err)
+	}
+}
+
+func TestRunMakeShell(t *testing.T) {
+	timings := filepath.Join(t.TempDir(), "recipes.jsonl")
+	t.Setenv("BMT_MAKE_TIMINGS", timings)
+	t.Setenv("BMT_MAKE_TARGET", "main.o")
+	t.Setenv("BMT_MAKE_REAL_SHELL", "/bin/sh")
+
+	if code := commands.RunMakeShell([]string{"-c", "exit 3"}); code != 3 {
+		t.Errorf("RunMakeShell() = %d, want 3", code)
+	}
+	data, err := os.ReadFile(timings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := string(data); !strings.Contains(s, `"target":"main.o"`) || !strings.Contains(s, `"exit_code":3`) {
+		t.Errorf("receita anotada = %s", s)
+	}
+}
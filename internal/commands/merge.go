@@ -0,0 +1,130 @@
+package commands
+
+import (
+	metrics "dev-metrics/internal/metrics"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+type MergeCommand struct {
+	Out         io.Writer
+	Err         io.Writer
+	FileOpener  func(string) (io.ReadCloser, error)
+	FileCreator func(string) (io.WriteCloser, error)
+	// Sidecars leva hosts.jsonl, toolchains.jsonl e artifacts/ das entradas
+	// para o diretório do log combinado.
+	Sidecars func(inputs []string, outLog string) (metrics.SidecarResult, error)
+}
+
+func (c *MergeCommand) Name() string { return "merge" }
+func (c *MergeCommand) Description() string {
+	return "Combina logs de várias máquinas, ordenando por tempo e removendo duplicados"
+}
+
+func (c *MergeCommand) Run(args []string) error {
+	c.ensureDefaults()
+	fs := flag.NewFlagSet("merge", flag.ContinueOnError)
+	fs.SetOutput(c.Out)
+
+	outPath := fs.String("out", "", "Caminho do log combinado (ou '-' para stdout)")
+	strict := fs.Bool("strict", false, "Falha ao encontrar linhas inválidas no JSONL")
+
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Uso: bmt merge a.jsonl b.jsonl ... -out merged.jsonl\n")
+		fs.PrintDefaults()
+	}
+	inputs, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(inputs) == 0 || *outPath == "" {
+		fs.Usage()
+		return errors.New("informe os logs de entrada e o arquivo de saída com -out")
+	}
+	for _, in := range inputs {
+		if *outPath != "-" && filepath.Clean(in) == filepath.Clean(*outPath) {
+			return fmt.Errorf("o arquivo de saída não pode ser uma das entradas: %s", in)
+		}
+	}
+
+	var mergeInputs []metrics.MergeInput
+	for _, path := range inputs {
+		f, err := c.FileOpener(path)
+		if err != nil {
+			return fmt.Errorf("erro ao abrir log %s: %v", path, err)
+		}
+		defer f.Close()
+		mergeInputs = append(mergeInputs, metrics.MergeInput{Name: path, R: f})
+	}
+
+	var out io.Writer
+	if *outPath == "-" {
+		out = c.Out
+	} else {
+		if err := metrics.EnsureLogDir(filepath.Dir(*outPath)); err != nil {
+			return fmt.Errorf("erro ao criar diretório de saída: %v", err)
+		}
+		f, err := c.FileCreator(*outPath)
+		if err != nil {
+			return fmt.Errorf("erro ao criar %s: %v", *outPath, err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	res, err := metrics.MergeJSONL(mergeInputs, out, *strict)
+	if err != nil {
+		return fmt.Errorf("erro ao combinar logs: %v", err)
+	}
+
+	for _, cf := range res.Conflicts {
+		fmt.Fprintf(c.Err, "conflito: id %s em %s difere de %s (mantido o primeiro)\n", cf.ID, cf.Dropped, cf.Kept)
+	}
+	fmt.Fprintf(c.Err, "combinado: %d registros de %d logs (duplicados: %d, conflitos: %d, inválidos: %d)\n",
+		res.Written, len(inputs), res.Duplicates, len(res.Conflicts), res.Invalid)
+
+	if *outPath == "-" {
+		return nil
+	}
+	side, err := c.Sidecars(inputs, *outPath)
+	if err != nil {
+		return fmt.Errorf("erro ao combinar arquivos auxiliares: %v", err)
+	}
+	fmt.Fprintf(c.Err, "auxiliares: %d perfis de máquina, %d toolchains, %d artefatos\n",
+		side.Hosts, side.Toolchains, side.Artifacts)
+	return nil
+}
+
+func (c *MergeCommand) ensureDefaults() {
+	if c.Out == nil {
+		c.Out = os.Stdout
+	}
+	if c.Err == nil {
+		c.Err = os.Stderr
+	}
+	if c.FileOpener == nil {
+		c.FileOpener = func(name string) (io.ReadCloser, error) {
+			return os.Open(name)
+		}
+	}
+	if c.FileCreator == nil {
+		c.FileCreator = func(name string) (io.WriteCloser, error) {
+			return os.Create(name)
+		}
+	}
+	if c.Sidecars == nil {
+		c.Sidecars = metrics.MergeSidecars
+	}
+}
+
+func (c *MergeCommand) Aliases() []string {
+	return []string{}
+}
+
+func init() {
+	Register(&MergeCommand{})
+}
@@ -0,0 +1,112 @@
+package commands_test
+
+import (
+	"bytes"
+	"dev-metrics/internal/commands"
+	"dev-metrics/internal/metrics"
+	"errors"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMergeCommand_Run(t *testing.T) {
+	logs := map[string]string{
+		"a.jsonl": `{"id":"1","timestamp":"2024-01-02T00:00:00Z"}` + "\n",
+		"b.jsonl": `{"id":"1","timestamp":"2024-01-02T00:00:00Z"}` + "\n" + `{"id":"0","timestamp":"2024-01-01T00:00:00Z"}` + "\n",
+	}
+	tests := []struct {
+		name       string
+		args       []string
+		wantErr    bool
+		wantOut    string
+		wantStderr string
+	}{
+		{
+			name:       "Flags depois das entradas",
+			args:       []string{"a.jsonl", "b.jsonl", "--out", "-"},
+			wantOut:    `{"id":"0","timestamp":"2024-01-01T00:00:00Z"}` + "\n" + `{"id":"1","timestamp":"2024-01-02T00:00:00Z"}` + "\n",
+			wantStderr: "combinado: 2 registros de 2 logs (duplicados: 1, conflitos: 0, inválidos: 0)",
+		},
+		{
+			name:    "Sem saída",
+			args:    []string{"a.jsonl"},
+			wantErr: true,
+		},
+		{
+			name:    "Saída igual a entrada",
+			args:    []string{"-out", "a.jsonl", "a.jsonl"},
+			wantErr: true,
+		},
+		{
+			name:    "Entrada inexistente",
+			args:    []string{"-out", "-", "missing.jsonl"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			c := &commands.MergeCommand{
+				Out: &stdout,
+				Err: &stderr,
+				FileOpener: func(name string) (io.ReadCloser, error) {
+					content, ok := logs[name]
+					if !ok {
+						return nil, errors.New("not found")
+					}
+					return &mockReadCloser{Reader: strings.NewReader(content)}, nil
+				},
+			}
+			err := c.Run(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantOut != "" && stdout.String() != tt.wantOut {
+				t.Errorf("stdout = %q, want %q", stdout.String(), tt.wantOut)
+			}
+			if tt.wantStderr != "" && !strings.Contains(stderr.String(), tt.wantStderr) {
+				t.Errorf("stderr = %q, want substring %q", stderr.String(), tt.wantStderr)
+			}
+		})
+	}
+}
+
+func TestMergeCommand_Sidecars(t *testing.T) {
+	outPath := filepath.Join(t.TempDir(), "merged.jsonl")
+	var stderr, merged bytes.Buffer
+	var gotInputs []string
+	var gotOut string
+	c := &commands.MergeCommand{
+		Out: &bytes.Buffer{},
+		Err: &stderr,
+		FileOpener: func(name string) (io.ReadCloser, error) {
+			return &mockReadCloser{Reader: strings.NewReader(`{"id":"1","timestamp":"2024-01-02T00:00:00Z"}` + "\n")}, nil
+		},
+		FileCreator: func(name string) (io.WriteCloser, error) {
+			return &mockWriteCloser{Writer: &merged}, nil
+		},
+		Sidecars: func(inputs []string, out string) (metrics.SidecarResult, error) {
+			gotInputs, gotOut = inputs, out
+			return metrics.SidecarResult{Hosts: 2, Toolchains: 1, Artifacts: 3}, nil
+		},
+	}
+	if err := c.Run([]string{"a/build.jsonl", "b/build.jsonl", "-out", outPath}); err != nil {
+		t.Fatalf("Run() erro: %v", err)
+	}
+	if want := []string{"a/build.jsonl", "b/build.jsonl"}; !reflect.DeepEqual(gotInputs, want) || gotOut != outPath {
+		t.Errorf("Sidecars(%v, %q), want (%v, %q)", gotInputs, gotOut, want, outPath)
+	}
+	if want := "auxiliares: 2 perfis de máquina, 1 toolchains, 3 artefatos"; !strings.Contains(stderr.String(), want) {
+		t.Errorf("stderr = %q, want substring %q", stderr.String(), want)
+	}
+
+	c.Sidecars = func([]string, string) (metrics.SidecarResult, error) {
+		return metrics.SidecarResult{}, errors.New("disco cheio")
+	}
+	if err := c.Run([]string{"a/build.jsonl", "-out", outPath}); err == nil || !strings.Contains(err.Error(), "disco cheio") {
+		t.Errorf("Run() erro = %v, esperava falha dos arquivos auxiliares", err)
+	}
+}
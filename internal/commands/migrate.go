@@ -0,0 +1,111 @@
+package commands
+
+import (
+	metrics "dev-metrics/internal/metrics"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+type MigrateCommand struct {
+	Out         io.Writer
+	Err         io.Writer
+	FileOpener  func(string) (io.ReadCloser, error)
+	FileCreator func(string) (io.WriteCloser, error)
+}
+
+func (c *MigrateCommand) Name() string { return "migrate" }
+func (c *MigrateCommand) Description() string {
+	return "Reescreve um log JSONL na versão mais recente do schema"
+}
+
+func (c *MigrateCommand) Run(args []string) error {
+	c.ensureDefaults()
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	fs.SetOutput(c.Out)
+
+	inPath := fs.String("in", "", "Caminho do log JSONL de entrada (padrão: log em uso)")
+	outPath := fs.String("out", "", "Caminho do log JSONL de saída (ou '-' para stdout)")
+	strict := fs.Bool("strict", false, "Falha ao encontrar linhas inválidas no JSONL")
+
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Uso: bmt migrate [-in path] -out path\n")
+		fs.PrintDefaults()
+		metrics.PrintResolvedLogPath(fs.Output(), "Arquivo de log: ", fs.Lookup("in").Value.String())
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *outPath == "" {
+		fs.Usage()
+		return errors.New("informe o arquivo de saída com -out")
+	}
+
+	logPath, err := metrics.GetLogFilePath(*inPath)
+	if err != nil {
+		return fmt.Errorf("erro ao resolver log: %v", err)
+	}
+	if *outPath != "-" && filepath.Clean(*outPath) == filepath.Clean(logPath) {
+		return errors.New("o arquivo de saída deve ser diferente do de entrada")
+	}
+
+	in, err := c.FileOpener(logPath)
+	if err != nil {
+		return fmt.Errorf("erro ao abrir log %s: %v", logPath, err)
+	}
+	defer in.Close()
+
+	var out io.Writer
+	if *outPath == "-" {
+		out = c.Out
+	} else {
+		if err := metrics.EnsureLogDir(filepath.Dir(*outPath)); err != nil {
+			return fmt.Errorf("erro ao criar diretório de saída: %v", err)
+		}
+		f, err := c.FileCreator(*outPath)
+		if err != nil {
+			return fmt.Errorf("erro ao criar %s: %v", *outPath, err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	res, err := metrics.MigrateJSONL(in, out, *strict)
+	if err != nil {
+		return fmt.Errorf("erro ao migrar: %v", err)
+	}
+
+	fmt.Fprintf(c.Err, "migrado: %d registros (%d atualizados para o schema %d, %d linhas inválidas copiadas)\n",
+		res.Processed, res.Upgraded, metrics.CurrentSchemaVersion, res.Invalid)
+	return nil
+}
+
+func (c *MigrateCommand) ensureDefaults() {
+	if c.Out == nil {
+		c.Out = os.Stdout
+	}
+	if c.Err == nil {
+		c.Err = os.Stderr
+	}
+	if c.FileOpener == nil {
+		c.FileOpener = func(name string) (io.ReadCloser, error) {
+			return os.Open(name)
+		}
+	}
+	if c.FileCreator == nil {
+		c.FileCreator = func(name string) (io.WriteCloser, error) {
+			return os.Create(name)
+		}
+	}
+}
+
+func (c *MigrateCommand) Aliases() []string {
+	return []string{}
+}
+
+func init() {
+	Register(&MigrateCommand{})
+}
@@ -0,0 +1,108 @@
+package commands_test
+
+import (
+	"bytes"
+	"dev-metrics/internal/commands"
+	"dev-metrics/internal/metrics"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// oldLog tem um registro v1, sem schema_version nem args, e um já atualizado.
+const oldLog = `{"project":"A","command":"[make -j8]","duration_sec":3}
+{"schema_version":2,"project":"B","command":"[go test]","args":["go","test"]}
+`
+
+func TestMigrateCommand_Run(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		openErr    error
+		wantErr    bool
+		wantOut    string
+		wantStderr string
+		wantFile   bool
+	}{
+		{
+			name:       "Migra para stdout",
+			args:       []string{"-in", "old.jsonl", "-out", "-"},
+			wantOut:    `"schema_version":2`,
+			wantStderr: "migrado: 2 registros (1 atualizados",
+		},
+		{
+			name:       "Migra para arquivo",
+			args:       []string{"-in", "old.jsonl", "-out", "new.jsonl"},
+			wantStderr: "migrado: 2 registros",
+			wantFile:   true,
+		},
+		{
+			name:    "Sem saída",
+			args:    []string{"-in", "old.jsonl"},
+			wantErr: true,
+		},
+		{
+			name:    "Saída igual à entrada",
+			args:    []string{"-in", "old.jsonl", "-out", "./old.jsonl"},
+			wantErr: true,
+		},
+		{
+			name:    "Erro ao abrir",
+			args:    []string{"-in", "old.jsonl", "-out", "-"},
+			openErr: errors.New("not found"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr, file bytes.Buffer
+			c := &commands.MigrateCommand{
+				Out: &stdout,
+				Err: &stderr,
+				FileOpener: func(name string) (io.ReadCloser, error) {
+					if tt.openErr != nil {
+						return nil, tt.openErr
+					}
+					return &mockReadCloser{Reader: strings.NewReader(oldLog)}, nil
+				},
+				FileCreator: func(name string) (io.WriteCloser, error) {
+					return &mockWriteCloser{Writer: &file}, nil
+				},
+			}
+
+			err := c.Run(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantOut != "" && !strings.Contains(stdout.String(), tt.wantOut) {
+				t.Errorf("stdout = %q, want substring %q", stdout.String(), tt.wantOut)
+			}
+			if tt.wantStderr != "" && !strings.Contains(stderr.String(), tt.wantStderr) {
+				t.Errorf("stderr = %q, want substring %q", stderr.String(), tt.wantStderr)
+			}
+			if !tt.wantFile {
+				return
+			}
+			lines := strings.Split(strings.TrimSuffix(file.String(), "\n"), "\n")
+			wantArgs := [][]string{{"make", "-j8"}, {"go", "test"}}
+			if len(lines) != len(wantArgs) {
+				t.Fatalf("arquivo com %d linhas, want %d:\n%s", len(lines), len(wantArgs), file.String())
+			}
+			for i, line := range lines {
+				var m metrics.BuildMetric
+				if err := json.Unmarshal([]byte(line), &m); err != nil {
+					t.Fatalf("linha %d inválida: %v", i+1, err)
+				}
+				if m.SchemaVersion != metrics.CurrentSchemaVersion || !reflect.DeepEqual(m.Args, wantArgs[i]) {
+					t.Errorf("linha %d: schema_version = %d, args = %q; want %d, %q", i+1, m.SchemaVersion, m.Args, metrics.CurrentSchemaVersion, wantArgs[i])
+				}
+			}
+			if !strings.Contains(lines[0], `"duration_sec":3`) || !strings.Contains(lines[0], `"command":"[make -j8]"`) {
+				t.Errorf("linha 1 perdeu campos originais: %s", lines[0])
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package commands
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"time"
+
+	"dev-metrics/internal/git"
+	"dev-metrics/internal/metrics"
+	"dev-metrics/internal/ninja"
+)
+
+// NinjaLogCommand importa o último build de um .ninja_log, para builds do
+// ninja que não rodaram sob bmt run.
+type NinjaLogCommand struct {
+	Out          io.Writer
+	GitInfo      func() (branch, commit, project string)
+	MetricsSaver func(m metrics.BuildMetric, filePath string) error
+	UserInfo     func() (*user.User, error)
+	Hostname     func() (string, error)
+	NewID        func() string
+	ReadLatest   func(dir string) (ninja.Build, error)
+	FileOpener   func(string) (io.ReadCloser, error)
+}
+
+func (c *NinjaLogCommand) Name() string { return "ninja-log" }
+func (c *NinjaLogCommand) Description() string {
+	return "Importa o último build do .ninja_log com o tempo de cada alvo"
+}
+
+func (c *NinjaLogCommand) Run(args []string) error {
+	c.ensureDefaults()
+	fs := flag.NewFlagSet("ninja-log", flag.ContinueOnError)
+	fs.SetOutput(c.Out)
+	logFlag := fs.String("log", "", "Caminho customizado para o arquivo de log")
+	projectFlag := fs.String("project", "", "Projeto do build (padrão: o do repositório atual)")
+	topFlag := fs.Int("top", ninja.DefaultTopTargets, "Quantos alvos guardar, dos mais lentos (0 guarda todos)")
+	dryRunFlag := fs.Bool("dry-run", false, "Só mostra os alvos mais lentos, sem gravar no log")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Uso: bmt ninja-log [--project p] [--top N] [--dry-run] [-log path] [diretório do build]\n")
+		fs.PrintDefaults()
+		fmt.Fprintf(fs.Output(), "%s", `Builds medidos com bmt run já trazem os alvos (ver bmt run -ninja); importe
+só os que rodaram fora dele; um build já importado não é gravado de novo. Exemplo:
+  ninja -C build && bmt ninja-log build
+`)
+	}
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 1 {
+		fs.Usage()
+		return errors.New("informe no máximo um diretório de build")
+	}
+	dir := "."
+	if len(positional) == 1 {
+		dir = positional[0]
+	}
+
+	b, err := c.ReadLatest(dir)
+	if err != nil {
+		return err
+	}
+	if len(b.Entries) == 0 {
+		return fmt.Errorf("nenhuma entrada no %s de %s", ninja.LogName, dir)
+	}
+	m := manualMetric(metrics.KindNinja, "ninja -C "+dir, b.ModTime, b.DurationSec())
+	m.Targets = b.Targets(*topFlag)
+
+	fmt.Fprintf(c.Out, "Último build: %s, %d alvos, terminado em %s\n",
+		metrics.FormatDuration(m.DurationSec, metrics.DurationAuto, true), len(b.Entries), b.ModTime.Format("2006-01-02 15:04"))
+	for _, t := range m.Targets[:min(10, len(m.Targets))] {
+		fmt.Fprintf(c.Out, "  %-40s %s\n", t.Name, metrics.FormatDuration(t.DurationSec, metrics.DurationAuto, true))
+	}
+	if *dryRunFlag {
+		return nil
+	}
+
+	logPath, err := metrics.GetLogFilePath(*logFlag)
+	if err != nil {
+		return fmt.Errorf("erro ao resolver caminho do log: %v", err)
+	}
+	imported, err := c.imported(logPath, m)
+	if err != nil {
+		return fmt.Errorf("erro ao ler o log: %v", err)
+	}
+	if imported {
+		fmt.Fprintf(c.Out, "Build já importado em %s, nada gravado\n", logPath)
+		return nil
+	}
+	branch, commit, project := c.GitInfo()
+	if *projectFlag != "" {
+		project = *projectFlag
+	}
+	hostname, _ := c.Hostname()
+	m.ID, m.Project, m.Branch, m.Commit = c.NewID(), project, branch, commit
+	m.User, m.Hostname = currentUsername(c.UserInfo), hostname
+	if err := c.MetricsSaver(m, logPath); err != nil {
+		return fmt.Errorf("erro ao gravar build: %v", err)
+	}
+	fmt.Fprintf(c.Out, "Importado: %s [%s]\n", project, m.ID)
+	return nil
+}
+
+// imported informa se o log já tem um build do ninja com o mesmo comando e o
+// mesmo fim de m (o mtime do .ninja_log).
+func (c *NinjaLogCommand) imported(logPath string, m metrics.BuildMetric) (bool, error) {
+	f, err := c.FileOpener(logPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	end, _ := time.Parse(time.RFC3339, m.Timestamp)
+	found := false
+	_, err = metrics.ScanJSONLParallel(f, metrics.ScanOptions{Unordered: true}, func(old metrics.BuildMetric) error {
+		if old.Kind != metrics.KindNinja || old.Command != m.Command {
+			return nil
+		}
+		if t, err := time.Parse(time.RFC3339, old.Timestamp); err == nil && t.Equal(end) {
+			found = true
+		}
+		return nil
+	})
+	return found, err
+}
+
+func (c *NinjaLogCommand) Aliases() []string {
+	return []string{}
+}
+
+func (c *NinjaLogCommand) ensureDefaults() {
+	if c.Out == nil {
+		c.Out = os.Stdout
+	}
+	if c.GitInfo == nil {
+		c.GitInfo = git.GetInfo
+	}
+	if c.MetricsSaver == nil {
+		c.MetricsSaver = metrics.Save
+	}
+	if c.UserInfo == nil {
+		c.UserInfo = user.Current
+	}
+	if c.Hostname == nil {
+		c.Hostname = os.Hostname
+	}
+	if c.NewID == nil {
+		c.NewID = metrics.NewID
+	}
+	if c.ReadLatest == nil {
+		c.ReadLatest = ninja.ReadLatest
+	}
+	if c.FileOpener == nil {
+		c.FileOpener = func(name string) (io.ReadCloser, error) {
+			return os.Open(name)
+		}
+	}
+}
+
+func init() {
+	Register(&NinjaLogCommand{})
+}
@@ -0,0 +1,105 @@
+package commands_test
+
+import (
+	"bytes"
+	"dev-metrics/internal/commands"
+	"dev-metrics/internal/metrics"
+	"dev-metrics/internal/ninja"
+	"io"
+	"os"
+	"os/user"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNinjaLogCommand_Run(t *testing.T) {
+	end := time.Date(2026, 10, 19, 16, 0, 0, 0, time.UTC)
+	build := ninja.Build{
+		Entries: []ninja.Entry{
+			{Output: "a.o", StartMs: 0, EndMs: 2000},
+			{Output: "b.o", StartMs: 0, EndMs: 5000},
+			{Output: "app", StartMs: 5000, EndMs: 6000},
+		},
+		ModTime: end,
+	}
+	tests := []struct {
+		name      string
+		args      []string
+		wantDir   string
+		existing  string // Conteúdo do log antes da importação
+		wantSaved bool
+		wantErr   string
+	}{
+		{name: "Importa", args: []string{"--top", "2", "build"}, wantDir: "build", wantSaved: true},
+		{
+			name:      "Outro build do mesmo diretório",
+			args:      []string{"--top", "2", "build"},
+			existing:  `{"kind":"ninja","command":"ninja -C build","timestamp":"2026-10-19T15:00:00Z"}` + "\n",
+			wantDir:   "build",
+			wantSaved: true,
+		},
+		{
+			name:     "Já importado",
+			args:     []string{"build"},
+			existing: `{"kind":"ninja","command":"ninja -C build","timestamp":"2026-10-19T13:00:00-03:00"}` + "\n",
+			wantDir:  "build",
+		},
+		{name: "Dry run", args: []string{"--dry-run"}, wantDir: "."},
+		{name: "Dois diretórios", args: []string{"a", "b"}, wantErr: "no máximo um"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var saved *metrics.BuildMetric
+			var gotDir string
+			var out bytes.Buffer
+			cmd := &commands.NinjaLogCommand{
+				Out:          &out,
+				GitInfo:      func() (string, string, string) { return "main", "1234567", "engine" },
+				MetricsSaver: func(m metrics.BuildMetric, filePath string) error { saved = &m; return nil },
+				UserInfo:     func() (*user.User, error) { return &user.User{Username: "u"}, nil },
+				Hostname:     func() (string, error) { return "h", nil },
+				NewID:        func() string { return "01NINJA" },
+				ReadLatest:   func(dir string) (ninja.Build, error) { gotDir = dir; return build, nil },
+				FileOpener: func(name string) (io.ReadCloser, error) {
+					if tt.existing == "" {
+						return nil, os.ErrNotExist
+					}
+					return &mockReadCloser{Reader: strings.NewReader(tt.existing)}, nil
+				},
+			}
+			err := cmd.Run(append([]string{"-log", filepath.Join(t.TempDir(), "log.jsonl")}, tt.args...))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Run() erro = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Run() erro: %v", err)
+			}
+			if gotDir != tt.wantDir {
+				t.Errorf("diretório = %q, want %q", gotDir, tt.wantDir)
+			}
+			if !strings.Contains(out.String(), "b.o") {
+				t.Errorf("saída sem o alvo mais lento:\n%s", out.String())
+			}
+			if (saved != nil) != tt.wantSaved {
+				t.Fatalf("gravado = %v, want %v", saved != nil, tt.wantSaved)
+			}
+			if saved == nil {
+				return
+			}
+			if saved.Kind != metrics.KindNinja || saved.ID != "01NINJA" || saved.Project != "engine" || saved.DurationSec != 6 ||
+				saved.Timestamp != end.Format(time.RFC3339) {
+				t.Errorf("registro = %+v", saved)
+			}
+			want := []metrics.TargetDuration{{Name: "b.o", DurationSec: 5}, {Name: "a.o", DurationSec: 2}}
+			if !reflect.DeepEqual(saved.Targets, want) {
+				t.Errorf("Targets = %+v, want %+v", saved.Targets, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package commands
+
+import (
+	metrics "dev-metrics/internal/metrics"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+type PruneCommand struct {
+	Out io.Writer
+}
+
+func (c *PruneCommand) Name() string { return "prune" }
+func (c *PruneCommand) Description() string {
+	return "Remove do log as execuções que casam com os filtros informados"
+}
+
+func (c *PruneCommand) Run(args []string) error {
+	c.ensureDefaults()
+	fs := flag.NewFlagSet("prune", flag.ContinueOnError)
+	fs.SetOutput(c.Out)
+
+	logFlag := fs.String("log", "", "Caminho do arquivo de log")
+	beforeFlag := fs.String("before", "", "Remove execuções anteriores a esta data (YYYY-MM-DD ou RFC3339)")
+	projectFlag := fs.String("project", "", "Remove execuções deste projeto")
+	commandFlag := fs.String("command-regex", "", "Remove execuções cujo comando casa com a expressão regular")
+	statusFlag := fs.String("status", "", "Remove execuções com este status (success|failure|interrupted)")
+	idFlag := fs.String("id", "", "Remove as execuções com estes IDs (separados por vírgula)")
+	dryRun := fs.Bool("dry-run", false, "Apenas mostra o que seria removido")
+
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Uso: bmt prune [-dry-run] [filtros...]\n")
+		fs.PrintDefaults()
+		fmt.Fprintf(fs.Output(), "%s", `Exemplo:
+  bmt prune --command-regex '^\[sleep ' --dry-run
+`)
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	filter := metrics.RecordFilter{
+		Project: *projectFlag,
+		Status:  *statusFlag,
+	}
+	if *beforeFlag != "" {
+		t, err := parseDateFlag(*beforeFlag)
+		if err != nil {
+			return fmt.Errorf("formato de data inválido para --before (use YYYY-MM-DD): %v", err)
+		}
+		filter.Before = t
+	}
+	if *commandFlag != "" {
+		re, err := regexp.Compile(*commandFlag)
+		if err != nil {
+			return fmt.Errorf("expressão inválida para --command-regex: %v", err)
+		}
+		filter.CommandRegex = re
+	}
+	if *idFlag != "" {
+		filter.IDs = splitList(*idFlag)
+	}
+	if filter.IsEmpty() {
+		fs.Usage()
+		return errors.New("informe ao menos um filtro; prune não remove o log inteiro")
+	}
+
+	logPath, err := metrics.GetLogFilePath(*logFlag)
+	if err != nil {
+		return fmt.Errorf("erro ao resolver log: %v", err)
+	}
+
+	res, err := editLog(logPath, *dryRun, func(m metrics.BuildMetric, rec metrics.RawRecord) (bool, bool) {
+		return !filter.Match(m), false
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, ch := range res.Changes {
+		fmt.Fprintf(c.Out, "- linha %d: %s\n", ch.Line, describeMetric(ch.Before))
+	}
+	printEditSummary(c.Out, res, *dryRun)
+	return nil
+}
+
+// editLog aplica edit ao log. Em dry-run o arquivo só é lido; caso contrário
+// é reescrito atomicamente sob o lock do log.
+func editLog(logPath string, dryRun bool, edit metrics.RecordEdit) (metrics.EditResult, error) {
+	var res metrics.EditResult
+	if dryRun {
+		f, err := os.Open(logPath)
+		if err != nil {
+			return res, fmt.Errorf("erro ao abrir log %s: %v", logPath, err)
+		}
+		defer f.Close()
+		res, err = metrics.EditJSONL(f, nil, edit)
+		if err != nil {
+			return res, fmt.Errorf("erro ao ler log: %v", err)
+		}
+		return res, nil
+	}
+
+	err := metrics.RewriteLog(logPath, "", func(r io.Reader, w io.Writer) error {
+		var eerr error
+		res, eerr = metrics.EditJSONL(r, w, edit)
+		return eerr
+	})
+	if err != nil {
+		return res, fmt.Errorf("erro ao reescrever log %s: %v", logPath, err)
+	}
+	return res, nil
+}
+
+func printEditSummary(w io.Writer, res metrics.EditResult, dryRun bool) {
+	fmt.Fprintf(w, "\nRemovidos: %d | Modificados: %d | Inalterados: %d", res.Removed, res.Modified, res.Kept)
+	if res.Invalid > 0 {
+		fmt.Fprintf(w, " | Linhas inválidas preservadas: %d", res.Invalid)
+	}
+	fmt.Fprintln(w)
+	if dryRun {
+		fmt.Fprintln(w, "[dry-run] Nenhuma alteração foi gravada.")
+	}
+}
+
+// describeMetric resume um registro em uma linha para listagens.
+func describeMetric(m metrics.BuildMetric) string {
+	id := m.ID
+	if id == "" {
+		id = "-"
+	}
+	return fmt.Sprintf("%s | %s | %s | %s | %s | %s", id, m.Timestamp, m.Project, m.Status,
+		metrics.FormatDuration(m.DurationSec, metrics.DurationAuto, true), m.Command)
+}
+
+// parseDateFlag aceita YYYY-MM-DD (horário local) ou RFC3339.
+func parseDateFlag(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02", value, time.Local)
+}
+
+// splitList separa uma lista de valores separados por vírgula, ignorando vazios.
+func splitList(value string) []string {
+	var out []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func (c *PruneCommand) ensureDefaults() {
+	if c.Out == nil {
+		c.Out = os.Stdout
+	}
+}
+
+func (c *PruneCommand) Aliases() []string {
+	return []string{"rm"}
+}
+
+func init() {
+	Register(&PruneCommand{})
+}
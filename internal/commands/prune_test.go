@@ -0,0 +1,101 @@
+package commands_test
+
+import (
+	"bytes"
+	"dev-metrics/internal/commands"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const editableLog = `{"id":"a1","project":"backend","branch":"main","timestamp":"2024-01-02T10:00:00Z","command":"[make]","status":"success","duration_sec":10}
+{"id":"a2","project":"backend","branch":"main","timestamp":"2024-01-03T10:00:00Z","command":"[sleep 600]","status":"interrupted","duration_sec":600}
+{"id":"a3","project":"wrong","branch":"feat","timestamp":"2024-01-04T10:00:00Z","command":"[make]","status":"failure","duration_sec":3}
+`
+
+func writeTempLog(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "log.jsonl")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestPruneCommand_Run(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		wantErr     bool
+		wantOut     []string
+		wantRemains []string
+		wantGone    []string
+	}{
+		{
+			name:        "Remove por regex de comando",
+			args:        []string{"-command-regex", `^\[sleep `},
+			wantOut:     []string{"- linha 2: a2", "Removidos: 1"},
+			wantRemains: []string{`"a1"`, `"a3"`},
+			wantGone:    []string{`"a2"`},
+		},
+		{
+			name:        "Dry-run não altera",
+			args:        []string{"-project", "wrong", "-dry-run"},
+			wantOut:     []string{"- linha 3: a3", "[dry-run]"},
+			wantRemains: []string{`"a1"`, `"a2"`, `"a3"`},
+		},
+		{
+			name:        "Filtros combinados",
+			args:        []string{"-before", "2024-01-04", "-status", "success", "-id", "a1,a2"},
+			wantOut:     []string{"Removidos: 1"},
+			wantRemains: []string{`"a2"`, `"a3"`},
+			wantGone:    []string{`"a1"`},
+		},
+		{
+			name:    "Sem filtro",
+			args:    []string{},
+			wantErr: true,
+		},
+		{
+			name:    "Regex inválida",
+			args:    []string{"-command-regex", "("},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeTempLog(t, editableLog)
+			var out bytes.Buffer
+			c := &commands.PruneCommand{Out: &out}
+
+			err := c.Run(append([]string{"-log", path}, tt.args...))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, want := range tt.wantOut {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("saída não contém %q:\n%s", want, out.String())
+				}
+			}
+			data, _ := os.ReadFile(path)
+			for _, want := range tt.wantRemains {
+				if !strings.Contains(string(data), want) {
+					t.Errorf("log deveria manter %s", want)
+				}
+			}
+			for _, gone := range tt.wantGone {
+				if strings.Contains(string(data), gone) {
+					t.Errorf("log não deveria conter %s", gone)
+				}
+			}
+		})
+	}
+}
+
+func TestPruneCommand_Metadata(t *testing.T) {
+	c := &commands.PruneCommand{}
+	if c.Name() != "prune" || len(c.Aliases()) != 1 || c.Aliases()[0] != "rm" {
+		t.Errorf("Name()/Aliases() = %q/%v", c.Name(), c.Aliases())
+	}
+}
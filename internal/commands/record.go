@@ -0,0 +1,163 @@
+package commands
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"runtime"
+	"time"
+
+	"dev-metrics/internal/git"
+	"dev-metrics/internal/metrics"
+)
+
+// RecordCommand registra uma duração que não vem de um comando medido (ex.:
+// espera pelo CI ou por uma revisão de código).
+type RecordCommand struct {
+	Out          io.Writer
+	GitInfo      func() (branch, commit, project string)
+	MetricsSaver func(m metrics.BuildMetric, filePath string) error
+	UserInfo     func() (*user.User, error)
+	Hostname     func() (string, error)
+	NewID        func() string
+	Now          func() time.Time
+}
+
+func (c *RecordCommand) Name() string { return "record" }
+func (c *RecordCommand) Description() string {
+	return "Registra manualmente uma duração (ex.: espera pelo CI)"
+}
+
+// atLayouts são os formatos aceitos por bmt record --at, além de RFC 3339.
+var atLayouts = []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02T15:04"}
+
+func (c *RecordCommand) Run(args []string) error {
+	c.ensureDefaults()
+	fs := flag.NewFlagSet("record", flag.ContinueOnError)
+	fs.SetOutput(c.Out)
+	logFlag := fs.String("log", "", "Caminho customizado para o arquivo de log")
+	projectFlag := fs.String("project", "", "Projeto do registro (padrão: o do repositório atual)")
+	nameFlag := fs.String("name", "", "O que foi medido (ex.: \"ci wait\")")
+	durationFlag := fs.Duration("duration", 0, "Duração, ex.: 14m ou 1h30m")
+	atFlag := fs.String("at", "", "Quando terminou: RFC 3339, \"YYYY-MM-DD HH:MM\" ou \"HH:MM\" de hoje (padrão: agora)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Uso: bmt record --name nome --duration duração [--project p] [--at quando] [-log path]\n")
+		fs.PrintDefaults()
+		fmt.Fprintf(fs.Output(), "%s", `Exemplo:
+  bmt record --project backend --name "ci wait" --duration 14m --at 15:30
+`)
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *nameFlag == "" {
+		fs.Usage()
+		return errors.New("informe o nome do registro em --name")
+	}
+	if *durationFlag <= 0 {
+		fs.Usage()
+		return errors.New("informe uma duração positiva em --duration")
+	}
+	end := c.Now()
+	if *atFlag != "" {
+		var err error
+		if end, err = parseAt(*atFlag, end); err != nil {
+			return err
+		}
+	}
+
+	logPath, err := metrics.GetLogFilePath(*logFlag)
+	if err != nil {
+		return fmt.Errorf("erro ao resolver caminho do log: %v", err)
+	}
+	branch, commit, project := c.GitInfo()
+	if *projectFlag != "" {
+		project = *projectFlag
+	}
+
+	hostname, _ := c.Hostname()
+	m := manualMetric(metrics.KindRecord, *nameFlag, end, durationFlag.Seconds())
+	m.ID, m.Project, m.Branch, m.Commit = c.NewID(), project, branch, commit
+	m.User, m.Hostname = currentUsername(c.UserInfo), hostname
+	if err := c.MetricsSaver(m, logPath); err != nil {
+		return fmt.Errorf("erro ao gravar registro: %v", err)
+	}
+	fmt.Fprintf(c.Out, "Registrado: %s (%s) em %s [%s]\n", m.Command,
+		metrics.FormatDuration(m.DurationSec, metrics.DurationAuto, true), m.Project, m.ID)
+	return nil
+}
+
+// parseAt interpreta --at no fuso local. "HH:MM" é o horário de hoje (de now).
+func parseAt(value string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range atLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	if t, err := time.ParseInLocation("15:04", value, time.Local); err == nil {
+		y, mo, d := now.In(time.Local).Date()
+		return time.Date(y, mo, d, t.Hour(), t.Minute(), 0, 0, time.Local), nil
+	}
+	return time.Time{}, fmt.Errorf("formato inválido para --at: %q (use RFC 3339, \"YYYY-MM-DD HH:MM\" ou \"HH:MM\")", value)
+}
+
+// manualMetric monta um registro de bmt record ou bmt span: nome em Command,
+// fim em Timestamp (como em bmt run) e status success. Os dados do projeto,
+// do usuário e o ID ficam com quem chama.
+func manualMetric(kind, name string, end time.Time, durationSec float64) metrics.BuildMetric {
+	return metrics.BuildMetric{
+		SchemaVersion: metrics.CurrentSchemaVersion,
+		Timestamp:     end.Format(time.RFC3339),
+		OS:            runtime.GOOS,
+		Command:       name,
+		DurationSec:   durationSec,
+		CPUs:          runtime.NumCPU(),
+		Status:        "success",
+		Kind:          kind,
+	}
+}
+
+func currentUsername(userInfo func() (*user.User, error)) string {
+	if u, err := userInfo(); err == nil && u != nil {
+		return u.Username
+	}
+	return "unknown"
+}
+
+func (c *RecordCommand) Aliases() []string {
+	return []string{}
+}
+
+func (c *RecordCommand) ensureDefaults() {
+	if c.Out == nil {
+		c.Out = os.Stdout
+	}
+	if c.GitInfo == nil {
+		c.GitInfo = git.GetInfo
+	}
+	if c.MetricsSaver == nil {
+		c.MetricsSaver = metrics.Save
+	}
+	if c.UserInfo == nil {
+		c.UserInfo = user.Current
+	}
+	if c.Hostname == nil {
+		c.Hostname = os.Hostname
+	}
+	if c.NewID == nil {
+		c.NewID = metrics.NewID
+	}
+	if c.Now == nil {
+		c.Now = time.Now
+	}
+}
+
+func init() {
+	Register(&RecordCommand{})
+}
@@ -0,0 +1,68 @@
+package commands_test
+
+import (
+	"bytes"
+	"dev-metrics/internal/commands"
+	"dev-metrics/internal/metrics"
+	"os/user"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecordCommand_Run(t *testing.T) {
+	now := time.Date(2026, 10, 19, 16, 0, 0, 0, time.Local)
+	tests := []struct {
+		name        string
+		args        []string
+		wantErr     string
+		wantProject string
+		wantEnd     time.Time
+		wantSec     float64
+	}{
+		{name: "Agora", args: []string{"--name", "ci wait", "--duration", "14m"}, wantProject: "git-proj", wantEnd: now, wantSec: 840},
+		{name: "Projeto e horário", args: []string{"--project", "p", "--name", "ci wait", "--duration", "1h30m", "--at", "15:30"}, wantProject: "p", wantEnd: now.Add(-30 * time.Minute), wantSec: 5400},
+		{name: "Data completa", args: []string{"--name", "review", "--duration", "2h", "--at", "2026-10-18 10:00"}, wantProject: "git-proj", wantEnd: time.Date(2026, 10, 18, 10, 0, 0, 0, time.Local), wantSec: 7200},
+		{name: "Sem nome", args: []string{"--duration", "14m"}, wantErr: "--name"},
+		{name: "Sem duração", args: []string{"--name", "ci wait"}, wantErr: "--duration"},
+		{name: "Horário inválido", args: []string{"--name", "ci wait", "--duration", "1m", "--at", "ontem"}, wantErr: "--at"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var saved *metrics.BuildMetric
+			var out bytes.Buffer
+			cmd := &commands.RecordCommand{
+				Out:          &out,
+				GitInfo:      func() (string, string, string) { return "main", "1234567", "git-proj" },
+				MetricsSaver: func(m metrics.BuildMetric, filePath string) error { saved = &m; return nil },
+				UserInfo:     func() (*user.User, error) { return &user.User{Username: "u"}, nil },
+				Hostname:     func() (string, error) { return "h", nil },
+				NewID:        func() string { return "01REC" },
+				Now:          func() time.Time { return now },
+			}
+			err := cmd.Run(append([]string{"-log", t.TempDir() + "/log.jsonl"}, tt.args...))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Run() erro = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Run() erro: %v", err)
+			}
+			if saved == nil {
+				t.Fatal("nenhum registro gravado")
+			}
+			if saved.Kind != metrics.KindRecord || saved.Status != "success" || saved.ID != "01REC" || saved.User != "u" {
+				t.Errorf("registro = %+v", saved)
+			}
+			if saved.Project != tt.wantProject || saved.DurationSec != tt.wantSec || saved.Timestamp != tt.wantEnd.Format(time.RFC3339) {
+				t.Errorf("projeto, duração, fim = %q, %v, %q; want %q, %v, %q", saved.Project, saved.DurationSec, saved.Timestamp,
+					tt.wantProject, tt.wantSec, tt.wantEnd.Format(time.RFC3339))
+			}
+			if !strings.Contains(out.String(), "Registrado: ") {
+				t.Errorf("saída = %q", out.String())
+			}
+		})
+	}
+}
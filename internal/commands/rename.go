@@ -0,0 +1,91 @@
+package commands
+
+import (
+	metrics "dev-metrics/internal/metrics"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+type RenameCommand struct {
+	Out io.Writer
+}
+
+func (c *RenameCommand) Name() string { return "rename" }
+func (c *RenameCommand) Description() string {
+	return "Renomeia um projeto ou branch em todo o log"
+}
+
+func (c *RenameCommand) Run(args []string) error {
+	c.ensureDefaults()
+	fs := flag.NewFlagSet("rename", flag.ContinueOnError)
+	fs.SetOutput(c.Out)
+
+	logFlag := fs.String("log", "", "Caminho do arquivo de log")
+	projectFlag := fs.String("project", "", "Projeto a renomear (ou, junto com -branch, projeto onde a branch será renomeada)")
+	branchFlag := fs.String("branch", "", "Branch a renomear")
+	toFlag := fs.String("to", "", "Novo nome")
+	dryRun := fs.Bool("dry-run", false, "Apenas mostra o que seria alterado")
+
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Uso: bmt rename (-project antigo | -branch antiga [-project p]) -to novo [-dry-run]\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *toFlag == "" || (*projectFlag == "" && *branchFlag == "") {
+		fs.Usage()
+		return errors.New("informe -project ou -branch e o novo nome com -to")
+	}
+
+	logPath, err := metrics.GetLogFilePath(*logFlag)
+	if err != nil {
+		return fmt.Errorf("erro ao resolver log: %v", err)
+	}
+
+	field, from := "project", *projectFlag
+	if *branchFlag != "" {
+		field, from = "branch", *branchFlag
+	}
+
+	res, err := editLog(logPath, *dryRun, func(m metrics.BuildMetric, rec metrics.RawRecord) (bool, bool) {
+		switch field {
+		case "branch":
+			if m.Branch != from || (*projectFlag != "" && m.Project != *projectFlag) {
+				return true, false
+			}
+		default:
+			if m.Project != from {
+				return true, false
+			}
+		}
+		return true, metrics.SetRecordField(rec, field, *toFlag) == nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, ch := range res.Changes {
+		fmt.Fprintf(c.Out, "~ linha %d: %s\n", ch.Line, describeMetric(ch.Before))
+	}
+	fmt.Fprintf(c.Out, "\n%s '%s' -> '%s'", field, from, *toFlag)
+	printEditSummary(c.Out, res, *dryRun)
+	return nil
+}
+
+func (c *RenameCommand) ensureDefaults() {
+	if c.Out == nil {
+		c.Out = os.Stdout
+	}
+}
+
+func (c *RenameCommand) Aliases() []string {
+	return []string{}
+}
+
+func init() {
+	Register(&RenameCommand{})
+}
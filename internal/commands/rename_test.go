@@ -0,0 +1,64 @@
+package commands_test
+
+import (
+	"bytes"
+	"dev-metrics/internal/commands"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRenameCommand_Run(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		wantErr   bool
+		wantCount int
+		wantText  string
+	}{
+		{
+			name:      "Renomeia projeto",
+			args:      []string{"-project", "wrong", "-to", "frontend"},
+			wantCount: 1,
+			wantText:  `"project":"frontend"`,
+		},
+		{
+			name:      "Renomeia branch em todos os projetos",
+			args:      []string{"-branch", "main", "-to", "master"},
+			wantCount: 2,
+			wantText:  `"branch":"master"`,
+		},
+		{
+			name:      "Renomeia branch apenas em um projeto",
+			args:      []string{"-branch", "feat", "-project", "backend", "-to", "x"},
+			wantCount: 0,
+		},
+		{
+			name:    "Sem destino",
+			args:    []string{"-project", "wrong"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeTempLog(t, editableLog)
+			var out bytes.Buffer
+			c := &commands.RenameCommand{Out: &out}
+
+			err := c.Run(append([]string{"-log", path}, tt.args...))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			data, _ := os.ReadFile(path)
+			if tt.wantText != "" && strings.Count(string(data), tt.wantText) != tt.wantCount {
+				t.Errorf("log contém %d ocorrências de %s, want %d:\n%s", strings.Count(string(data), tt.wantText), tt.wantText, tt.wantCount, data)
+			}
+			if strings.Count(string(data), "\n") != 3 {
+				t.Errorf("rename não deveria remover registros:\n%s", data)
+			}
+		})
+	}
+}
@@ -12,8 +12,10 @@ import (
 )
 
 type ReportCommand struct {
-	FileOpener func(name string) (io.ReadCloser, error)
-	Out        io.Writer
+	FileOpener       func(name string) (io.ReadCloser, error)
+	LoadToolchains   func(logPath string) (map[string]metrics.ToolchainInfo, error)
+	LoadHostProfiles func(logPath string) (map[string]metrics.HostProfile, error)
+	Out              io.Writer
 }
 
 func (c *ReportCommand) Name() string { return "report" }
@@ -24,31 +26,55 @@ func (c *ReportCommand) Description() string {
 func (c *ReportCommand) Run(args []string) error {
 	c.ensureDefaults()
 	fs := flag.NewFlagSet("report", flag.ContinueOnError)
-	logFlag := fs.String("log", "", "Caminho do arquivo de log")
+	var logFlag stringList
+	fs.Var(&logFlag, "log", "Caminho do arquivo de log (pode ser repetido)")
 	sinceFlag := fs.String("since", "", "Data de início (YYYY-MM-DD) para filtrar o relatório")
 	untilFlag := fs.String("until", "", "Data de fim (YYYY-MM-DD) para filtrar o relatório")
 	unitFlag := fs.String("unit", "auto", "Unidade para os totais (auto|s|min|h)")
+	byFlag := fs.String("by", "project", "Agrupamento do relatório (project|tool|phase|toolchain|host|target)")
+	phasesFlag := fs.Bool("phases", false, "Agrega a duração de cada fase por semana (o mesmo que --by phase)")
+	ioFlag := fs.Bool("io", false, "Lista a atividade de disco e I/O de cada build")
+	failuresFlag := fs.Bool("failures", false, "Mostra o tempo perdido com falhas por classe e projeto")
+	excludeContendedFlag := fs.Bool("exclude-contended", false, "Ignora execuções sob contenção (carga alta, outros builds, bateria ou throttling)")
+	benchmarksFlag := fs.String("benchmarks", "exclude", "Iterações de bmt run --repeat no relatório (exclude|include|only)")
+	nestedFlag := fs.Bool("nested", false, "Inclui execuções aninhadas (bmt run dentro de bmt run), cujo tempo já conta na execução pai")
+	kindsFlag := fs.String("kinds", "", "Registros manuais incluídos, separados por vírgula (record|span); aparecem como <projeto> / <tipo>")
+	cacheFlag := fs.Bool("cache", false, "Relaciona a taxa de acerto de cache (probes) com a duração dos builds")
+	topTargetsFlag := fs.Int("top-targets", 0, "Lista os N alvos mais lentos (.ninja_log e bmt make) com a tendência da duração")
 	fs.SetOutput(c.Out)
 	fs.Usage = func() {
 		fs.PrintDefaults()
 		fmt.Fprintf(fs.Output(), "%s", `Exemplo:
-  bmt report --log ./logs/dev-metrics.log --since 2024-01-01 --until 2024-01-31`)
+  bmt report --log ./logs/dev-metrics.log --since 2024-01-01 --until 2024-01-31
+  bmt report --log laptop.jsonl --log ci.jsonl
+  bmt report --by tool --since 2024-01-01
+  bmt report --by toolchain
+  bmt report --by host
+  bmt report --by target --since 2024-01-01
+  bmt report --exclude-contended --since 2024-01-01
+  bmt report --benchmarks only
+  bmt report --kinds record,span
+  bmt report --io --since 2024-01-01
+  bmt report --failures --unit h
+  bmt report --phases --since 2024-01-01
+  bmt report --cache --since 2024-01-01
+  bmt report --top-targets 20 --since 2024-01-01`)
 	}
 	err := fs.Parse(args)
 	if err != nil {
 		return err
 	}
 
-	logPath, err := metrics.PrintResolvedLogPath(c.Out, "Usando arquivo de log: ", *logFlag)
+	logPaths, err := resolveLogPaths(c.Out, "Usando arquivo de log: ", logFlag)
 	if err != nil {
 		return fmt.Errorf("Erro ao obter path do arquivo de log: %v\n", err)
 	}
 
-	file, err := c.FileOpener(logPath)
+	logs, err := openLogs(c.FileOpener, logPaths)
 	if err != nil {
-		return fmt.Errorf("Erro ao abrir log (%s): %v", logPath, err)
+		return fmt.Errorf("Erro ao abrir log: %v", err)
 	}
-	defer file.Close()
+	defer closeLogs(logs)
 
 	// Parse das opções
 	opts := metrics.ReportOptions{}
@@ -68,13 +94,84 @@ func (c *ReportCommand) Run(args []string) error {
 		opts.Until = t
 	}
 
+	if opts.GroupBy, err = metrics.ParseGroupBy(*byFlag); err != nil {
+		return err
+	}
+	if *phasesFlag {
+		opts.GroupBy = metrics.GroupByPhase
+	}
+	opts.ExcludeContended = *excludeContendedFlag
+	opts.IncludeNested = *nestedFlag
+	if opts.Kinds, err = metrics.ParseReportKinds(*kindsFlag); err != nil {
+		return err
+	}
+	if opts.Benchmarks, err = metrics.ParseBenchmarkFilter(*benchmarksFlag); err != nil {
+		return err
+	}
+
 	unit, err := metrics.ParseDurationUnit(*unitFlag)
 	if err != nil {
 		return err
 	}
 
+	if *ioFlag {
+		ioData, err := metrics.GenerateIOReport(logs, opts)
+		if err != nil {
+			return fmt.Errorf("Erro ao processar dados: %v", err)
+		}
+		ui.RenderIOTable(c.Out, ioData)
+		return nil
+	}
+
+	if *failuresFlag {
+		failureData, err := metrics.GenerateFailureReport(logs, opts)
+		if err != nil {
+			return fmt.Errorf("Erro ao processar dados: %v", err)
+		}
+		ui.RenderFailureTable(c.Out, failureData, unit)
+		return nil
+	}
+
+	if *topTargetsFlag > 0 {
+		targetData, err := metrics.GenerateTargetReport(logs, opts, *topTargetsFlag)
+		if err != nil {
+			return fmt.Errorf("Erro ao processar dados: %v", err)
+		}
+		ui.RenderTargetTable(c.Out, targetData)
+		return nil
+	}
+
+	if *cacheFlag {
+		cacheData, err := metrics.GenerateCacheReport(logs, opts)
+		if err != nil {
+			return fmt.Errorf("Erro ao processar dados: %v", err)
+		}
+		ui.RenderCacheTable(c.Out, cacheData)
+		return nil
+	}
+
+	if opts.GroupBy == metrics.GroupByToolchain {
+		// As versões de cada impressão digital ficam em toolchains.jsonl, ao lado de cada log
+		toolchains, err := loadStores(logPaths, c.LoadToolchains)
+		if err != nil {
+			return fmt.Errorf("Erro ao ler toolchains: %v", err)
+		}
+		toolchainData, err := metrics.GenerateToolchainReport(logs, toolchains, opts)
+		if err != nil {
+			return fmt.Errorf("Erro ao processar dados: %v", err)
+		}
+		ui.RenderToolchainTable(c.Out, toolchainData)
+		return nil
+	}
+
+	if opts.GroupBy == metrics.GroupByHost {
+		if opts.HostProfiles, err = loadStores(logPaths, c.LoadHostProfiles); err != nil {
+			return fmt.Errorf("Erro ao ler perfis de máquina: %v", err)
+		}
+	}
+
 	// Geração do relatório
-	reportData, err := metrics.GenerateReport(file, opts)
+	reportData, err := metrics.GenerateReport(logs, opts)
 	if err != nil {
 		return fmt.Errorf("Erro ao processar dados: %v", err)
 	}
@@ -95,6 +192,12 @@ func (c *ReportCommand) ensureDefaults() {
 			return os.Open(name)
 		}
 	}
+	if c.LoadHostProfiles == nil {
+		c.LoadHostProfiles = metrics.LoadHostProfiles
+	}
+	if c.LoadToolchains == nil {
+		c.LoadToolchains = metrics.LoadToolchains
+	}
 	if c.Out == nil {
 		c.Out = os.Stdout
 	}
@@ -46,6 +46,121 @@ func TestReportCommand_Run(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "Invalid Kinds",
+			args: []string{"-log", "testdata/sample.log", "-kinds", "record,build"},
+			fileHandler: func(name string) (io.ReadCloser, error) {
+				return &mockReadCloser{Reader: bytes.NewBufferString(""), closeFunc: nil}, nil
+			},
+			wantErr: true,
+		},
+		{
+			name: "Multiple Log Files",
+			args: []string{"-log", "laptop.jsonl", "-log", "ci.jsonl"},
+			fileHandler: func(name string) (io.ReadCloser, error) {
+				if name != "laptop.jsonl" && name != "ci.jsonl" {
+					return nil, os.ErrNotExist
+				}
+				return &mockReadCloser{Reader: bytes.NewBufferString(`{"project":"A","timestamp":"2024-01-01T00:00:00Z","duration_sec":1}`), closeFunc: nil}, nil
+			},
+			wantErr: false,
+		},
+		{
+			name: "One Of Multiple Log Files Missing",
+			args: []string{"-log", "laptop.jsonl", "-log", "missing.jsonl"},
+			fileHandler: func(name string) (io.ReadCloser, error) {
+				if name == "missing.jsonl" {
+					return nil, os.ErrNotExist
+				}
+				return &mockReadCloser{Reader: bytes.NewBufferString(""), closeFunc: nil}, nil
+			},
+			wantErr: true,
+		},
+		{
+			name: "Group By Tool",
+			args: []string{"-log", "a.jsonl", "-by", "tool"},
+			fileHandler: func(name string) (io.ReadCloser, error) {
+				return &mockReadCloser{Reader: bytes.NewBufferString(`{"project":"A","timestamp":"2024-01-01T00:00:00Z","duration_sec":1,"tools":[{"name":"ld","cpu_sec":1}]}`)}, nil
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Invalid Group By",
+			args:    []string{"-log", "a.jsonl", "-by", "semana"},
+			wantErr: true,
+		},
+		{
+			name: "Phases Report",
+			args: []string{"-log", "a.jsonl", "-phases"},
+			fileHandler: func(name string) (io.ReadCloser, error) {
+				return &mockReadCloser{Reader: bytes.NewBufferString(`{"project":"A","timestamp":"2024-01-01T00:00:00Z","duration_sec":3,"phases":[{"name":"test","start_sec":0,"duration_sec":3}]}`)}, nil
+			},
+			wantErr: false,
+		},
+		{
+			name: "Failures Report",
+			args: []string{"-log", "a.jsonl", "-failures"},
+			fileHandler: func(name string) (io.ReadCloser, error) {
+				return &mockReadCloser{Reader: bytes.NewBufferString(`{"project":"A","timestamp":"2024-01-01T00:00:00Z","duration_sec":1,"status":"failure","failure_class":"network"}`)}, nil
+			},
+			wantErr: false,
+		},
+		{
+			name: "Toolchain Report",
+			args: []string{"-log", "a.jsonl", "-by", "toolchain"},
+			fileHandler: func(name string) (io.ReadCloser, error) {
+				return &mockReadCloser{Reader: bytes.NewBufferString(`{"project":"A","timestamp":"2024-01-01T00:00:00Z","duration_sec":1,"status":"success","toolchain":"abc"}`)}, nil
+			},
+			wantErr: false,
+		},
+		{
+			name: "Host Report",
+			args: []string{"-log", "a.jsonl", "-by", "host"},
+			fileHandler: func(name string) (io.ReadCloser, error) {
+				return &mockReadCloser{Reader: bytes.NewBufferString(`{"project":"A","timestamp":"2024-01-01T00:00:00Z","duration_sec":1,"status":"success","host_profile":"abc"}`)}, nil
+			},
+			wantErr: false,
+		},
+		{
+			name: "Exclude Contended",
+			args: []string{"-log", "a.jsonl", "-exclude-contended"},
+			fileHandler: func(name string) (io.ReadCloser, error) {
+				return &mockReadCloser{Reader: bytes.NewBufferString(`{"project":"A","timestamp":"2024-01-01T00:00:00Z","duration_sec":1,"cpus":4,"contention":{"load_avg_1":0,"concurrent_runs":2}}`)}, nil
+			},
+			wantErr: false,
+		},
+		{
+			name: "Only Benchmarks",
+			args: []string{"-log", "a.jsonl", "-benchmarks", "only"},
+			fileHandler: func(name string) (io.ReadCloser, error) {
+				return &mockReadCloser{Reader: bytes.NewBufferString(`{"project":"A","timestamp":"2024-01-01T00:00:00Z","duration_sec":1,"benchmark_id":"B1","iteration":1}`)}, nil
+			},
+			wantErr: false,
+		},
+		{
+			name: "Invalid Benchmarks Filter",
+			args: []string{"-log", "a.jsonl", "-benchmarks", "todos"},
+			fileHandler: func(name string) (io.ReadCloser, error) {
+				return &mockReadCloser{Reader: bytes.NewBufferString("")}, nil
+			},
+			wantErr: true,
+		},
+		{
+			name: "Cache Report",
+			args: []string{"-log", "a.jsonl", "-cache"},
+			fileHandler: func(name string) (io.ReadCloser, error) {
+				return &mockReadCloser{Reader: bytes.NewBufferString(`{"project":"A","timestamp":"2024-01-01T00:00:00Z","duration_sec":1,"status":"success","probes":{"ccache":{"hits":3,"misses":1}}}`)}, nil
+			},
+			wantErr: false,
+		},
+		{
+			name: "IO Report",
+			args: []string{"-log", "a.jsonl", "-io"},
+			fileHandler: func(name string) (io.ReadCloser, error) {
+				return &mockReadCloser{Reader: bytes.NewBufferString(`{"project":"A","timestamp":"2024-01-01T00:00:00Z","duration_sec":1,"io":{"read_bytes":10,"write_bytes":20}}`)}, nil
+			},
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -2,31 +2,82 @@ package commands
 
 import (
 	"context"
+	"dev-metrics/internal/config"
+	"dev-metrics/internal/failure"
 	"dev-metrics/internal/git"
+	"dev-metrics/internal/host"
 	metrics "dev-metrics/internal/metrics"
+	"dev-metrics/internal/ninja"
+	"dev-metrics/internal/notify"
+	"dev-metrics/internal/probe"
 	"dev-metrics/internal/runner"
+	"dev-metrics/internal/stats"
+	"dev-metrics/internal/toolchain"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"os"
+	"os/exec"
 	"os/signal"
 	"os/user"
 	"runtime"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 )
 
 type ExecCommand struct {
-	Out          io.Writer
-	Err          io.Writer
-	Runner       func(ctx context.Context, args []string) (float64, int)
-	GitInfo      func() (string, string, string)
-	MetricsSaver func(metrics.BuildMetric, string) error
-	UserInfo     func() (*user.User, error)
-	Hostname     func() (string, error)
+	Out            io.Writer
+	Err            io.Writer
+	Runner         func(ctx context.Context, args []string, opts runner.Options) runner.Result
+	GitInfo        func() (string, string, string)
+	MetricsSaver   func(metrics.BuildMetric, string) error
+	ToolchainSaver func(logPath string, info metrics.ToolchainInfo) error
+	UserInfo       func() (*user.User, error)
+	Hostname       func() (string, error)
+	NewID          func() string
+	Config         func() (*config.Config, error)
+	ProbeExec      probe.Exec
+	Toolchain      func(tools []toolchain.Tool) ([]toolchain.Version, error)
+	HostProfile    func(hostname, buildDir string) metrics.HostProfile
+	HostSaver      func(logPath string, p metrics.HostProfile) error
+	// StartContention mede a contenção desde a chamada até a chamada da função retornada
+	StartContention func() (finish func() *metrics.Contention)
+	// Prepare executa o comando de -prepare antes de cada iteração do benchmark
+	Prepare func(ctx context.Context, command string) error
+	// Estimate calcula a duração esperada a partir do histórico do comando no log
+	Estimate func(logPath, project, fingerprint string) (metrics.Estimate, error)
+	// Interactive indica se a linha de status pode ser desenhada (stderr é um terminal)
+	Interactive func() bool
+	// Notify avisa o fim de execuções longas (ver -notify-after)
+	Notify func(settings notify.Settings, m notify.Message) error
+	// Webhooks avisa os webhooks de notify.webhooks cujas condições a execução atende
+	Webhooks func(hooks []notify.Webhook, m notify.Message) error
+	// Getenv lê a execução pai (ver EnvParentRunID)
+	Getenv func(string) string
+	// Env é acrescentado ao ambiente do comando medido (usado por bmt make)
+	Env []string
+	// WatchNinja guarda o estado do .ninja_log nos dirs; a função retornada lê os
+	// alvos mais lentos se o log mudou desde a chamada (ver -ninja)
+	WatchNinja func(dirs []string, top int) (targets func() ([]metrics.TargetDuration, error))
 }
 
+// Variáveis passadas ao comando medido, para que um bmt run dentro dele seja
+// registrado como filho desta execução.
+const (
+	EnvParentRunID = "BMT_PARENT_RUN_ID"
+	EnvParentDepth = "BMT_PARENT_DEPTH"
+)
+
+const (
+	estimateRuns    = 20 // Execuções recentes consideradas na duração esperada
+	minEstimateRuns = 3  // Abaixo disso o histórico não é mostrado
+)
+
 func (c *ExecCommand) Name() string { return "run" }
 func (c *ExecCommand) Description() string {
 	return "Executa e mede um comando (ex: mtx run cmake --build .)"
@@ -39,9 +90,29 @@ func (c *ExecCommand) Run(args []string) error {
 	fs := flag.NewFlagSet("run", flag.ContinueOnError)
 	fs.SetOutput(c.Out)
 	logFlag := fs.String("log", "", "Caminho customizado para o arquivo de log")
+	sampleFlag := fs.Duration("sample", 0, "Intervalo de amostragem da árvore de processos, ex.: 500ms (Linux)")
+	sampleOutFlag := fs.String("sample-out", "", "Grava a série temporal completa da amostragem neste arquivo JSONL")
+	topToolsFlag := fs.Int("top-tools", runner.DefaultTopTools, "Quantos executáveis guardar no detalhamento de CPU (com -sample)")
+	keepOutputFlag := fs.Bool("keep-output", false, "Salva o final da saída mesmo quando o comando tem sucesso")
+	phasesFlag := fs.Bool("phases", false, "Reconhece marcadores de fase (::bmt-phase name=x::) na saída do comando; implica capturar a saída")
+	phaseFDFlag := fs.Bool("phase-fd", false, "Passa ao comando um descritor em $BMT_PHASE_FD para marcar fases (Unix)")
+	outputTailFlag := fs.Int("output-tail", runner.DefaultOutputTail/1024, "KB do final da saída guardados para falhas (0 liga o comando direto aos descritores do bmt, sem linha de status)")
+	probesFlag := fs.Bool("probes", true, "Executa as probes da configuração antes e depois do comando")
+	toolchainFlag := fs.Bool("toolchain", true, "Registra a impressão digital das versões das ferramentas de build")
+	etaFlag := fs.Bool("eta", true, "Mostra a duração esperada (histórico do mesmo projeto e comando) e avisa se a execução foi lenta")
+	statusFlag := fs.Bool("status", true, "Mostra no stderr o tempo decorrido contra o esperado (só em terminal)")
+	notifyAfterFlag := fs.Duration("notify-after", 0, "Notifica o fim de execuções mais longas que isso, ex.: 2m (padrão: notify.after da configuração; 0 desativa)")
+	notifyViaFlag := fs.String("notify-via", "", "Backends de notificação separados por vírgula: auto|desktop|osc9|osc777|bell|command (padrão: notify.backends da configuração)")
+	repeatFlag := fs.Int("repeat", 1, "Modo benchmark: mede o comando N vezes e mostra as estatísticas")
+	warmupFlag := fs.Int("warmup", 0, "Modo benchmark: execuções de aquecimento, não registradas, antes das medidas")
+	retriesFlag := fs.Int("retries", 0, "Repete o comando até N vezes se ele falhar, registrando cada tentativa")
+	retryOnFlag := fs.String("retry-on", "", "Códigos de saída que disparam nova tentativa, ex.: 1,2 (padrão: qualquer falha)")
+	prepareFlag := fs.String("prepare", "", "Modo benchmark: comando de shell executado antes de cada execução (ex.: \"make clean\")")
+	ninjaFlag := fs.String("ninja", "auto", "Diretório do build cujo .ninja_log é importado após a execução (auto: ninja -C, cmake --build, . ou ./build; off desativa)")
+	topTargetsFlag := fs.Int("top-targets", ninja.DefaultTopTargets, "Quantos alvos do .ninja_log guardar, dos mais lentos (0 guarda todos)")
 
 	fs.Usage = func() {
-		fmt.Fprintf(fs.Output(), "Uso: bmt run [-log path] <comando> [args...]\n")
+		fmt.Fprintf(fs.Output(), "Uso: bmt run [-log path] [-retries N [-retry-on códigos]] [-repeat N [-warmup K] [-prepare cmd]] <comando> [args...]\n")
 		fs.PrintDefaults()
 		// Note: PrintResolvedLogPath writes to fs.Output() internally if passed
 		metrics.PrintResolvedLogPath(fs.Output(), "Arquivo de log: ", fs.Lookup("log").Value.String())
@@ -63,24 +134,184 @@ func (c *ExecCommand) Run(args []string) error {
 		return errors.New(fmt.Sprint("erro ao resolver caminho do log:", err))
 	}
 
-	// Cria um context que pode ser cancelado
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := interruptContext()
 	defer cancel()
 
-	// Captura SIGINT (Ctrl+C) e cancela o contexto
+	cfg := c.loadConfig()
+	settings := runSettings{
+		logPath: logPath,
+		runner: runner.Options{
+			SampleInterval: *sampleFlag,
+			TopTools:       *topToolsFlag,
+			OutputTail:     *outputTailFlag * 1024,
+			Phases:         *phasesFlag,
+			PhaseFD:        *phaseFDFlag,
+			Env:            c.Env,
+		},
+		sampleOut:  *sampleOutFlag,
+		keepOutput: *keepOutputFlag,
+		toolchain:  *toolchainFlag,
+		topTargets: *topTargetsFlag,
+	}
+	switch *ninjaFlag {
+	case "off":
+	case "auto":
+		settings.ninjaDirs = ninja.BuildDirs(cmdArgs)
+	default:
+		settings.ninjaDirs = []string{*ninjaFlag}
+	}
+	if *probesFlag {
+		settings.probes = cfg.Probes
+	}
+	settings.branch, settings.commit, settings.project = c.GitInfo()
+	settings.parentID, settings.depth = c.parent()
+	settings.notify = cfg.Notify
+	if *notifyViaFlag != "" {
+		settings.notify.Backends = strings.Split(*notifyViaFlag, ",")
+	}
+	if settings.notifyAfter, err = settings.notify.Threshold(); err != nil {
+		fmt.Fprintf(c.Err, "[Metrics Error] %v\n", err)
+	}
+	if flagWasSet(fs, "notify-after") {
+		settings.notifyAfter = *notifyAfterFlag
+	}
+
+	benchmarkMode := *repeatFlag != 1 || *warmupFlag != 0 || *prepareFlag != ""
+	if !benchmarkMode {
+		// Num bmt run aninhado, o terminal já tem a linha de status do pai. A
+		// linha passa pela captura da saída; sem ela, o comando fica com os
+		// descritores do bmt
+		captured := settings.runner.OutputTail > 0 || settings.runner.Phases
+		settings.status = *statusFlag && settings.depth == 0 && captured && c.Interactive()
+		settings.eta = *etaFlag
+		if settings.eta || settings.status || settings.notifyAfter > 0 || len(settings.notify.Webhooks) > 0 {
+			settings.estimate = c.estimate(logPath, settings.project, cmdArgs)
+		}
+		if *etaFlag && settings.estimate.Runs >= minEstimateRuns {
+			fmt.Fprintf(c.Err, "[bmt] Duração esperada: %s (mediana), até %s (p90), com base em %d execuções\n",
+				metrics.FormatDuration(settings.estimate.Median, metrics.DurationAuto, true),
+				metrics.FormatDuration(settings.estimate.P90, metrics.DurationAuto, true), settings.estimate.Runs)
+		}
+	}
+	if *retriesFlag != 0 || *retryOnFlag != "" {
+		if benchmarkMode {
+			return errors.New("-retries não pode ser usado com -repeat, -warmup ou -prepare")
+		}
+		if *retriesFlag < 1 {
+			return errors.New("-retry-on requer -retries N, com N pelo menos 1")
+		}
+		retryOn, err := parseExitCodes(*retryOnFlag)
+		if err != nil {
+			return err
+		}
+		return c.retry(ctx, cmdArgs, cfg, settings, *retriesFlag, retryOn)
+	}
+	if !benchmarkMode {
+		metric := c.measure(ctx, cmdArgs, cfg, settings)
+		c.printFooter(cmdArgs, metric, settings.probes, settings.shownEstimate())
+		c.notifyDone(settings, metric)
+		return nil
+	}
+	if *repeatFlag < 1 || *warmupFlag < 0 {
+		return errors.New("-repeat deve ser pelo menos 1 e -warmup não pode ser negativo")
+	}
+	if *sampleOutFlag != "" && *repeatFlag > 1 {
+		return errors.New("-sample-out não pode ser usado com -repeat")
+	}
+	return c.benchmark(ctx, cmdArgs, cfg, settings, *repeatFlag, *warmupFlag, *prepareFlag)
+}
+
+// interruptContext cria um context cancelado no primeiro SIGINT (Ctrl+C), para
+// que o comando medido termine e a execução seja registrada como interrompida.
+func interruptContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT)
 	go func() {
-		<-sigChan
-		cancel()
+		select {
+		case <-sigChan:
+			cancel()
+		case <-ctx.Done():
+		}
 	}()
+	return ctx, func() {
+		signal.Stop(sigChan)
+		cancel()
+	}
+}
+
+// runSettings reúne as opções de bmt run aplicadas a cada execução medida.
+type runSettings struct {
+	logPath    string
+	runner     runner.Options
+	sampleOut  string
+	keepOutput bool
+	probes     []probe.Probe
+	toolchain  bool
+
+	ninjaDirs  []string // Onde procurar o .ninja_log; vazio com -ninja off
+	topTargets int
+
+	benchmarkID string // Vazio fora do modo benchmark
+	iteration   int
+	variant     string // "A" ou "B" em bmt compare
+
+	attemptGroup string // Vazio sem -retries
+	attempt      int
+
+	// Metadados do repositório, lidos uma vez antes das execuções
+	branch, commit, project string
+
+	parentID string // Execução que roda este bmt run; vazio no nível superior
+	depth    int
+
+	estimate metrics.Estimate // Duração esperada; Runs zero sem histórico
+	eta      bool             // Mostra a duração esperada ao usuário
+	status   bool             // Desenha a linha de status no stderr
 
-	duration, exitCode := c.Runner(ctx, cmdArgs)
+	notify      notify.Settings
+	notifyAfter time.Duration // Zero desativa as notificações
+}
+
+// shownEstimate é a duração esperada exibida ao usuário (vazia com -eta=false).
+func (s runSettings) shownEstimate() metrics.Estimate {
+	if !s.eta {
+		return metrics.Estimate{}
+	}
+	return s.estimate
+}
+
+// measure executa o comando uma vez, monta a métrica com os metadados,
+// probes, toolchain e perfil da máquina e a salva no log.
+func (c *ExecCommand) measure(ctx context.Context, cmdArgs []string, cfg *config.Config, s runSettings) metrics.BuildMetric {
+	logPath, probes := s.logPath, s.probes
+	before := c.snapshotProbes(probes)
+	var versions []toolchain.Version
+	if s.toolchain {
+		versions = c.detectToolchain(cfg)
+	}
+
+	// O ID é escolhido antes para que execuções aninhadas apontem para esta
+	id := c.NewID()
+	opts := s.runner
+	opts.Env = append(slices.Clip(opts.Env), EnvParentRunID+"="+id, EnvParentDepth+"="+strconv.Itoa(s.depth))
+	if s.status {
+		opts.Status = &runner.StatusLine{Out: c.Err, Text: statusText(s.shownEstimate())}
+	}
+
+	var ninjaTargets func() ([]metrics.TargetDuration, error)
+	if len(s.ninjaDirs) > 0 {
+		ninjaTargets = c.WatchNinja(s.ninjaDirs, s.topTargets)
+	}
+	finishContention := c.StartContention()
+	result := c.Runner(ctx, cmdArgs, opts)
+	duration, exitCode := result.DurationSec, result.ExitCode
+	contention := finishContention()
+	probeDeltas := c.probeDeltas(probes, before)
 
 	// 2. Coleta metadados
 	currUser, _ := c.UserInfo()
 	hostname, _ := c.Hostname()
-	branch, commit, project := c.GitInfo()
 
 	status := "success"
 	if exitCode != 0 {
@@ -99,30 +330,504 @@ func (c *ExecCommand) Run(args []string) error {
 
 	// 3. Monta a métrica
 	metric := metrics.BuildMetric{
-		Timestamp:   time.Now().Format(time.RFC3339),
-		User:        username,
-		Hostname:    hostname,
-		OS:          runtime.GOOS,
-		Project:     project,
-		Branch:      branch,
-		Commit:      commit,
-		Command:     fmt.Sprintf("%v", cmdArgs),
-		DurationSec: duration,
-		ReturnCode:  exitCode,
-		CPUs:        runtime.NumCPU(),
-		Status:      status,
+		SchemaVersion: metrics.CurrentSchemaVersion,
+		ID:            id,
+		Timestamp:     time.Now().Format(time.RFC3339),
+		User:          username,
+		Hostname:      hostname,
+		OS:            runtime.GOOS,
+		Project:       s.project,
+		Branch:        s.branch,
+		Commit:        s.commit,
+		Command:       fmt.Sprintf("%v", cmdArgs),
+		Args:          cmdArgs,
+		DurationSec:   duration,
+		ReturnCode:    exitCode,
+		CPUs:          runtime.NumCPU(),
+		Toolchain:     toolchain.Fingerprint(versions),
+		Status:        status,
+		CPUSec:        result.CPUSec,
+		Tools:         result.Tools,
+		IO:            result.IO,
+		Contention:    contention,
+		Phases:        result.Phases,
+		Probes:        probeDeltas,
+		BenchmarkID:   s.benchmarkID,
+		Iteration:     s.iteration,
+		Variant:       s.variant,
+		AttemptGroup:  s.attemptGroup,
+		Attempt:       s.attempt,
+		ParentID:      s.parentID,
+		Depth:         s.depth,
+	}
+
+	if result.Sampling != nil {
+		sampling := *result.Sampling
+		if s.sampleOut != "" {
+			if err := metrics.SaveSamples(s.sampleOut, result.Samples); err != nil {
+				fmt.Fprintf(c.Err, "[Metrics Error] %v\n", err)
+			} else {
+				sampling.SeriesFile = s.sampleOut
+			}
+		}
+		metric.Sampling = &sampling
+	}
+
+	// O perfil da máquina fica em hosts.jsonl; o registro guarda só o ID
+	wd, _ := os.Getwd()
+	profile := c.HostProfile(hostname, wd)
+	profile.FirstSeen = metric.Timestamp
+	if err := c.HostSaver(logPath, profile); err != nil {
+		fmt.Fprintf(c.Err, "[Metrics Error] %v\n", err)
+	} else {
+		metric.HostProfile = profile.ID
+	}
+
+	if metric.Toolchain != "" {
+		info := metrics.ToolchainInfo{Fingerprint: metric.Toolchain, Hostname: hostname, FirstSeen: metric.Timestamp, Versions: map[string]string{}}
+		for _, v := range versions {
+			info.Versions[v.Name] = v.Version
+		}
+		if err := c.ToolchainSaver(logPath, info); err != nil {
+			fmt.Fprintf(c.Err, "[Metrics Error] %v\n", err)
+		}
+	}
+
+	if status == "failure" {
+		metric.FailureClass = c.classify(cfg, exitCode, result.Output)
+	}
+
+	// Só vale um .ninja_log escrito por este comando
+	if ninjaTargets != nil {
+		targets, err := ninjaTargets()
+		if err != nil {
+			fmt.Fprintf(c.Err, "[Metrics Error] %v\n", err)
+		}
+		metric.Targets = targets
+	}
+
+	// Guarda o final da saída para diagnosticar falhas com 'bmt show <id>'
+	if len(result.Output) > 0 && (status != "success" || s.keepOutput) {
+		if file, err := metrics.SaveOutput(logPath, metric.ID, result.Output); err != nil {
+			fmt.Fprintf(c.Err, "[Metrics Error] %v\n", err)
+		} else {
+			metric.OutputFile = file
+		}
 	}
 
 	// 4. Salva (falha silenciosa para não atrapalhar o dev)
 	if err := c.MetricsSaver(metric, logPath); err != nil {
 		fmt.Fprintf(c.Err, "[Metrics Error] %v\n", err)
 	}
+	return metric
+}
+
+// printFooter resume uma execução medida ao final de bmt run. Com histórico
+// suficiente em estimate, avisa se a execução foi mais lenta que o normal.
+func (c *ExecCommand) printFooter(cmdArgs []string, metric metrics.BuildMetric, probes []probe.Probe, estimate metrics.Estimate) {
+	duration := metric.DurationSec
 	adjustedDuration := metrics.FormatDuration(duration, metrics.AutoDurationUnit(duration), true)
 
-	fmt.Printf("\n------BMT------\n- Duração do comando %s: %v.\n- Finalizado em %v\n---------------\n", cmdArgs[0], adjustedDuration, time.Now().Format(time.RFC1123))
+	fmt.Printf("\n------BMT------\n- Duração do comando %s: %v.\n- Finalizado em %v\n", cmdArgs[0], adjustedDuration, time.Now().Format(time.RFC1123))
+	if s := metric.Sampling; s != nil {
+		fmt.Printf("- Paralelismo médio: %.1f (pico %.1f) de %d CPUs, ociosidade %.0f%%, pico de RSS %.1f MB\n",
+			s.AvgParallelism, s.PeakParallelism, metric.CPUs, s.IdleFraction*100, float64(s.PeakRSSBytes)/(1024*1024))
+	}
+	if io := metric.IO; io != nil {
+		fmt.Printf("- I/O: %.1f MB lidos e %.1f MB escritos no disco", float64(io.ReadBytes)/(1024*1024), float64(io.WriteBytes)/(1024*1024))
+		if io.ReadChars > 0 || io.WriteChars > 0 {
+			fmt.Printf(" (%.1f MB / %.1f MB via read/write)", float64(io.ReadChars)/(1024*1024), float64(io.WriteChars)/(1024*1024))
+		}
+		fmt.Printf("\n")
+	}
+	if len(metric.Tools) > 0 {
+		fmt.Printf("- CPU total: %s. Maiores consumidores:\n", metrics.FormatDuration(metric.CPUSec, metrics.DurationAuto, true))
+	}
+	for i, t := range metric.Tools {
+		if i == 5 {
+			break
+		}
+		fmt.Printf("  %-16s CPU %-10s (%d processos)\n", t.Name, metrics.FormatDuration(t.CPUSec, metrics.DurationAuto, true), t.Procs)
+	}
+	if len(metric.Phases) > 0 {
+		fmt.Printf("- Fases:\n")
+		for _, p := range metric.Phases {
+			fmt.Printf("  %-16s %s\n", p.Name, metrics.FormatDuration(p.DurationSec, metrics.DurationAuto, true))
+		}
+	}
+	if len(metric.Targets) > 0 {
+		fmt.Printf("- Alvos mais lentos (.ninja_log):\n")
+	}
+	for i, t := range metric.Targets {
+		if i == 5 {
+			break
+		}
+		fmt.Printf("  %-40s %s\n", t.Name, metrics.FormatDuration(t.DurationSec, metrics.DurationAuto, true))
+	}
+	for _, p := range probes {
+		if d, ok := metric.Probes[p.Name]; ok {
+			fmt.Printf("- %s: %s\n", p.Name, formatProbeCounters(d))
+		}
+	}
+	if metric.Status == "success" && estimate.Runs >= minEstimateRuns && estimate.Slow(metric.DurationSec) {
+		fmt.Printf("- Execução mais lenta que o normal: p90 de %s e mediana de %s nas últimas %d execuções\n",
+			metrics.FormatDuration(estimate.P90, metrics.DurationAuto, true),
+			metrics.FormatDuration(estimate.Median, metrics.DurationAuto, true), estimate.Runs)
+	}
+	if metric.Attempt > 1 {
+		fmt.Printf("- Tentativa %d (as anteriores falharam); comandos instáveis: bmt flaky\n", metric.Attempt)
+	}
+	if reasons := metric.Contention.Reasons(metric.CPUs); len(reasons) > 0 {
+		fmt.Printf("- Execução sob contenção: %s\n", strings.Join(reasons, ", "))
+	}
+	if metric.FailureClass != "" {
+		fmt.Printf("- Classe da falha: %s\n", metric.FailureClass)
+	}
+	if metric.OutputFile != "" {
+		fmt.Printf("- Saída guardada; veja com: bmt show %s\n", metric.ID)
+	}
+	fmt.Printf("---------------\n")
+}
+
+// parent lê do ambiente a execução pai, quando este bmt run roda dentro de outro.
+func (c *ExecCommand) parent() (id string, depth int) {
+	id = c.Getenv(EnvParentRunID)
+	if id == "" {
+		return "", 0
+	}
+	parentDepth, _ := strconv.Atoi(c.Getenv(EnvParentDepth))
+	return id, parentDepth + 1
+}
+
+// notifyDone avisa o fim de uma execução que durou pelo menos s.notifyAfter e
+// repassa a execução aos webhooks, que decidem pelas próprias condições.
+// Execuções interrompidas pelo usuário não são notificadas, nem as aninhadas:
+// quem avisa é a execução de nível superior.
+func (c *ExecCommand) notifyDone(s runSettings, m metrics.BuildMetric) {
+	if m.Status == "interrupted" || s.depth > 0 {
+		return
+	}
+	msg := notification(m, s.estimate)
+	if s.notifyAfter > 0 && m.DurationSec >= s.notifyAfter.Seconds() {
+		if err := c.Notify(s.notify, msg); err != nil {
+			fmt.Fprintf(c.Err, "[Metrics Error] notificação: %v\n", err)
+		}
+	}
+	if len(s.notify.Webhooks) > 0 {
+		if err := c.Webhooks(s.notify.Webhooks, msg); err != nil {
+			fmt.Fprintf(c.Err, "[Metrics Error] webhook: %v\n", err)
+		}
+	}
+}
+
+// notification descreve o fim de uma execução: status, duração e comparação
+// com a mediana das execuções anteriores, se houver histórico.
+func notification(m metrics.BuildMetric, estimate metrics.Estimate) notify.Message {
+	name := m.Command
+	if len(m.Args) > 0 {
+		name = m.Args[0]
+	}
+	title := fmt.Sprintf("bmt: %s concluído", name)
+	if m.Status != "success" {
+		title = fmt.Sprintf("bmt: %s falhou (código %d)", name, m.ReturnCode)
+	}
+	body := "Duração: " + metrics.FormatDuration(m.DurationSec, metrics.DurationAuto, true)
+	msg := notify.Message{Status: m.Status, ExitCode: m.ReturnCode, DurationSec: m.DurationSec, Project: m.Project,
+		Branch: m.Branch, Commit: m.Commit, Hostname: m.Hostname, Command: m.Command, RunID: m.ID}
+	if estimate.Runs >= minEstimateRuns && estimate.Median > 0 {
+		msg.MedianSec = estimate.Median
+		diff := (m.DurationSec/estimate.Median - 1) * 100
+		direction := "acima"
+		if diff < 0 {
+			diff, direction = -diff, "abaixo"
+		}
+		body += fmt.Sprintf(" (%.0f%% %s da mediana de %s)", diff, direction, metrics.FormatDuration(estimate.Median, metrics.DurationAuto, true))
+	}
+	msg.Title, msg.Body = title, body+"\nProjeto: "+m.Project
+	return msg
+}
+
+// estimate consulta o histórico do comando. Um log ilegível é reportado e a
+// execução segue sem duração esperada.
+func (c *ExecCommand) estimate(logPath, project string, cmdArgs []string) metrics.Estimate {
+	e, err := c.Estimate(logPath, project, metrics.BuildMetric{Args: cmdArgs}.CommandFingerprint())
+	if err != nil {
+		fmt.Fprintf(c.Err, "[Metrics Error] %v\n", err)
+	}
+	return e
+}
+
+// statusText descreve o tempo decorrido em relação à duração esperada.
+func statusText(estimate metrics.Estimate) func(time.Duration) string {
+	format := func(sec float64) string { return metrics.FormatDuration(sec, metrics.DurationAuto, true) }
+	return func(elapsed time.Duration) string {
+		sec := elapsed.Seconds()
+		text := "[bmt] " + format(sec)
+		switch {
+		case estimate.Runs < minEstimateRuns || estimate.Median <= 0:
+		case sec <= estimate.Median:
+			text += fmt.Sprintf(" de ~%s (%.0f%%)", format(estimate.Median), sec/estimate.Median*100)
+		case sec <= estimate.P90:
+			text += fmt.Sprintf(", acima da mediana de %s (p90 %s)", format(estimate.Median), format(estimate.P90))
+		default:
+			text += fmt.Sprintf(", acima do p90 de %s", format(estimate.P90))
+		}
+		return text
+	}
+}
+
+// estimateFromLog lê a duração esperada do log; sem log ainda não há histórico.
+func estimateFromLog(logPath, project, fingerprint string) (metrics.Estimate, error) {
+	f, err := os.Open(logPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return metrics.Estimate{}, nil
+	}
+	if err != nil {
+		return metrics.Estimate{}, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return metrics.Estimate{}, err
+	}
+	return metrics.EstimateDuration(f, info.Size(), project, fingerprint, estimateRuns)
+}
+
+// retry executa o comando e, enquanto ele falhar com um código aceito por
+// retryOn (nil aceita qualquer um), tenta de novo até retries vezes. Cada
+// tentativa é registrada com o mesmo attempt_group.
+func (c *ExecCommand) retry(ctx context.Context, cmdArgs []string, cfg *config.Config, s runSettings, retries int, retryOn map[int]bool) error {
+	s.attemptGroup = c.NewID()
+	for attempt := 1; ; attempt++ {
+		s.attempt = attempt
+		metric := c.measure(ctx, cmdArgs, cfg, s)
+		if metric.Status != "failure" || attempt > retries || (retryOn != nil && !retryOn[metric.ReturnCode]) {
+			c.printFooter(cmdArgs, metric, s.probes, s.shownEstimate())
+			c.notifyDone(s, metric)
+			return nil
+		}
+		fmt.Fprintf(c.Out, "Tentativa %d/%d falhou (código %d) após %s; tentando de novo...\n",
+			attempt, retries+1, metric.ReturnCode, metrics.FormatDuration(metric.DurationSec, metrics.DurationAuto, true))
+	}
+}
+
+// parseExitCodes lê a lista de códigos de -retry-on (ex.: "1,2,137").
+// Uma lista vazia retorna nil.
+func parseExitCodes(s string) (map[int]bool, error) {
+	if s == "" {
+		return nil, nil
+	}
+	codes := make(map[int]bool)
+	for _, part := range strings.Split(s, ",") {
+		code, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || code <= 0 {
+			return nil, fmt.Errorf("código de saída inválido em -retry-on: %q", part)
+		}
+		codes[code] = true
+	}
+	return codes, nil
+}
+
+// benchmark executa o comando warmup vezes sem registrar e depois repeat vezes,
+// registrando cada iteração com o mesmo benchmark_id. Uma iteração sem sucesso
+// interrompe o benchmark; as estatísticas consideram as iterações concluídas.
+func (c *ExecCommand) benchmark(ctx context.Context, cmdArgs []string, cfg *config.Config, s runSettings, repeat, warmup int, prepare string) error {
+	for i := 1; i <= warmup; i++ {
+		if err := c.runPrepare(ctx, prepare); err != nil {
+			return err
+		}
+		result := c.Runner(ctx, cmdArgs, s.runner)
+		if ctx.Err() != nil {
+			return errors.New("benchmark interrompido durante o aquecimento")
+		}
+		if result.ExitCode != 0 {
+			return fmt.Errorf("aquecimento %d/%d falhou (código %d)", i, warmup, result.ExitCode)
+		}
+		fmt.Fprintf(c.Out, "Aquecimento %d/%d: %s\n", i, warmup, metrics.FormatDuration(result.DurationSec, metrics.DurationAuto, true))
+	}
+
+	s.benchmarkID = c.NewID()
+	var runs []metrics.BuildMetric
+	var stopErr error
+	for i := 1; i <= repeat; i++ {
+		if err := c.runPrepare(ctx, prepare); err != nil {
+			stopErr = err
+			break
+		}
+		s.iteration = i
+		m := c.measure(ctx, cmdArgs, cfg, s)
+		runs = append(runs, m)
+		fmt.Fprintf(c.Out, "Iteração %d/%d: %s\n", i, repeat, metrics.FormatDuration(m.DurationSec, metrics.DurationAuto, true))
+		if m.Status != "success" {
+			stopErr = fmt.Errorf("iteração %d/%d terminou com status %s (código %d); benchmark interrompido", i, repeat, m.Status, m.ReturnCode)
+			break
+		}
+	}
+	c.printBenchmarkSummary(cmdArgs, runs, warmup, s.benchmarkID)
+	return stopErr
+}
+
+// runPrepare executa o comando de -prepare, se houver.
+func (c *ExecCommand) runPrepare(ctx context.Context, command string) error {
+	if command == "" {
+		return nil
+	}
+	if err := c.Prepare(ctx, command); err != nil {
+		return fmt.Errorf("-prepare %q falhou: %v", command, err)
+	}
+	return nil
+}
+
+// printBenchmarkSummary mostra média, desvio padrão, mediana, faixa e outliers
+// das iterações com sucesso.
+func (c *ExecCommand) printBenchmarkSummary(cmdArgs []string, runs []metrics.BuildMetric, warmup int, benchmarkID string) {
+	var durations []float64
+	contended := 0
+	for _, m := range runs {
+		if m.Status == "success" {
+			durations = append(durations, m.DurationSec)
+		}
+		if m.Contended() {
+			contended++
+		}
+	}
+	format := func(sec float64) string { return metrics.FormatDuration(sec, metrics.DurationAuto, true) }
+
+	fmt.Fprintf(c.Out, "\n------BMT benchmark------\n- Comando: %s (%d execuções medidas, %d de aquecimento)\n",
+		strings.Join(cmdArgs, " "), len(durations), warmup)
+	if len(durations) > 0 {
+		summary := stats.Summarize(durations)
+		fmt.Fprintf(c.Out, "- Tempo (média ± σ): %s ± %s\n", format(summary.Mean), format(stats.StdDev(durations)))
+		fmt.Fprintf(c.Out, "- Mediana: %s\n", format(summary.Median))
+		fmt.Fprintf(c.Out, "- Faixa (mín … máx): %s … %s\n", format(summary.Min), format(summary.Max))
+		if outliers := stats.Outliers(durations); len(outliers) > 0 {
+			values := make([]string, len(outliers))
+			for i, idx := range outliers {
+				values[i] = format(durations[idx])
+			}
+			fmt.Fprintf(c.Out, "- %d outlier(s) detectado(s): %s. Use mais -warmup ou feche outros programas.\n",
+				len(outliers), strings.Join(values, ", "))
+		}
+	}
+	if contended > 0 {
+		fmt.Fprintf(c.Out, "- %d execução(ões) sob contenção; os números podem estar distorcidos\n", contended)
+	}
+	fmt.Fprintf(c.Out, "- Benchmark %s; veja com: bmt report --benchmarks only\n", benchmarkID)
+	fmt.Fprintf(c.Out, "---------------\n")
+}
+
+// shellPrepare executa command no shell do sistema, descartando a saída em caso de sucesso.
+func shellPrepare(ctx context.Context, command string) error {
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if tail := strings.TrimSpace(string(out)); tail != "" {
+			lines := strings.Split(tail, "\n")
+			return fmt.Errorf("%v: %s", err, lines[len(lines)-1])
+		}
+		return err
+	}
 	return nil
 }
 
+// loadConfig lê a configuração do usuário. Uma configuração inválida é
+// reportada e a execução segue com os padrões.
+func (c *ExecCommand) loadConfig() *config.Config {
+	cfg, err := c.Config()
+	if err != nil {
+		fmt.Fprintf(c.Err, "[Metrics Error] %v\n", err)
+		return &config.Config{}
+	}
+	return cfg
+}
+
+// classify aplica as regras de falha do usuário e as embutidas. Regras
+// inválidas são reportadas e as embutidas continuam valendo.
+func (c *ExecCommand) classify(cfg *config.Config, exitCode int, output []byte) string {
+	classifier, err := failure.NewClassifier(cfg.FailureRules)
+	if err != nil {
+		fmt.Fprintf(c.Err, "[Metrics Error] failure_rules: %v\n", err)
+		classifier, _ = failure.NewClassifier(nil)
+	}
+	return classifier.Classify(exitCode, output)
+}
+
+// detectToolchain consulta as versões das ferramentas de build. Falhas no cache
+// são reportadas, mas as versões encontradas continuam valendo.
+func (c *ExecCommand) detectToolchain(cfg *config.Config) []toolchain.Version {
+	tools := cfg.Toolchain
+	if len(tools) == 0 {
+		tools = toolchain.DefaultTools
+	}
+	versions, err := c.Toolchain(tools)
+	if err != nil {
+		fmt.Fprintf(c.Err, "[Metrics Error] %v\n", err)
+	}
+	return versions
+}
+
+// snapshotProbes lê os contadores de cada probe antes do comando. Probes que
+// falham são reportadas e ficam de fora da métrica.
+func (c *ExecCommand) snapshotProbes(probes []probe.Probe) map[string]map[string]float64 {
+	snapshots := make(map[string]map[string]float64, len(probes))
+	for _, p := range probes {
+		counters, err := p.Snapshot(context.Background(), c.ProbeExec)
+		if err != nil {
+			fmt.Fprintf(c.Err, "[Metrics Error] %v\n", err)
+			continue
+		}
+		snapshots[p.Name] = counters
+	}
+	return snapshots
+}
+
+// probeDeltas lê as probes de novo após o comando e calcula a variação.
+// Usa um contexto próprio para que a leitura aconteça mesmo após Ctrl+C.
+func (c *ExecCommand) probeDeltas(probes []probe.Probe, before map[string]map[string]float64) map[string]metrics.ProbeCounters {
+	var deltas map[string]metrics.ProbeCounters
+	for _, p := range probes {
+		prev, ok := before[p.Name]
+		if !ok {
+			continue
+		}
+		after, err := p.Snapshot(context.Background(), c.ProbeExec)
+		if err != nil {
+			fmt.Fprintf(c.Err, "[Metrics Error] %v\n", err)
+			continue
+		}
+		if deltas == nil {
+			deltas = make(map[string]metrics.ProbeCounters)
+		}
+		deltas[p.Name] = probe.Delta(prev, after)
+	}
+	return deltas
+}
+
+// formatProbeCounters resume os contadores de uma probe para o rodapé.
+func formatProbeCounters(d metrics.ProbeCounters) string {
+	hits, hasHits := d[probe.KeyHits]
+	misses, hasMisses := d[probe.KeyMisses]
+	if hasHits && hasMisses {
+		s := fmt.Sprintf("%.0f acertos, %.0f falhas", hits, misses)
+		if hits+misses > 0 {
+			s += fmt.Sprintf(" (%.0f%% de acerto)", hits/(hits+misses)*100)
+		}
+		return s
+	}
+	keys := make([]string, 0, len(d))
+	for k := range d {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = fmt.Sprintf("%s %+g", k, d[k])
+	}
+	return strings.Join(parts, ", ")
+}
+
 func (c *ExecCommand) Aliases() []string {
 	return []string{"exec", "r"}
 }
@@ -134,8 +839,39 @@ func (c *ExecCommand) ensureDefaults() {
 	if c.Err == nil {
 		c.Err = os.Stderr
 	}
+	if c.Config == nil {
+		c.Config = config.Load
+	}
+	if c.Toolchain == nil {
+		c.Toolchain = toolchain.NewDetector().Detect
+	}
+	if c.ProbeExec == nil {
+		c.ProbeExec = probe.DefaultExec
+	}
 	if c.Runner == nil {
-		c.Runner = runner.Run
+		c.Runner = runner.RunWithOptions
+	}
+	if c.Prepare == nil {
+		c.Prepare = shellPrepare
+	}
+	if c.Estimate == nil {
+		c.Estimate = estimateFromLog
+	}
+	if c.Interactive == nil {
+		c.Interactive = func() bool { return runner.IsTerminal(os.Stderr) }
+	}
+	if c.Notify == nil {
+		var terminal io.Writer
+		if runner.IsTerminal(os.Stderr) {
+			terminal = os.Stderr
+		}
+		c.Notify = notify.NewSender(terminal).Send
+	}
+	if c.Getenv == nil {
+		c.Getenv = os.Getenv
+	}
+	if c.Webhooks == nil {
+		c.Webhooks = notify.NewWebhookSender(notify.DefaultSpoolDir()).Send
 	}
 	if c.GitInfo == nil {
 		c.GitInfo = git.GetInfo
@@ -143,12 +879,32 @@ func (c *ExecCommand) ensureDefaults() {
 	if c.MetricsSaver == nil {
 		c.MetricsSaver = metrics.Save
 	}
+	if c.StartContention == nil {
+		c.StartContention = func() func() *metrics.Contention {
+			return host.StartContention(os.Getpid()).Finish
+		}
+	}
+	if c.HostProfile == nil {
+		c.HostProfile = host.Collect
+	}
+	if c.HostSaver == nil {
+		c.HostSaver = metrics.SaveHostProfile
+	}
+	if c.ToolchainSaver == nil {
+		c.ToolchainSaver = metrics.SaveToolchain
+	}
 	if c.UserInfo == nil {
 		c.UserInfo = user.Current
 	}
 	if c.Hostname == nil {
 		c.Hostname = os.Hostname
 	}
+	if c.NewID == nil {
+		c.NewID = metrics.NewID
+	}
+	if c.WatchNinja == nil {
+		c.WatchNinja = ninja.Watch
+	}
 }
 
 func init() {
@@ -4,13 +4,68 @@ import (
 	"bytes"
 	"context"
 	"dev-metrics/internal/commands"
+	"dev-metrics/internal/config"
+	"dev-metrics/internal/failure"
 	"dev-metrics/internal/metrics"
+	"dev-metrics/internal/notify"
+	"dev-metrics/internal/probe"
+	"dev-metrics/internal/runner"
+	"dev-metrics/internal/toolchain"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
 	"os/user"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
+// noToolchain evita consultar as ferramentas instaladas na máquina dos testes.
+func noToolchain([]toolchain.Tool) ([]toolchain.Version, error) { return nil, nil }
+
+// discardHostProfile evita gravar hosts.jsonl ao lado do log padrão.
+func discardHostProfile(string, metrics.HostProfile) error { return nil }
+
+// newTestExecCommand devolve um ExecCommand que não toca a máquina: o comando
+// termina com sucesso em 1 s e nada é gravado. Cada teste sobrepõe só os
+// campos que verifica.
+func newTestExecCommand(t *testing.T) *commands.ExecCommand {
+	t.Helper()
+	return &commands.ExecCommand{
+		Out: &bytes.Buffer{},
+		Err: &bytes.Buffer{},
+		Runner: func(ctx context.Context, args []string, opts runner.Options) runner.Result {
+			return runner.Result{DurationSec: 1}
+		},
+		Prepare:        func(context.Context, string) error { return nil },
+		Config:         func() (*config.Config, error) { return &config.Config{}, nil },
+		Estimate:       func(string, string, string) (metrics.Estimate, error) { return metrics.Estimate{}, nil },
+		Interactive:    func() bool { return false },
+		Notify:         func(notify.Settings, notify.Message) error { return nil },
+		Webhooks:       func([]notify.Webhook, notify.Message) error { return nil },
+		Getenv:         func(string) string { return "" },
+		GitInfo:        func() (string, string, string) { return "main", "1234567", "p" },
+		MetricsSaver:   func(metrics.BuildMetric, string) error { return nil },
+		UserInfo:       func() (*user.User, error) { return &user.User{Username: "u"}, nil },
+		Hostname:       func() (string, error) { return "h", nil },
+		Toolchain:      noToolchain,
+		ToolchainSaver: func(string, metrics.ToolchainInfo) error { return nil },
+		HostProfile:    func(string, string) metrics.HostProfile { return metrics.HostProfile{} },
+		HostSaver:      discardHostProfile,
+		StartContention: func() func() *metrics.Contention {
+			return func() *metrics.Contention { return nil }
+		},
+		WatchNinja: func([]string, int) func() ([]metrics.TargetDuration, error) {
+			return func() ([]metrics.TargetDuration, error) { return nil, nil }
+		},
+	}
+}
+
 func TestExecCommand_Run(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -60,25 +115,24 @@ func TestExecCommand_Run(t *testing.T) {
 			var savedMetric metrics.BuildMetric
 			var stdout, stderr bytes.Buffer
 
-			cmd := &commands.ExecCommand{
-				Out: &stdout,
-				Err: &stderr,
-				Runner: func(ctx context.Context, args []string) (float64, int) {
-					return tt.mockDuration, tt.mockExitCode
-				},
-				GitInfo: func() (string, string, string) {
-					return "main", "1234567", "test-project"
-				},
-				MetricsSaver: func(m metrics.BuildMetric, filePath string) error {
-					savedMetric = m
-					return tt.mockSaveErr
-				},
-				UserInfo: func() (*user.User, error) {
-					return &user.User{Username: "testuser"}, nil
-				},
-				Hostname: func() (string, error) {
-					return "testhost", nil
-				},
+			cmd := newTestExecCommand(t)
+			cmd.Out = &stdout
+			cmd.Err = &stderr
+			cmd.Runner = func(ctx context.Context, args []string, opts runner.Options) runner.Result {
+				return runner.Result{DurationSec: tt.mockDuration, ExitCode: tt.mockExitCode}
+			}
+			cmd.GitInfo = func() (string, string, string) {
+				return "main", "1234567", "test-project"
+			}
+			cmd.MetricsSaver = func(m metrics.BuildMetric, filePath string) error {
+				savedMetric = m
+				return tt.mockSaveErr
+			}
+			cmd.UserInfo = func() (*user.User, error) {
+				return &user.User{Username: "testuser"}, nil
+			}
+			cmd.Hostname = func() (string, error) {
+				return "testhost", nil
 			}
 
 			err := cmd.Run(tt.args)
@@ -108,6 +162,9 @@ func TestExecCommand_Run(t *testing.T) {
 			if savedMetric.Project != "test-project" {
 				t.Errorf("Metric.Project = %v, want %v", savedMetric.Project, "test-project")
 			}
+			if savedMetric.ID == "" {
+				t.Errorf("Metric.ID is empty")
+			}
 			if tt.expectedCmdStr != "" && savedMetric.Command != tt.expectedCmdStr {
 				t.Errorf("Metric.Command = %v, want %v", savedMetric.Command, tt.expectedCmdStr)
 			}
@@ -177,4 +234,741 @@ func TestExecCommand_ensureDefaults(t *testing.T) {
 	if c.Hostname == nil {
 		t.Error("Hostname is not set")
 	}
+	if c.NewID == nil {
+		t.Error("NewID is not set")
+	}
+	if c.Config == nil {
+		t.Error("Config is not set")
+	}
+	if c.Toolchain == nil || c.ToolchainSaver == nil {
+		t.Error("Toolchain is not set")
+	}
+	if c.HostProfile == nil || c.HostSaver == nil {
+		t.Error("HostProfile is not set")
+	}
+	if c.StartContention == nil {
+		t.Error("StartContention is not set")
+	}
+}
+
+func TestExecCommand_Sampling(t *testing.T) {
+	var saved metrics.BuildMetric
+	var gotOpts runner.Options
+	seriesPath := t.TempDir() + "/series.jsonl"
+	var stdout, stderr bytes.Buffer
+
+	cmd := newTestExecCommand(t)
+	cmd.Out = &stdout
+	cmd.Err = &stderr
+	cmd.Runner = func(ctx context.Context, args []string, opts runner.Options) runner.Result {
+		gotOpts = opts
+		return runner.Result{
+			DurationSec: 2,
+			Sampling:    &metrics.SamplingSummary{Samples: 2, AvgParallelism: 1.5, PeakParallelism: 2},
+			Samples:     []metrics.ProcessSample{{OffsetSec: 0.5, CPUs: 1}, {OffsetSec: 1, CPUs: 2}},
+			CPUSec:      3,
+			Tools:       []metrics.ToolUsage{{Name: "cc1plus", CPUSec: 2.5, WallSec: 1.5, Procs: 2}},
+			IO:          &metrics.IOStats{ReadBytes: 4096, ReadChars: 8192},
+			Phases:      []metrics.Phase{{Name: "compile", StartSec: 0, DurationSec: 2}},
+		}
+	}
+	cmd.MetricsSaver = func(m metrics.BuildMetric, filePath string) error { saved = m; return nil }
+
+	if err := cmd.Run([]string{"-sample", "500ms", "-sample-out", seriesPath, "-top-tools", "3", "-phases", "-phase-fd", "make"}); err != nil {
+		t.Fatalf("Run() erro: %v", err)
+	}
+	if gotOpts.SampleInterval != 500*time.Millisecond || gotOpts.TopTools != 3 {
+		t.Errorf("opts = %+v, want SampleInterval 500ms e TopTools 3", gotOpts)
+	}
+	if saved.CPUSec != 3 || len(saved.Tools) != 1 || saved.Tools[0].Name != "cc1plus" {
+		t.Errorf("CPUSec = %v, Tools = %+v", saved.CPUSec, saved.Tools)
+	}
+	if saved.IO == nil || saved.IO.ReadBytes != 4096 || saved.IO.ReadChars != 8192 {
+		t.Errorf("IO = %+v", saved.IO)
+	}
+	if !gotOpts.Phases || !gotOpts.PhaseFD || len(saved.Phases) != 1 || saved.Phases[0].Name != "compile" {
+		t.Errorf("Phases/PhaseFD = %v/%v, saved.Phases = %+v", gotOpts.Phases, gotOpts.PhaseFD, saved.Phases)
+	}
+	if saved.Sampling == nil || saved.Sampling.AvgParallelism != 1.5 || saved.Sampling.SeriesFile != seriesPath {
+		t.Errorf("Sampling = %+v", saved.Sampling)
+	}
+	data, err := os.ReadFile(seriesPath)
+	if err != nil || strings.Count(string(data), "\n") != 2 {
+		t.Errorf("série = %q (err %v), want 2 linhas", data, err)
+	}
+}
+
+func TestExecCommand_OutputArtifacts(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		exitCode int
+		wantFile bool
+	}{
+		{name: "Falha guarda a saída", exitCode: 2, wantFile: true},
+		{name: "Sucesso não guarda", exitCode: 0, wantFile: false},
+		{name: "Sucesso com keep-output", args: []string{"-keep-output"}, exitCode: 0, wantFile: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logPath := filepath.Join(t.TempDir(), "log.jsonl")
+			var saved metrics.BuildMetric
+			var gotOpts runner.Options
+			cmd := newTestExecCommand(t)
+			cmd.Runner = func(ctx context.Context, args []string, opts runner.Options) runner.Result {
+				gotOpts = opts
+				return runner.Result{DurationSec: 1, ExitCode: tt.exitCode, Output: []byte("cc: erro\r\n")}
+			}
+			cmd.MetricsSaver = func(m metrics.BuildMetric, filePath string) error { saved = m; return nil }
+			cmd.NewID = func() string { return "01TESTE" }
+
+			args := append([]string{"-log", logPath, "-output-tail", "8"}, tt.args...)
+			if err := cmd.Run(append(args, "make")); err != nil {
+				t.Fatalf("Run() erro: %v", err)
+			}
+			if gotOpts.OutputTail != 8*1024 {
+				t.Errorf("OutputTail = %d, want %d", gotOpts.OutputTail, 8*1024)
+			}
+			if !tt.wantFile {
+				if saved.OutputFile != "" {
+					t.Errorf("OutputFile = %q, want vazio", saved.OutputFile)
+				}
+				return
+			}
+			if saved.OutputFile != filepath.Join("artifacts", "01TESTE.log") {
+				t.Fatalf("OutputFile = %q", saved.OutputFile)
+			}
+			data, err := os.ReadFile(filepath.Join(filepath.Dir(logPath), saved.OutputFile))
+			if err != nil || string(data) != "cc: erro\n" {
+				t.Errorf("artefato = %q (err %v)", data, err)
+			}
+		})
+	}
+}
+
+func TestExecCommand_FailureClass(t *testing.T) {
+	tests := []struct {
+		name      string
+		exitCode  int
+		output    string
+		cfg       *config.Config
+		cfgErr    error
+		want      string
+		wantError string
+	}{
+		{name: "Sucesso sem classe", exitCode: 0, output: "main.c:1:1: error: x", want: ""},
+		{name: "Regra embutida", exitCode: 1, output: "main.c:1:1: error: x", want: failure.ClassCompilerError},
+		{
+			name:     "Regra do usuário",
+			exitCode: 1,
+			output:   "vpn down",
+			cfg:      &config.Config{FailureRules: []failure.Rule{{Class: "vpn", Pattern: "vpn down"}}},
+			want:     "vpn",
+		},
+		{
+			name:      "Regra inválida mantém as embutidas",
+			exitCode:  137,
+			cfg:       &config.Config{FailureRules: []failure.Rule{{Class: "x", Pattern: "(("}}},
+			want:      failure.ClassOOMKilled,
+			wantError: "failure_rules",
+		},
+		{
+			name:      "Configuração ilegível",
+			exitCode:  3,
+			cfgErr:    errors.New("configuração inválida"),
+			want:      failure.ClassUnknown,
+			wantError: "configuração inválida",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var saved metrics.BuildMetric
+			var stderr bytes.Buffer
+			cmd := newTestExecCommand(t)
+			cmd.Err = &stderr
+			cmd.Runner = func(ctx context.Context, args []string, opts runner.Options) runner.Result {
+				return runner.Result{DurationSec: 1, ExitCode: tt.exitCode, Output: []byte(tt.output)}
+			}
+			cmd.MetricsSaver = func(m metrics.BuildMetric, filePath string) error { saved = m; return nil }
+			cmd.Config = func() (*config.Config, error) {
+				if tt.cfg == nil && tt.cfgErr == nil {
+					return &config.Config{}, nil
+				}
+				return tt.cfg, tt.cfgErr
+			}
+			if err := cmd.Run([]string{"-log", filepath.Join(t.TempDir(), "log.jsonl"), "make"}); err != nil {
+				t.Fatalf("Run() erro: %v", err)
+			}
+			if saved.FailureClass != tt.want {
+				t.Errorf("FailureClass = %q, want %q", saved.FailureClass, tt.want)
+			}
+			if tt.wantError != "" && !strings.Contains(stderr.String(), tt.wantError) {
+				t.Errorf("stderr = %q, want %q", stderr.String(), tt.wantError)
+			}
+		})
+	}
+}
+
+func TestExecCommand_Probes(t *testing.T) {
+	// Cada leitura do ccache soma 3 acertos e 1 falha; a probe "broken" sempre falha
+	var calls int
+	probeExec := func(ctx context.Context, args []string) ([]byte, error) {
+		if args[0] == "broken" {
+			return nil, errors.New("exit status 2")
+		}
+		calls++
+		return []byte(fmt.Sprintf("direct_cache_hit\t%d\ncache_miss\t%d\ncache_size_kibibyte\t%d\n", 10+3*calls, 5+calls, 100*calls)), nil
+	}
+	cfg := &config.Config{Probes: []probe.Probe{
+		{Name: "ccache", Parser: "ccache"},
+		{Name: "broken", Command: []string{"broken"}, Parser: "kv"},
+	}}
+
+	for _, tt := range []struct {
+		name  string
+		args  []string
+		want  map[string]metrics.ProbeCounters
+		calls int
+	}{
+		{
+			name:  "Probes da configuração",
+			args:  []string{"make"},
+			want:  map[string]metrics.ProbeCounters{"ccache": {"hits": 3, "misses": 1, "cache_size_bytes": 100 * 1024}},
+			calls: 2,
+		},
+		{name: "Desativadas com -probes=false", args: []string{"-probes=false", "make"}, want: nil, calls: 0},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			calls = 0
+			var saved metrics.BuildMetric
+			var stderr bytes.Buffer
+			cmd := newTestExecCommand(t)
+			cmd.Err = &stderr
+			cmd.MetricsSaver = func(m metrics.BuildMetric, filePath string) error { saved = m; return nil }
+			cmd.Config = func() (*config.Config, error) { return cfg, nil }
+			cmd.ProbeExec = probeExec
+			args := append([]string{"-log", filepath.Join(t.TempDir(), "log.jsonl")}, tt.args...)
+			if err := cmd.Run(args); err != nil {
+				t.Fatalf("Run() erro: %v", err)
+			}
+			if !reflect.DeepEqual(saved.Probes, tt.want) {
+				t.Errorf("Probes = %v, want %v", saved.Probes, tt.want)
+			}
+			if calls != tt.calls {
+				t.Errorf("leituras do ccache = %d, want %d", calls, tt.calls)
+			}
+			if tt.calls > 0 && !strings.Contains(stderr.String(), "probe broken") {
+				t.Errorf("stderr = %q, want erro da probe broken", stderr.String())
+			}
+		})
+	}
+}
+
+func TestExecCommand_Toolchain(t *testing.T) {
+	versions := []toolchain.Version{
+		{Name: "gcc", Path: "/usr/bin/gcc", Version: "gcc (GCC) 13.2.0"},
+		{Name: "cmake", Path: "/usr/bin/cmake", Version: "cmake version 3.28.1"},
+	}
+	var gotTools []toolchain.Tool
+	var saved metrics.BuildMetric
+	var savedInfo metrics.ToolchainInfo
+	cmd := newTestExecCommand(t)
+	cmd.MetricsSaver = func(m metrics.BuildMetric, filePath string) error { saved = m; return nil }
+	cmd.ToolchainSaver = func(logPath string, info metrics.ToolchainInfo) error { savedInfo = info; return nil }
+	cmd.Config = func() (*config.Config, error) {
+		return &config.Config{Toolchain: []toolchain.Tool{{Name: "gcc", Command: []string{"gcc", "--version"}}}}, nil
+	}
+	cmd.Toolchain = func(tools []toolchain.Tool) ([]toolchain.Version, error) {
+		gotTools = tools
+		return versions, errors.New("cache de toolchain: somente leitura")
+	}
+	if err := cmd.Run([]string{"-log", filepath.Join(t.TempDir(), "log.jsonl"), "make"}); err != nil {
+		t.Fatalf("Run() erro: %v", err)
+	}
+	if len(gotTools) != 1 || gotTools[0].Name != "gcc" {
+		t.Errorf("ferramentas consultadas = %+v, want as da configuração", gotTools)
+	}
+	if want := toolchain.Fingerprint(versions); saved.Toolchain != want || savedInfo.Fingerprint != want {
+		t.Errorf("Toolchain = %q, registrada %q, want %q", saved.Toolchain, savedInfo.Fingerprint, want)
+	}
+	if savedInfo.Versions["cmake"] != "cmake version 3.28.1" || savedInfo.Hostname != "h" || savedInfo.FirstSeen != saved.Timestamp {
+		t.Errorf("ToolchainInfo = %+v", savedInfo)
+	}
+
+	saved = metrics.BuildMetric{}
+	if err := cmd.Run([]string{"-log", filepath.Join(t.TempDir(), "log.jsonl"), "-toolchain=false", "make"}); err != nil {
+		t.Fatalf("Run() erro: %v", err)
+	}
+	if saved.Toolchain != "" {
+		t.Errorf("Toolchain com -toolchain=false = %q, want vazio", saved.Toolchain)
+	}
+}
+
+func TestExecCommand_HostProfile(t *testing.T) {
+	profile := metrics.HostProfile{ID: "abc123def456", Hostname: "h", CPUModel: "i7", LogicalCores: 8}
+	var saved metrics.BuildMetric
+	var savedProfile metrics.HostProfile
+	var gotHostname, gotDir string
+	newCmd := func(saveErr error) *commands.ExecCommand {
+		cmd := newTestExecCommand(t)
+		cmd.MetricsSaver = func(m metrics.BuildMetric, filePath string) error { saved = m; return nil }
+		cmd.HostProfile = func(hostname, buildDir string) metrics.HostProfile {
+			gotHostname, gotDir = hostname, buildDir
+			return profile
+		}
+		cmd.HostSaver = func(logPath string, p metrics.HostProfile) error { savedProfile = p; return saveErr }
+		return cmd
+	}
+
+	if err := newCmd(nil).Run([]string{"-log", filepath.Join(t.TempDir(), "log.jsonl"), "make"}); err != nil {
+		t.Fatalf("Run() erro: %v", err)
+	}
+	wd, _ := os.Getwd()
+	if gotHostname != "h" || gotDir != wd {
+		t.Errorf("HostProfile(%q, %q), want (h, %q)", gotHostname, gotDir, wd)
+	}
+	if saved.HostProfile != profile.ID || savedProfile.FirstSeen != saved.Timestamp {
+		t.Errorf("host_profile = %q, FirstSeen = %q; want %q, %q", saved.HostProfile, savedProfile.FirstSeen, profile.ID, saved.Timestamp)
+	}
+
+	// Sem o perfil gravado, o registro não referencia um ID que não pode ser resolvido
+	if err := newCmd(errors.New("disco cheio")).Run([]string{"-log", filepath.Join(t.TempDir(), "log.jsonl"), "make"}); err != nil {
+		t.Fatalf("Run() erro: %v", err)
+	}
+	if saved.HostProfile != "" {
+		t.Errorf("host_profile = %q com erro ao gravar o perfil, want vazio", saved.HostProfile)
+	}
+}
+
+func TestExecCommand_Contention(t *testing.T) {
+	var saved metrics.BuildMetric
+	var events []string
+	cmd := newTestExecCommand(t)
+	cmd.Runner = func(ctx context.Context, args []string, opts runner.Options) runner.Result {
+		events = append(events, "run")
+		return runner.Result{DurationSec: 1}
+	}
+	cmd.MetricsSaver = func(m metrics.BuildMetric, filePath string) error { saved = m; return nil }
+	cmd.StartContention = func() func() *metrics.Contention {
+		events = append(events, "start")
+		return func() *metrics.Contention {
+			events = append(events, "finish")
+			return &metrics.Contention{LoadAvg1: 0.5, ConcurrentRuns: 1, PowerSource: "ac"}
+		}
+	}
+	if err := cmd.Run([]string{"-log", filepath.Join(t.TempDir(), "log.jsonl"), "make"}); err != nil {
+		t.Fatalf("Run() erro: %v", err)
+	}
+	if !reflect.DeepEqual(events, []string{"start", "run", "finish"}) {
+		t.Errorf("ordem = %v, want a medição em volta do comando", events)
+	}
+	if saved.Contention == nil || saved.Contention.ConcurrentRuns != 1 || !saved.Contended() {
+		t.Errorf("Contention = %+v, want 1 bmt run simultâneo", saved.Contention)
+	}
+}
+
+func TestExecCommand_Benchmark(t *testing.T) {
+	tests := []struct {
+		name          string
+		exitCodes     []int // Por execução do comando, incluindo o aquecimento
+		wantErr       bool
+		wantSaved     int
+		wantPrepares  int
+		wantInSummary string
+	}{
+		{name: "Todas com sucesso", exitCodes: []int{0, 0, 0, 0}, wantSaved: 3, wantPrepares: 4, wantInSummary: "3 execuções medidas, 1 de aquecimento"},
+		{name: "Falha interrompe", exitCodes: []int{0, 0, 2, 0}, wantErr: true, wantSaved: 2, wantPrepares: 3, wantInSummary: "1 execuções medidas, 1 de aquecimento"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var saved []metrics.BuildMetric
+			var stdout bytes.Buffer
+			runs, prepares, ids := 0, 0, 0
+			cmd := newTestExecCommand(t)
+			cmd.Out = &stdout
+			cmd.Runner = func(ctx context.Context, args []string, opts runner.Options) runner.Result {
+				code := tt.exitCodes[runs]
+				runs++
+				return runner.Result{DurationSec: float64(runs), ExitCode: code}
+			}
+			cmd.Prepare = func(ctx context.Context, command string) error {
+				if command != "make clean" {
+					t.Errorf("Prepare(%q), want make clean", command)
+				}
+				prepares++
+				return nil
+			}
+			cmd.NewID = func() string {
+				ids++
+				return fmt.Sprintf("ID%d", ids)
+			}
+			cmd.MetricsSaver = func(m metrics.BuildMetric, filePath string) error { saved = append(saved, m); return nil }
+			err := cmd.Run([]string{"-log", filepath.Join(t.TempDir(), "log.jsonl"), "-repeat", "3", "-warmup", "1", "-prepare", "make clean", "make"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Run() erro = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(saved) != tt.wantSaved {
+				t.Fatalf("%d métricas salvas, want %d (o aquecimento não é registrado)", len(saved), tt.wantSaved)
+			}
+			for i, m := range saved {
+				if m.BenchmarkID != "ID1" || m.Iteration != i+1 {
+					t.Errorf("métrica %d: benchmark %q iteração %d, want ID1 e %d", i, m.BenchmarkID, m.Iteration, i+1)
+				}
+			}
+			if prepares != tt.wantPrepares {
+				t.Errorf("Prepare chamado %d vezes, want %d", prepares, tt.wantPrepares)
+			}
+			if !strings.Contains(stdout.String(), tt.wantInSummary) {
+				t.Errorf("resumo sem %q:\n%s", tt.wantInSummary, stdout.String())
+			}
+		})
+	}
+}
+
+func TestExecCommand_BenchmarkPrepareError(t *testing.T) {
+	var saved int
+	cmd := newTestExecCommand(t)
+	cmd.Runner = func(ctx context.Context, args []string, opts runner.Options) runner.Result {
+		t.Error("o comando não deve rodar se -prepare falha")
+		return runner.Result{}
+	}
+	cmd.Prepare = func(ctx context.Context, command string) error { return errors.New("exit status 1") }
+	cmd.MetricsSaver = func(m metrics.BuildMetric, filePath string) error { saved++; return nil }
+	err := cmd.Run([]string{"-log", filepath.Join(t.TempDir(), "log.jsonl"), "-repeat", "2", "-prepare", "false", "make"})
+	if err == nil || !strings.Contains(err.Error(), "-prepare") {
+		t.Errorf("Run() erro = %v, want falha do -prepare", err)
+	}
+	if saved != 0 {
+		t.Errorf("%d métricas salvas, want 0", saved)
+	}
+}
+
+func TestExecCommand_Retries(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		exitCodes []int
+		wantErr   bool
+		wantSaved int
+	}{
+		{name: "Passa na segunda tentativa", args: []string{"-retries", "2"}, exitCodes: []int{1, 0, 0}, wantSaved: 2},
+		{name: "Esgota as tentativas", args: []string{"-retries", "2"}, exitCodes: []int{1, 1, 1, 1}, wantSaved: 3},
+		{name: "Código fora de retry-on", args: []string{"-retries", "2", "-retry-on", "2,3"}, exitCodes: []int{1, 0}, wantSaved: 1},
+		{name: "Código em retry-on", args: []string{"-retries", "2", "-retry-on", "2,3"}, exitCodes: []int{3, 0}, wantSaved: 2},
+		{name: "Retry-on inválido", args: []string{"-retries", "1", "-retry-on", "x"}, wantErr: true},
+		{name: "Retry-on sem retries", args: []string{"-retry-on", "1"}, wantErr: true},
+		{name: "Com repeat", args: []string{"-retries", "1", "-repeat", "3"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var saved []metrics.BuildMetric
+			runs := 0
+			ids := 0
+			cmd := newTestExecCommand(t)
+			cmd.Runner = func(ctx context.Context, args []string, opts runner.Options) runner.Result {
+				code := tt.exitCodes[runs]
+				runs++
+				return runner.Result{DurationSec: 1, ExitCode: code}
+			}
+			cmd.NewID = func() string {
+				ids++
+				return fmt.Sprintf("ID%d", ids)
+			}
+			cmd.MetricsSaver = func(m metrics.BuildMetric, filePath string) error { saved = append(saved, m); return nil }
+			args := append([]string{"-log", filepath.Join(t.TempDir(), "log.jsonl")}, tt.args...)
+			err := cmd.Run(append(args, "ctest"))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Run() erro = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(saved) != tt.wantSaved {
+				t.Fatalf("%d tentativas registradas, want %d", len(saved), tt.wantSaved)
+			}
+			for i, m := range saved {
+				if m.AttemptGroup != "ID1" || m.Attempt != i+1 {
+					t.Errorf("tentativa %d: grupo %q número %d, want ID1 e %d", i, m.AttemptGroup, m.Attempt, i+1)
+				}
+			}
+		})
+	}
+}
+
+func TestExecCommand_Estimate(t *testing.T) {
+	tests := []struct {
+		name         string
+		args         []string
+		interactive  bool
+		wantEstimate bool
+		wantETA      bool
+		wantStatus   string // Texto da linha de status aos 5s; vazio se não houver linha
+	}{
+		{name: "Terminal", interactive: true, wantEstimate: true, wantETA: true, wantStatus: "[bmt] 5.0 s de ~10.0 s (50%)"},
+		{name: "Sem terminal", interactive: false, wantEstimate: true, wantETA: true},
+		{name: "Sem eta", args: []string{"-eta=false"}, interactive: true, wantEstimate: true, wantStatus: "[bmt] 5.0 s"},
+		{name: "Sem eta nem status", args: []string{"-eta=false", "-status=false"}, interactive: true},
+		{name: "Sem captura da saída", args: []string{"-output-tail", "0"}, interactive: true, wantEstimate: true, wantETA: true},
+		{name: "Benchmark", args: []string{"-repeat", "2"}, interactive: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stderr bytes.Buffer
+			var status *runner.StatusLine
+			estimated := false
+			cmd := newTestExecCommand(t)
+			cmd.Err = &stderr
+			cmd.Runner = func(ctx context.Context, args []string, opts runner.Options) runner.Result {
+				status = opts.Status
+				return runner.Result{DurationSec: 20}
+			}
+			cmd.Estimate = func(logPath, project, fingerprint string) (metrics.Estimate, error) {
+				estimated = true
+				if project != "p" || fingerprint != (metrics.BuildMetric{Args: []string{"make"}}).CommandFingerprint() {
+					t.Errorf("Estimate(%q, %q), want o projeto e o comando da execução", project, fingerprint)
+				}
+				return metrics.Estimate{Runs: 5, Median: 10, P90: 12}, nil
+			}
+			cmd.Interactive = func() bool { return tt.interactive }
+			args := append([]string{"-log", filepath.Join(t.TempDir(), "log.jsonl")}, tt.args...)
+			if err := cmd.Run(append(args, "make")); err != nil {
+				t.Fatalf("Run() erro: %v", err)
+			}
+			if estimated != tt.wantEstimate {
+				t.Errorf("Estimate chamado = %v, want %v", estimated, tt.wantEstimate)
+			}
+			if got := strings.Contains(stderr.String(), "Duração esperada: 10.0 s (mediana), até 12.0 s (p90)"); got != tt.wantETA {
+				t.Errorf("duração esperada no stderr = %v, want %v:\n%s", got, tt.wantETA, stderr.String())
+			}
+			switch {
+			case tt.wantStatus == "" && status != nil:
+				t.Error("linha de status inesperada")
+			case tt.wantStatus != "" && status == nil:
+				t.Error("linha de status ausente")
+			case status != nil:
+				if got := status.Text(5 * time.Second); got != tt.wantStatus {
+					t.Errorf("status = %q, want %q", got, tt.wantStatus)
+				}
+				if got := status.Text(15 * time.Second); tt.wantETA && !strings.Contains(got, "acima do p90") {
+					t.Errorf("status após o p90 = %q", got)
+				}
+			}
+		})
+	}
+}
+
+func TestExecCommand_Notify(t *testing.T) {
+	tests := []struct {
+		name         string
+		args         []string
+		after        string // notify.after da configuração
+		duration     float64
+		exitCode     int
+		wantNotified bool
+		wantBackends []string
+	}{
+		{name: "Acima do limite da configuração", after: "1m", duration: 90, wantNotified: true},
+		{name: "Abaixo do limite", after: "1m", duration: 30},
+		{name: "Sem configuração", duration: 3600},
+		{name: "Flag sobrepõe a configuração", args: []string{"-notify-after", "10s"}, after: "1m", duration: 30, wantNotified: true},
+		{name: "Flag zero desativa", args: []string{"-notify-after", "0"}, after: "1m", duration: 90},
+		{name: "Backends pela flag", args: []string{"-notify-via", "osc9,bell"}, after: "1m", duration: 90, wantNotified: true, wantBackends: []string{"osc9", "bell"}},
+		{name: "Falha", after: "1m", duration: 90, exitCode: 2, wantNotified: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *notify.Message
+			var gotSettings notify.Settings
+			cmd := newTestExecCommand(t)
+			cmd.Runner = func(ctx context.Context, args []string, opts runner.Options) runner.Result {
+				return runner.Result{DurationSec: tt.duration, ExitCode: tt.exitCode}
+			}
+			cmd.Config = func() (*config.Config, error) {
+				return &config.Config{Notify: notify.Settings{After: tt.after}}, nil
+			}
+			cmd.Estimate = func(logPath, project, fingerprint string) (metrics.Estimate, error) {
+				return metrics.Estimate{Runs: 10, Median: 60, P90: 70}, nil
+			}
+			cmd.Notify = func(settings notify.Settings, m notify.Message) error {
+				got, gotSettings = &m, settings
+				return nil
+			}
+			args := append([]string{"-log", filepath.Join(t.TempDir(), "log.jsonl")}, tt.args...)
+			if err := cmd.Run(append(args, "make", "-j8")); err != nil {
+				t.Fatalf("Run() erro: %v", err)
+			}
+			if (got != nil) != tt.wantNotified {
+				t.Fatalf("notificado = %v, want %v", got != nil, tt.wantNotified)
+			}
+			if got == nil {
+				return
+			}
+			if got.DurationSec != tt.duration || got.MedianSec != 60 || got.Project != "p" || got.ExitCode != tt.exitCode {
+				t.Errorf("mensagem = %+v", got)
+			}
+			wantTitle := "bmt: make concluído"
+			if tt.exitCode != 0 {
+				wantTitle = "bmt: make falhou (código 2)"
+			}
+			if got.Title != wantTitle {
+				t.Errorf("título = %q, want %q", got.Title, wantTitle)
+			}
+			if tt.duration == 90 && !strings.Contains(got.Body, "50% acima da mediana de 1min00s") {
+				t.Errorf("corpo = %q, want comparação com a mediana", got.Body)
+			}
+			if !reflect.DeepEqual(gotSettings.Backends, tt.wantBackends) {
+				t.Errorf("backends = %v, want %v", gotSettings.Backends, tt.wantBackends)
+			}
+		})
+	}
+}
+
+func TestExecCommand_Webhooks(t *testing.T) {
+	var received []notify.Event
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var e notify.Event
+		json.NewDecoder(r.Body).Decode(&e)
+		received = append(received, e)
+	}))
+	defer srv.Close()
+	hooks := []notify.Webhook{{Name: "ci", URL: srv.URL, On: notify.Conditions{Failure: true, MedianFactor: 1.5}}}
+
+	tests := []struct {
+		name     string
+		duration float64
+		exitCode int
+		want     []string
+	}{
+		{name: "Dentro do normal", duration: 70},
+		{name: "Lento", duration: 120, want: []string{"2.0x a mediana (limite 1.5x)"}},
+		{name: "Falha", duration: 30, exitCode: 1, want: []string{"falhou com código 1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received = nil
+			cmd := newTestExecCommand(t)
+			cmd.Runner = func(ctx context.Context, args []string, opts runner.Options) runner.Result {
+				return runner.Result{DurationSec: tt.duration, ExitCode: tt.exitCode}
+			}
+			cmd.Config = func() (*config.Config, error) {
+				return &config.Config{Notify: notify.Settings{Webhooks: hooks}}, nil
+			}
+			cmd.Estimate = func(logPath, project, fingerprint string) (metrics.Estimate, error) {
+				return metrics.Estimate{Runs: 10, Median: 60, P90: 70}, nil
+			}
+			cmd.Notify = func(settings notify.Settings, m notify.Message) error {
+				t.Error("sem notify.after, a notificação local não deve ser enviada")
+				return nil
+			}
+			cmd.Webhooks = notify.NewWebhookSender("").Send
+			if err := cmd.Run([]string{"-log", filepath.Join(t.TempDir(), "log.jsonl"), "-eta=false", "make"}); err != nil {
+				t.Fatalf("Run() erro: %v", err)
+			}
+			if len(tt.want) == 0 {
+				if len(received) != 0 {
+					t.Errorf("webhooks recebidos = %+v, want nenhum", received)
+				}
+				return
+			}
+			if len(received) != 1 {
+				t.Fatalf("webhooks recebidos = %d, want 1", len(received))
+			}
+			e := received[0]
+			if !reflect.DeepEqual(e.Reasons, tt.want) || e.Branch != "main" || e.Commit != "1234567" || e.Hostname != "h" || e.MedianSec != 60 {
+				t.Errorf("evento = %+v", e)
+			}
+		})
+	}
+}
+
+func TestExecCommand_Nested(t *testing.T) {
+	tests := []struct {
+		name      string
+		env       map[string]string
+		wantDepth int
+	}{
+		{name: "Nível superior", env: map[string]string{}},
+		{name: "Dentro de outro bmt run", env: map[string]string{"BMT_PARENT_RUN_ID": "01PAI", "BMT_PARENT_DEPTH": "0"}, wantDepth: 1},
+		{name: "Dois níveis", env: map[string]string{"BMT_PARENT_RUN_ID": "01PAI", "BMT_PARENT_DEPTH": "1"}, wantDepth: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var saved metrics.BuildMetric
+			var gotOpts runner.Options
+			var notified, webhooks int
+			cmd := newTestExecCommand(t)
+			cmd.Runner = func(ctx context.Context, args []string, opts runner.Options) runner.Result {
+				gotOpts = opts
+				return runner.Result{DurationSec: 1}
+			}
+			cmd.Config = func() (*config.Config, error) {
+				return &config.Config{Notify: notify.Settings{After: "1s", Webhooks: []notify.Webhook{{Name: "ci", URL: "http://ci"}}}}, nil
+			}
+			cmd.Notify = func(notify.Settings, notify.Message) error { notified++; return nil }
+			cmd.Webhooks = func([]notify.Webhook, notify.Message) error { webhooks++; return nil }
+			cmd.Getenv = func(k string) string { return tt.env[k] }
+			cmd.NewID = func() string { return "01FILHO" }
+			cmd.Interactive = func() bool { return true }
+			cmd.MetricsSaver = func(m metrics.BuildMetric, filePath string) error { saved = m; return nil }
+			if err := cmd.Run([]string{"-log", filepath.Join(t.TempDir(), "log.jsonl"), "make"}); err != nil {
+				t.Fatalf("Run() erro: %v", err)
+			}
+			if saved.ParentID != tt.env["BMT_PARENT_RUN_ID"] || saved.Depth != tt.wantDepth {
+				t.Errorf("parent_id, depth = %q, %d; want %q, %d", saved.ParentID, saved.Depth, tt.env["BMT_PARENT_RUN_ID"], tt.wantDepth)
+			}
+			wantEnv := []string{"BMT_PARENT_RUN_ID=01FILHO", fmt.Sprintf("BMT_PARENT_DEPTH=%d", tt.wantDepth)}
+			if !reflect.DeepEqual(gotOpts.Env, wantEnv) {
+				t.Errorf("Env = %q, want %q", gotOpts.Env, wantEnv)
+			}
+			// A linha de status fica só com a execução de nível superior
+			if (gotOpts.Status != nil) != (tt.wantDepth == 0) {
+				t.Errorf("linha de status = %v no nível %d", gotOpts.Status != nil, tt.wantDepth)
+			}
+			// Assim como as notificações e os webhooks
+			if top := tt.wantDepth == 0; (notified == 1) != top || (webhooks == 1) != top {
+				t.Errorf("notificações, webhooks = %d, %d no nível %d", notified, webhooks, tt.wantDepth)
+			}
+		})
+	}
+}
+
+func TestExecCommand_NinjaTargets(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		wantDirs []string
+	}{
+		{name: "Detecta o -C do ninja", args: []string{"ninja", "-C", "out"}, wantDirs: []string{"out"}},
+		{name: "Diretório explícito", args: []string{"-ninja", "build", "./build.sh"}, wantDirs: []string{"build"}},
+		{name: "Desativado", args: []string{"-ninja", "off", "ninja"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var saved metrics.BuildMetric
+			var gotDirs []string
+			targets := []metrics.TargetDuration{{Name: "render.o", DurationSec: 12}}
+			cmd := newTestExecCommand(t)
+			cmd.Runner = func(ctx context.Context, args []string, opts runner.Options) runner.Result {
+				return runner.Result{DurationSec: 20}
+			}
+			cmd.WatchNinja = func(dirs []string, top int) func() ([]metrics.TargetDuration, error) {
+				gotDirs = dirs
+				if top != 3 {
+					t.Errorf("WatchNinja(top=%d), want 3", top)
+				}
+				return func() ([]metrics.TargetDuration, error) { return targets, nil }
+			}
+			cmd.MetricsSaver = func(m metrics.BuildMetric, filePath string) error { saved = m; return nil }
+			args := append([]string{"-log", filepath.Join(t.TempDir(), "log.jsonl"), "-top-targets", "3"}, tt.args...)
+			if err := cmd.Run(args); err != nil {
+				t.Fatalf("Run() erro: %v", err)
+			}
+			if !reflect.DeepEqual(gotDirs, tt.wantDirs) {
+				t.Errorf("diretórios = %q, want %q", gotDirs, tt.wantDirs)
+			}
+			if tt.wantDirs == nil {
+				targets = nil
+			}
+			if !reflect.DeepEqual(saved.Targets, targets) {
+				t.Errorf("Targets = %+v, want %+v", saved.Targets, targets)
+			}
+		})
+	}
 }
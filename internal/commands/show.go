@@ -0,0 +1,261 @@
+package commands
+
+import (
+	metrics "dev-metrics/internal/metrics"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+type ShowCommand struct {
+	Out io.Writer
+}
+
+func (c *ShowCommand) Name() string { return "show" }
+func (c *ShowCommand) Description() string {
+	return "Mostra os detalhes de uma execução e o final da saída guardada"
+}
+
+func (c *ShowCommand) Run(args []string) error {
+	c.ensureDefaults()
+	fs := flag.NewFlagSet("show", flag.ContinueOnError)
+	fs.SetOutput(c.Out)
+
+	logFlag := fs.String("log", "", "Caminho do arquivo de log")
+	treeFlag := fs.Bool("tree", false, "Mostra a árvore das execuções aninhadas (bmt run dentro de bmt run)")
+
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Uso: bmt show [--tree] <id> [-log path]\n")
+		fs.PrintDefaults()
+		fmt.Fprintf(fs.Output(), "%s", `O id pode ser abreviado, desde que identifique uma única execução.
+`)
+	}
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		return errors.New("informe o id de uma execução")
+	}
+	id := strings.ToUpper(positional[0]) // ULIDs são case-insensitive
+
+	logPath, err := metrics.GetLogFilePath(*logFlag)
+	if err != nil {
+		return fmt.Errorf("erro ao resolver log: %v", err)
+	}
+	f, err := os.Open(logPath)
+	if err != nil {
+		return fmt.Errorf("erro ao abrir log %s: %v", logPath, err)
+	}
+	defer f.Close()
+
+	found, err := metrics.FindByID(f, id)
+	if err != nil {
+		return fmt.Errorf("erro ao ler log: %v", err)
+	}
+	switch {
+	case len(found) == 0:
+		return fmt.Errorf("nenhuma execução com id %q em %s", positional[0], logPath)
+	case len(found) > 1:
+		fmt.Fprintf(c.Out, "O id %q é ambíguo:\n", positional[0])
+		for _, m := range found {
+			fmt.Fprintf(c.Out, "- %s\n", describeMetric(m))
+		}
+		return fmt.Errorf("%d execuções começam com %q", len(found), positional[0])
+	}
+
+	m := found[0]
+	if *treeFlag {
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("erro ao ler log: %v", err)
+		}
+		root, err := metrics.FindTree(f, m.ID)
+		if err != nil {
+			return fmt.Errorf("erro ao ler log: %v", err)
+		}
+		if m.ParentID != "" {
+			fmt.Fprintf(c.Out, "Aninhada em %s (bmt show --tree %s para subir um nível)\n", m.ParentID, m.ParentID)
+		}
+		printRunTree(c.Out, root)
+		return nil
+	}
+	var toolchain metrics.ToolchainInfo
+	if m.Toolchain != "" {
+		// Sem o registro de versões, mostra só a impressão digital
+		infos, _ := metrics.LoadToolchains(logPath)
+		toolchain = infos[m.Toolchain]
+	}
+	var profile *metrics.HostProfile
+	if m.HostProfile != "" {
+		if profiles, _ := metrics.LoadHostProfiles(logPath); profiles != nil {
+			if p, ok := profiles[m.HostProfile]; ok {
+				profile = &p
+			}
+		}
+	}
+	printMetricDetails(c.Out, m, toolchain.Versions, profile)
+	if m.OutputFile == "" {
+		if m.Kind == "" { // Registros e spans não têm saída
+			fmt.Fprintln(c.Out, "\nNenhuma saída guardada (use 'bmt run --keep-output' para guardar também execuções com sucesso).")
+		}
+		return nil
+	}
+
+	output, err := os.ReadFile(metrics.ResolveArtifact(logPath, m.OutputFile))
+	if err != nil {
+		return fmt.Errorf("erro ao ler saída guardada: %v", err)
+	}
+	fmt.Fprintf(c.Out, "\n------ final da saída (%s) ------\n", m.OutputFile)
+	c.Out.Write(output)
+	if len(output) > 0 && output[len(output)-1] != '\n' {
+		fmt.Fprintln(c.Out)
+	}
+	return nil
+}
+
+// printMetricDetails escreve os campos principais de um registro, um por linha.
+// versions e profile são a toolchain e o perfil da máquina do registro, se conhecidos.
+func printMetricDetails(w io.Writer, m metrics.BuildMetric, versions map[string]string, profile *metrics.HostProfile) {
+	row := func(label, format string, args ...any) {
+		if label != "" {
+			label += ":"
+		}
+		fmt.Fprintf(w, "%-12s %s\n", label, fmt.Sprintf(format, args...))
+	}
+	row("Execução", "%s", m.ID)
+	row("Data", "%s", m.Timestamp)
+	row("Projeto", "%s (%s @ %s)", m.Project, m.Branch, m.Commit)
+	switch m.Kind {
+	case metrics.KindRecord:
+		row("Registro", "%s (bmt record)", m.Command)
+	case metrics.KindSpan:
+		row("Span", "%s (bmt span)", m.Command)
+	case metrics.KindTarget:
+		row("Alvo", "%s (bmt make)", m.Command)
+	case metrics.KindNinja:
+		row("Build", "%s (importado com bmt ninja-log)", m.Command)
+	default:
+		row("Comando", "%s", m.Command)
+	}
+	if m.BenchmarkID != "" && m.Variant != "" {
+		row("Benchmark", "%s (comando %s, rodada %d de bmt compare)", m.BenchmarkID, m.Variant, m.Iteration)
+	} else if m.BenchmarkID != "" {
+		row("Benchmark", "%s (iteração %d)", m.BenchmarkID, m.Iteration)
+	}
+	if m.ParentID != "" {
+		row("Pai", "%s (nível %d)", m.ParentID, m.Depth)
+	}
+	row("Status", "%s (código %d)", m.Status, m.ReturnCode)
+	if m.AttemptGroup != "" {
+		row("Tentativa", "%d (grupo %s)", m.Attempt, m.AttemptGroup)
+	}
+	row("Duração", "%s", metrics.FormatDuration(m.DurationSec, metrics.DurationAuto, true))
+	if m.CPUSec > 0 {
+		row("CPU", "%s", metrics.FormatDuration(m.CPUSec, metrics.DurationAuto, true))
+	}
+	row("Máquina", "%s@%s (%s, %d CPUs)", m.User, m.Hostname, m.OS, m.CPUs)
+	if p := profile; p != nil {
+		row("", "%s, kernel %s, %s (perfil %s)", p.Label(), p.Kernel, p.FSType, p.ID)
+	}
+	if m.Toolchain != "" {
+		row("Toolchain", "%s", m.Toolchain)
+		tools := make([]string, 0, len(versions))
+		for tool := range versions {
+			tools = append(tools, tool)
+		}
+		sort.Strings(tools)
+		for _, tool := range tools {
+			row("", "%-16s %s", tool, versions[tool])
+		}
+	}
+	for i, t := range m.Tools {
+		label := ""
+		if i == 0 {
+			label = "Ferramentas"
+		}
+		row(label, "%-16s CPU %s (%d processos)", t.Name, metrics.FormatDuration(t.CPUSec, metrics.DurationAuto, true), t.Procs)
+	}
+	for i, p := range m.Phases {
+		label := ""
+		if i == 0 {
+			label = "Fases"
+		}
+		row(label, "%-16s %s (início em %s)", p.Name, metrics.FormatDuration(p.DurationSec, metrics.DurationAuto, true),
+			metrics.FormatDuration(p.StartSec, metrics.DurationAuto, true))
+	}
+	for i, t := range m.Targets {
+		label := ""
+		if i == 0 {
+			label = "Alvos"
+		}
+		row(label, "%-40s %s", t.Name, metrics.FormatDuration(t.DurationSec, metrics.DurationAuto, true))
+	}
+	if c := m.Contention; c != nil {
+		power := c.PowerSource
+		if power == "" {
+			power = "energia desconhecida"
+		}
+		row("Contenção", "carga %.2f, %d outro(s) bmt run, %s, governor %s, %d eventos de throttling",
+			c.LoadAvg1, c.ConcurrentRuns, power, c.Governor, c.ThrottleEvents)
+	}
+	if io := m.IO; io != nil {
+		row("I/O", "%d bytes lidos, %d bytes escritos", io.ReadBytes, io.WriteBytes)
+	}
+	names := make([]string, 0, len(m.Probes))
+	for name := range m.Probes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for i, name := range names {
+		label := ""
+		if i == 0 {
+			label = "Probes"
+		}
+		row(label, "%-16s %s", name, formatProbeCounters(m.Probes[name]))
+	}
+}
+
+// printRunTree escreve uma execução e as aninhadas nela, com a fração do tempo
+// da execução pai que cada uma ocupou.
+func printRunTree(w io.Writer, root *metrics.RunNode) {
+	line := func(prefix string, m metrics.BuildMetric, share string) {
+		fmt.Fprintf(w, "%s%s  %s%s  %s  %s\n", prefix, m.ID,
+			metrics.FormatDuration(m.DurationSec, metrics.DurationAuto, true), share, m.Status, m.Command)
+	}
+	var walk func(n *metrics.RunNode, indent string)
+	walk = func(n *metrics.RunNode, indent string) {
+		for i, child := range n.Children {
+			branch, next := "├─ ", "│  "
+			if i == len(n.Children)-1 {
+				branch, next = "└─ ", "   "
+			}
+			share := ""
+			if n.Metric.DurationSec > 0 {
+				share = fmt.Sprintf(" (%.0f%%)", child.Metric.DurationSec/n.Metric.DurationSec*100)
+			}
+			line(indent+branch, child.Metric, share)
+			walk(child, indent+next)
+		}
+	}
+	line("", root.Metric, "")
+	walk(root, "")
+}
+
+func (c *ShowCommand) ensureDefaults() {
+	if c.Out == nil {
+		c.Out = os.Stdout
+	}
+}
+
+func (c *ShowCommand) Aliases() []string {
+	return []string{}
+}
+
+func init() {
+	Register(&ShowCommand{})
+}
@@ -0,0 +1,109 @@
+package commands_test
+
+import (
+	"bytes"
+	"dev-metrics/internal/commands"
+	"dev-metrics/internal/metrics"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestShowCommand_Run(t *testing.T) {
+	logPath := writeTempLog(t, `{"id":"01HAAA","project":"backend","branch":"main","commit":"abc1234","timestamp":"2024-01-02T10:00:00Z","command":"[make]","status":"failure","returncode":2,"duration_sec":10,"output_file":"artifacts/01HAAA.log"}
+{"id":"01HAAB","project":"backend","timestamp":"2024-01-03T10:00:00Z","command":"[make test]","status":"success","duration_sec":5,"toolchain":"abc123","host_profile":"h1","contention":{"load_avg_1":1.5,"concurrent_runs":1,"power_source":"ac","governor":"performance"}}
+{"id":"01HBBB","project":"frontend","timestamp":"2024-01-04T10:00:00Z","command":"[npm ci]","status":"success","duration_sec":3,"probes":{"ccache":{"hits":3,"misses":1},"disk":{"used_kb":-20}}}
+{"id":"01HCCF","parent_id":"01HCCD","depth":2,"project":"backend","timestamp":"2024-01-05T10:04:00Z","command":"[./slow_test]","status":"success","duration_sec":60}
+{"id":"01HCCD","parent_id":"01HCCC","depth":1,"project":"backend","timestamp":"2024-01-05T10:05:00Z","command":"[ctest]","status":"success","duration_sec":240}
+{"id":"01HCCE","parent_id":"01HCCC","depth":1,"project":"backend","timestamp":"2024-01-05T10:09:00Z","command":"[./lint.sh]","status":"failure","duration_sec":120}
+{"id":"01HCCC","project":"backend","timestamp":"2024-01-05T10:10:00Z","command":"[make all]","status":"failure","duration_sec":600}
+`)
+	if err := metrics.SaveToolchain(logPath, metrics.ToolchainInfo{Fingerprint: "abc123", Versions: map[string]string{"gcc": "gcc (GCC) 13.2.0"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := metrics.SaveHostProfile(logPath, metrics.HostProfile{ID: "h1", CPUModel: "i7", PhysicalCores: 4, LogicalCores: 8, Kernel: "6.8", FSType: "ext4"}); err != nil {
+		t.Fatal(err)
+	}
+	artifacts := filepath.Join(filepath.Dir(logPath), "artifacts")
+	if err := os.MkdirAll(artifacts, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(artifacts, "01HAAA.log"), []byte("main.c:3: erro: falta ';'"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr bool
+		wantOut []string
+	}{
+		{
+			name:    "Execução com saída guardada",
+			args:    []string{"01haaa"},
+			wantOut: []string{"Execução:    01HAAA", "Status:      failure (código 2)", "backend (main @ abc1234)", "final da saída", "main.c:3: erro: falta ';'\n"},
+		},
+		{
+			name:    "Prefixo único",
+			args:    []string{"01HB"},
+			wantOut: []string{"Execução:    01HBBB", "Probes:      ccache           3 acertos, 1 falhas (75% de acerto)", "disk             used_kb -20", "Nenhuma saída guardada"},
+		},
+		{
+			name:    "Toolchain registrada",
+			args:    []string{"01HAAB"},
+			wantOut: []string{"Toolchain:   abc123\n", "gcc              gcc (GCC) 13.2.0", "i7 (4c/8t), kernel 6.8, ext4 (perfil h1)", "Contenção:   carga 1.50, 1 outro(s) bmt run, ac, governor performance, 0 eventos"},
+		},
+		{
+			name: "Árvore de execuções aninhadas",
+			args: []string{"--tree", "01HCCC"},
+			wantOut: []string{
+				"01HCCC  10min00s  failure  [make all]\n" +
+					"├─ 01HCCD  4min00s (40%)  success  [ctest]\n" +
+					"│  └─ 01HCCF  1min00s (25%)  success  [./slow_test]\n" +
+					"└─ 01HCCE  2min00s (20%)  failure  [./lint.sh]\n",
+			},
+		},
+		{
+			name:    "Subárvore",
+			args:    []string{"--tree", "01HCCD"},
+			wantOut: []string{"Aninhada em 01HCCC", "01HCCD  4min00s  success  [ctest]\n└─ 01HCCF"},
+		},
+		{
+			name:    "Execução aninhada",
+			args:    []string{"01HCCF"},
+			wantOut: []string{"Pai:         01HCCD (nível 2)"},
+		},
+		{
+			name:    "Prefixo ambíguo",
+			args:    []string{"01HAA"},
+			wantErr: true,
+			wantOut: []string{"é ambíguo", "01HAAA |", "01HAAB |"},
+		},
+		{
+			name:    "Id inexistente",
+			args:    []string{"01ZZZ"},
+			wantErr: true,
+		},
+		{
+			name:    "Sem id",
+			args:    []string{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			c := &commands.ShowCommand{Out: &out}
+			err := c.Run(append(tt.args, "-log", logPath))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Run() erro = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, want := range tt.wantOut {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("saída não contém %q:\n%s", want, out.String())
+				}
+			}
+		})
+	}
+}
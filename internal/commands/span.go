@@ -0,0 +1,192 @@
+package commands
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"path/filepath"
+	"time"
+
+	"dev-metrics/internal/git"
+	"dev-metrics/internal/metrics"
+	"dev-metrics/internal/span"
+)
+
+// SpanCommand mede intervalos nomeados que começam e terminam em comandos
+// separados, possivelmente em shells diferentes.
+type SpanCommand struct {
+	Out          io.Writer
+	GitInfo      func() (branch, commit, project string)
+	MetricsSaver func(m metrics.BuildMetric, filePath string) error
+	UserInfo     func() (*user.User, error)
+	Hostname     func() (string, error)
+	NewID        func() string
+	Now          func() time.Time
+	// StateDir retorna o diretório dos spans abertos
+	StateDir func() (string, error)
+}
+
+func (c *SpanCommand) Name() string { return "span" }
+func (c *SpanCommand) Description() string {
+	return "Mede um intervalo nomeado entre 'span start' e 'span stop'"
+}
+
+func (c *SpanCommand) Run(args []string) error {
+	c.ensureDefaults()
+	fs := flag.NewFlagSet("span", flag.ContinueOnError)
+	fs.SetOutput(c.Out)
+	logFlag := fs.String("log", "", "Caminho customizado para o arquivo de log (start guarda o log; stop pode sobrepô-lo)")
+	projectFlag := fs.String("project", "", "Projeto do span, no start (padrão: o do repositório atual)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Uso: bmt span start|stop|cancel <nome> [--project p] [-log path]\n       bmt span list\n")
+		fs.PrintDefaults()
+		fmt.Fprintf(fs.Output(), "%s", `Os spans abertos ficam em $XDG_STATE_HOME/bmt/spans (ou $BMT_STATE_DIR/spans)
+e valem para todos os shells, mesmo após reiniciar a máquina. Exemplo:
+  bmt span start "code review" && ... && bmt span stop "code review"
+`)
+	}
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) == 0 {
+		fs.Usage()
+		return errors.New("informe start, stop, cancel ou list")
+	}
+	dir, err := c.StateDir()
+	if err != nil {
+		return fmt.Errorf("erro ao resolver diretório de estado: %v", err)
+	}
+	store := span.Store{Dir: dir}
+
+	action, positional := positional[0], positional[1:]
+	if action == "list" {
+		return c.list(store)
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		return fmt.Errorf("informe o nome do span para %s", action)
+	}
+	name := positional[0]
+	switch action {
+	case "start":
+		return c.start(store, name, *projectFlag, *logFlag)
+	case "stop":
+		return c.stop(store, name, *logFlag)
+	case "cancel":
+		if err := store.Remove(name); err != nil {
+			return err
+		}
+		fmt.Fprintf(c.Out, "Span %s cancelado, sem registro.\n", name)
+		return nil
+	}
+	fs.Usage()
+	return fmt.Errorf("ação desconhecida: %q", action)
+}
+
+func (c *SpanCommand) start(store span.Store, name, project, logFlag string) error {
+	logPath, err := metrics.GetLogFilePath(logFlag)
+	if err != nil {
+		return fmt.Errorf("erro ao resolver caminho do log: %v", err)
+	}
+	// O stop pode rodar em outro diretório
+	if abs, err := filepath.Abs(logPath); err == nil {
+		logPath = abs
+	}
+	branch, commit, gitProject := c.GitInfo()
+	if project == "" {
+		project = gitProject
+	}
+	sp := span.Span{Name: name, Project: project, Branch: branch, Commit: commit, LogPath: logPath, Started: c.Now()}
+	if err := store.Start(sp); err != nil {
+		if errors.Is(err, span.ErrOpen) {
+			if open, getErr := store.Get(name); getErr == nil {
+				return fmt.Errorf("%v (desde %s; use 'bmt span stop' ou 'bmt span cancel')", err, open.Started.Format("2006-01-02 15:04"))
+			}
+		}
+		return err
+	}
+	fmt.Fprintf(c.Out, "Span %s aberto em %s.\n", name, project)
+	return nil
+}
+
+func (c *SpanCommand) stop(store span.Store, name, logFlag string) error {
+	sp, err := store.Get(name)
+	if err != nil {
+		return err
+	}
+	logPath := sp.LogPath
+	if logFlag != "" {
+		logPath = logFlag
+	}
+	end := c.Now()
+	duration := max(end.Sub(sp.Started).Seconds(), 0) // Relógio ajustado para trás
+
+	hostname, _ := c.Hostname()
+	m := manualMetric(metrics.KindSpan, sp.Name, end, duration)
+	m.ID, m.Project, m.Branch, m.Commit = c.NewID(), sp.Project, sp.Branch, sp.Commit
+	m.User, m.Hostname = currentUsername(c.UserInfo), hostname
+	if err := c.MetricsSaver(m, logPath); err != nil {
+		return fmt.Errorf("erro ao gravar span (continua aberto): %v", err)
+	}
+	if err := store.Remove(name); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.Out, "Span %s: %s [%s]\n", name, metrics.FormatDuration(duration, metrics.DurationAuto, true), m.ID)
+	return nil
+}
+
+func (c *SpanCommand) list(store span.Store) error {
+	spans, err := store.List()
+	if err != nil {
+		return err
+	}
+	if len(spans) == 0 {
+		fmt.Fprintln(c.Out, "Nenhum span aberto.")
+		return nil
+	}
+	now := c.Now()
+	for _, sp := range spans {
+		fmt.Fprintf(c.Out, "%-24s %-16s desde %s (%s)\n", sp.Name, sp.Project, sp.Started.Format("2006-01-02 15:04"),
+			metrics.FormatDuration(now.Sub(sp.Started).Seconds(), metrics.DurationAuto, true))
+	}
+	return nil
+}
+
+func (c *SpanCommand) Aliases() []string {
+	return []string{}
+}
+
+func (c *SpanCommand) ensureDefaults() {
+	if c.Out == nil {
+		c.Out = os.Stdout
+	}
+	if c.GitInfo == nil {
+		c.GitInfo = git.GetInfo
+	}
+	if c.MetricsSaver == nil {
+		c.MetricsSaver = metrics.Save
+	}
+	if c.UserInfo == nil {
+		c.UserInfo = user.Current
+	}
+	if c.Hostname == nil {
+		c.Hostname = os.Hostname
+	}
+	if c.NewID == nil {
+		c.NewID = metrics.NewID
+	}
+	if c.Now == nil {
+		c.Now = time.Now
+	}
+	if c.StateDir == nil {
+		c.StateDir = span.DefaultDir
+	}
+}
+
+func init() {
+	Register(&SpanCommand{})
+}
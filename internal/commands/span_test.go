@@ -0,0 +1,79 @@
+package commands_test
+
+import (
+	"bytes"
+	"dev-metrics/internal/commands"
+	"dev-metrics/internal/metrics"
+	"os/user"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSpanCommand_Run(t *testing.T) {
+	stateDir := t.TempDir()
+	logPath := t.TempDir() + "/log.jsonl"
+	now := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	var saved []metrics.BuildMetric
+	var savedPaths []string
+	// Cada chamada usa um comando novo, como shells diferentes
+	run := func(project string, args ...string) (string, error) {
+		var out bytes.Buffer
+		cmd := &commands.SpanCommand{
+			Out:     &out,
+			GitInfo: func() (string, string, string) { return "main", "1234567", project },
+			MetricsSaver: func(m metrics.BuildMetric, filePath string) error {
+				saved, savedPaths = append(saved, m), append(savedPaths, filePath)
+				return nil
+			},
+			UserInfo: func() (*user.User, error) { return &user.User{Username: "u"}, nil },
+			Hostname: func() (string, error) { return "h", nil },
+			NewID:    func() string { return "01SPAN" },
+			Now:      func() time.Time { return now },
+			StateDir: func() (string, error) { return stateDir, nil },
+		}
+		err := cmd.Run(args)
+		return out.String(), err
+	}
+
+	if _, err := run("backend", "start", "code review", "-log", logPath); err != nil {
+		t.Fatalf("start erro: %v", err)
+	}
+	if _, err := run("backend", "start", "code review"); err == nil || !strings.Contains(err.Error(), "já está aberto") {
+		t.Errorf("start repetido erro = %v, want span já aberto", err)
+	}
+	if _, err := run("backend", "start", "ci", "--project", "infra"); err != nil {
+		t.Fatalf("start erro: %v", err)
+	}
+
+	now = now.Add(25 * time.Minute)
+	out, err := run("backend", "list")
+	if err != nil || !strings.Contains(out, "code review") || !strings.Contains(out, "infra") || !strings.Contains(out, "25min") {
+		t.Errorf("list = %q, %v", out, err)
+	}
+
+	// O stop roda em outro repositório, mas o registro fica com o projeto do start
+	if _, err := run("frontend", "stop", "code review"); err != nil {
+		t.Fatalf("stop erro: %v", err)
+	}
+	if len(saved) != 1 {
+		t.Fatalf("registros = %d, want 1", len(saved))
+	}
+	m := saved[0]
+	if m.Kind != metrics.KindSpan || m.Command != "code review" || m.Project != "backend" || m.DurationSec != 1500 || savedPaths[0] != logPath {
+		t.Errorf("registro = %+v em %s", m, savedPaths[0])
+	}
+	if _, err := run("backend", "stop", "code review"); err == nil || !strings.Contains(err.Error(), "não está aberto") {
+		t.Errorf("stop repetido erro = %v, want span não aberto", err)
+	}
+
+	if _, err := run("backend", "cancel", "ci"); err != nil {
+		t.Fatalf("cancel erro: %v", err)
+	}
+	if out, _ := run("backend", "list"); !strings.Contains(out, "Nenhum span aberto") || len(saved) != 1 {
+		t.Errorf("após cancel: list = %q, %d registros", out, len(saved))
+	}
+	if _, err := run("backend", "pause", "x"); err == nil {
+		t.Error("ação desconhecida deveria falhar")
+	}
+}
@@ -0,0 +1,68 @@
+// Package config carrega a configuração do usuário do bmt (um arquivo JSON opcional).
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"dev-metrics/internal/failure"
+	"dev-metrics/internal/notify"
+	"dev-metrics/internal/probe"
+	"dev-metrics/internal/toolchain"
+)
+
+const EnvConfigPath = "BMT_CONFIG"
+
+// Usando monkey patching simples para facilitar testes
+var EnvGetter = os.Getenv
+var UserConfigDir = os.UserConfigDir
+
+// Config é o conteúdo de config.json. Campos ausentes usam os padrões de cada comando.
+type Config struct {
+	FailureRules []failure.Rule   `json:"failure_rules,omitempty"` // Avaliadas antes das regras embutidas
+	Probes       []probe.Probe    `json:"probes,omitempty"`        // Executadas antes e depois de cada bmt run
+	Toolchain    []toolchain.Tool `json:"toolchain,omitempty"`     // Ferramentas da impressão digital; vazio usa toolchain.DefaultTools
+	Notify       notify.Settings  `json:"notify"`                  // Padrões de bmt run --notify-after
+}
+
+// Path retorna o caminho do arquivo de configuração:
+// 1. Variável de ambiente BMT_CONFIG
+// 2. $XDG_CONFIG_HOME/bmt/config.json (ou ~/.config/bmt/config.json)
+func Path() (string, error) {
+	if p := EnvGetter(EnvConfigPath); p != "" {
+		return p, nil
+	}
+	dir, err := UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "bmt", "config.json"), nil
+}
+
+// Load lê a configuração do caminho padrão. A ausência do arquivo não é erro.
+func Load() (*Config, error) {
+	path, err := Path()
+	if err != nil {
+		return &Config{}, nil
+	}
+	return LoadFile(path)
+}
+
+// LoadFile lê a configuração de path. A ausência do arquivo não é erro.
+func LoadFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	cfg := &Config{}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("configuração inválida em %s: %v", path, err)
+	}
+	return cfg, nil
+}
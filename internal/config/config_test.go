@@ -0,0 +1,70 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPath(t *testing.T) {
+	origEnv, origDir := EnvGetter, UserConfigDir
+	defer func() { EnvGetter, UserConfigDir = origEnv, origDir }()
+
+	UserConfigDir = func() (string, error) { return "/home/u/.config", nil }
+	EnvGetter = func(string) string { return "" }
+	if got, _ := Path(); got != filepath.Join("/home/u/.config", "bmt", "config.json") {
+		t.Errorf("Path() = %q", got)
+	}
+
+	EnvGetter = func(k string) string {
+		if k == EnvConfigPath {
+			return "/etc/bmt.json"
+		}
+		return ""
+	}
+	if got, _ := Path(); got != "/etc/bmt.json" {
+		t.Errorf("Path() com %s = %q", EnvConfigPath, got)
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+
+	cfg, err := LoadFile(filepath.Join(dir, "nao-existe.json"))
+	if err != nil || cfg == nil || len(cfg.FailureRules) != 0 {
+		t.Errorf("LoadFile(ausente) = %+v, %v; want configuração vazia", cfg, err)
+	}
+
+	path := filepath.Join(dir, "config.json")
+	os.WriteFile(path, []byte(`{"failure_rules": [{"class": "timeout", "exit_code": 124}, {"class": "vpn", "pattern": "vpn down"}]}`), 0644)
+	cfg, err = LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() erro: %v", err)
+	}
+	if len(cfg.FailureRules) != 2 || cfg.FailureRules[0].ExitCode != 124 || cfg.FailureRules[1].Pattern != "vpn down" {
+		t.Errorf("FailureRules = %+v", cfg.FailureRules)
+	}
+
+	os.WriteFile(path, []byte(`{"probes": [{"name": "ccache", "parser": "ccache"}, {"name": "disk", "command": ["df-stats"], "parser": "kv"}]}`), 0644)
+	cfg, err = LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() erro: %v", err)
+	}
+	if len(cfg.Probes) != 2 || cfg.Probes[0].Parser != "ccache" || cfg.Probes[1].Command[0] != "df-stats" {
+		t.Errorf("Probes = %+v", cfg.Probes)
+	}
+
+	os.WriteFile(path, []byte(`{"notify": {"after": "2m", "backends": ["desktop", "bell"], "command": ["ntfy", "publish"]}}`), 0644)
+	cfg, err = LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() erro: %v", err)
+	}
+	if cfg.Notify.After != "2m" || len(cfg.Notify.Backends) != 2 || cfg.Notify.Command[0] != "ntfy" {
+		t.Errorf("Notify = %+v", cfg.Notify)
+	}
+
+	os.WriteFile(path, []byte(`{"failure_rules": `), 0644)
+	if _, err := LoadFile(path); err == nil {
+		t.Error("LoadFile() com JSON inválido deveria falhar")
+	}
+}
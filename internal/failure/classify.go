@@ -0,0 +1,97 @@
+// Package failure classifica execuções que falharam a partir do código de
+// saída e do final da saída do comando.
+package failure
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Classes embutidas. Regras do usuário podem usar qualquer outro nome.
+const (
+	ClassOOMKilled     = "oom_killed"
+	ClassDiskFull      = "disk_full"
+	ClassNetwork       = "network"
+	ClassLinkerError   = "linker_error"
+	ClassCompilerError = "compiler_error"
+	ClassTestFailure   = "test_failure"
+	ClassLint          = "lint"
+	ClassUnknown       = "unknown" // falhou, mas nenhuma regra casou
+)
+
+// Rule associa um padrão da saída e/ou um código de saída a uma classe de falha.
+// Se ambos forem informados, os dois precisam casar.
+type Rule struct {
+	Class    string `json:"class"`
+	Pattern  string `json:"pattern,omitempty"`   // Expressão regular (sintaxe RE2) aplicada à saída
+	ExitCode int    `json:"exit_code,omitempty"` // Zero ignora o código de saída
+}
+
+// builtinRules em ordem de prioridade: causas de ambiente (memória, disco,
+// rede) antes das de código, e o linker antes do compilador, já que o driver do
+// compilador também reporta os erros do linker.
+var builtinRules = []Rule{
+	{Class: ClassOOMKilled, ExitCode: 137},
+	{Class: ClassOOMKilled, Pattern: `(?i)out of memory|cannot allocate memory|virtual memory exhausted|Killed signal terminated program|OutOfMemoryError|heap out of memory`},
+	{Class: ClassDiskFull, Pattern: `(?i)no space left on device|disk quota exceeded|ENOSPC`},
+	{Class: ClassNetwork, Pattern: `(?i)could not resolve host|temporary failure in name resolution|connection (refused|reset|timed out)|network is unreachable|TLS handshake timeout|failed to (download|fetch)|i/o timeout|ETIMEDOUT|ECONNRESET|EAI_AGAIN`},
+	{Class: ClassLinkerError, Pattern: `undefined reference to|ld returned \d+ exit status|collect2: error|symbol\(s\) not found|unresolved external symbol|multiple definition of|cannot find -l\w+`},
+	{Class: ClassCompilerError, Pattern: `(?m):\d+:(\d+:)? (fatal )?error:|error\[E\d{4}\]|error CS\d{4}|error TS\d+|\.go:\d+:\d+: |SyntaxError:|\[ERROR\] COMPILATION ERROR`},
+	{Class: ClassTestFailure, Pattern: `(?m)^--- FAIL:|^FAIL\s|^FAILED |Tests? failed|\d+ (tests? )?failed|FAILURES!|AssertionError|Tests run: \d+, Failures: [1-9]`},
+	{Class: ClassLint, Pattern: `(?i)\b(golangci-lint|eslint|flake8|pylint|ruff|clippy|shellcheck|stylelint|rubocop)\b|✖ \d+ problems?|would reformat`},
+}
+
+// BuiltinRules retorna uma cópia das regras embutidas.
+func BuiltinRules() []Rule {
+	return append([]Rule(nil), builtinRules...)
+}
+
+type compiledRule struct {
+	Rule
+	re *regexp.Regexp
+}
+
+// Classifier aplica as regras em ordem; a primeira que casar define a classe.
+type Classifier struct {
+	rules []compiledRule
+}
+
+// NewClassifier compila as regras do usuário, que têm prioridade sobre as embutidas.
+func NewClassifier(userRules []Rule) (*Classifier, error) {
+	c := &Classifier{}
+	for i, r := range append(append([]Rule(nil), userRules...), builtinRules...) {
+		if r.Class == "" {
+			return nil, fmt.Errorf("regra %d: class é obrigatório", i+1)
+		}
+		if r.Pattern == "" && r.ExitCode == 0 {
+			return nil, fmt.Errorf("regra %d (%s): informe pattern e/ou exit_code", i+1, r.Class)
+		}
+		cr := compiledRule{Rule: r}
+		if r.Pattern != "" {
+			re, err := regexp.Compile(r.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("regra %d (%s): %v", i+1, r.Class, err)
+			}
+			cr.re = re
+		}
+		c.rules = append(c.rules, cr)
+	}
+	return c, nil
+}
+
+// Classify retorna a classe da falha. Execuções com sucesso (exitCode 0) não têm classe.
+func (c *Classifier) Classify(exitCode int, output []byte) string {
+	if exitCode == 0 {
+		return ""
+	}
+	for _, r := range c.rules {
+		if r.ExitCode != 0 && r.ExitCode != exitCode {
+			continue
+		}
+		if r.re != nil && !r.re.Match(output) {
+			continue
+		}
+		return r.Class
+	}
+	return ClassUnknown
+}
@@ -0,0 +1,77 @@
+package failure
+
+import "testing"
+
+func TestClassifier_Builtin(t *testing.T) {
+	c, err := NewClassifier(nil)
+	if err != nil {
+		t.Fatalf("NewClassifier() erro: %v", err)
+	}
+	tests := []struct {
+		name     string
+		exitCode int
+		output   string
+		want     string
+	}{
+		{"sucesso", 0, "main.c:1:1: error: x", ""},
+		{"gcc", 2, "src/main.cpp:12:5: error: 'foo' was not declared in this scope\nmake: *** [all] Error 1", ClassCompilerError},
+		{"rustc", 101, "error[E0425]: cannot find value `x` in this scope", ClassCompilerError},
+		{"go build", 1, "./main.go:10:2: undefined: foo", ClassCompilerError},
+		{"linker antes do compilador", 1, "main.o: undefined reference to `bar'\ncollect2: error: ld returned 1 exit status", ClassLinkerError},
+		{"go test", 1, "--- FAIL: TestSoma (0.00s)\nFAIL\tpkg/soma\t0.01s", ClassTestFailure},
+		{"pytest", 1, "===== 2 failed, 10 passed in 1.2s =====", ClassTestFailure},
+		{"oom por código de saída", 137, "", ClassOOMKilled},
+		{"oom do gcc", 1, "c++: fatal error: Killed signal terminated program cc1plus", ClassOOMKilled},
+		{"disco cheio", 1, "cp: error writing 'out.bin': No space left on device", ClassDiskFull},
+		{"rede", 1, "fatal: unable to access 'https://x/': Could not resolve host: x", ClassNetwork},
+		{"lint", 1, "✖ 3 problems (3 errors, 0 warnings)", ClassLint},
+		{"desconhecida", 3, "algo deu errado", ClassUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.Classify(tt.exitCode, []byte(tt.output)); got != tt.want {
+				t.Errorf("Classify(%d, %q) = %q, want %q", tt.exitCode, tt.output, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassifier_UserRules(t *testing.T) {
+	c, err := NewClassifier([]Rule{
+		{Class: "runner_offline", Pattern: `runner .* is offline`},
+		{Class: "timeout", ExitCode: 124},
+		{Class: "flaky_db", Pattern: `deadlock detected`, ExitCode: 1},
+	})
+	if err != nil {
+		t.Fatalf("NewClassifier() erro: %v", err)
+	}
+	tests := []struct {
+		exitCode int
+		output   string
+		want     string
+	}{
+		// Regras do usuário vêm antes das embutidas.
+		{1, "runner ci-3 is offline: connection refused", "runner_offline"},
+		{124, "", "timeout"},
+		{1, "ERROR: deadlock detected", "flaky_db"},
+		{2, "ERROR: deadlock detected", ClassUnknown}, // exit_code também precisa casar
+		{1, "connection refused", ClassNetwork},
+	}
+	for _, tt := range tests {
+		if got := c.Classify(tt.exitCode, []byte(tt.output)); got != tt.want {
+			t.Errorf("Classify(%d, %q) = %q, want %q", tt.exitCode, tt.output, got, tt.want)
+		}
+	}
+}
+
+func TestNewClassifier_InvalidRules(t *testing.T) {
+	for _, rules := range [][]Rule{
+		{{Pattern: "x"}},
+		{{Class: "vazia"}},
+		{{Class: "regex", Pattern: "(("}},
+	} {
+		if _, err := NewClassifier(rules); err == nil {
+			t.Errorf("NewClassifier(%+v) deveria falhar", rules)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package host
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"dev-metrics/internal/metrics"
+)
+
+// RunSubcommands são os nomes com que o bmt mede um comando (run e seus
+// aliases, make, compare); processos bmt com outros subcomandos (report,
+// show...) não contam como builds simultâneos.
+var RunSubcommands = []string{"run", "exec", "r", "make", "compare"}
+
+// ContentionSampler mede a contenção entre o início e o fim de uma execução.
+type ContentionSampler struct {
+	selfPID       int
+	start         metrics.Contention
+	throttleStart uint64
+}
+
+// StartContention registra a carga, a energia e os contadores de throttling
+// no início de uma execução. selfPID é ignorado na contagem de bmt run.
+func StartContention(selfPID int) *ContentionSampler {
+	s := &ContentionSampler{selfPID: selfPID, throttleStart: throttleCount()}
+	s.start.LoadAvg1 = loadAvg1()
+	s.start.ConcurrentRuns = countRuns(selfPID)
+	s.start.PowerSource = powerSource()
+	s.start.Governor = readTrimmed(filepath.Join(SysRoot, "devices/system/cpu/cpu0/cpufreq/scaling_governor"))
+	return s
+}
+
+// Finish completa a medição: builds iniciados durante a execução ainda ativos
+// no fim também contam, e o throttling é a diferença dos contadores.
+func (s *ContentionSampler) Finish() *metrics.Contention {
+	c := s.start
+	if n := countRuns(s.selfPID); n > c.ConcurrentRuns {
+		c.ConcurrentRuns = n
+	}
+	if end := throttleCount(); end > s.throttleStart {
+		c.ThrottleEvents = end - s.throttleStart
+	}
+	return &c
+}
+
+// loadAvg1 lê o load average de 1 minuto de /proc/loadavg.
+func loadAvg1() float64 {
+	fields := strings.Fields(readTrimmed(filepath.Join(ProcRoot, "loadavg")))
+	if len(fields) == 0 {
+		return 0
+	}
+	v, _ := strconv.ParseFloat(fields[0], 64)
+	return v
+}
+
+// countRuns conta os processos bmt medindo um comando, exceto selfPID e seus
+// ancestrais: um bmt run dentro de outro (ex.: um script medido que mede seus
+// passos) não é contenção.
+func countRuns(selfPID int) int {
+	entries, err := os.ReadDir(ProcRoot)
+	if err != nil {
+		return 0
+	}
+	self := filepath.Base(os.Args[0])
+	ancestors := ancestorPIDs(selfPID)
+	n := 0
+	for _, e := range entries {
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil || pid == selfPID || ancestors[pid] {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(ProcRoot, e.Name(), "cmdline"))
+		if err != nil {
+			continue
+		}
+		args := strings.Split(strings.TrimRight(string(data), "\x00"), "\x00")
+		if len(args) < 2 {
+			continue
+		}
+		name := filepath.Base(args[0])
+		if name != "bmt" && name != self {
+			continue
+		}
+		for _, sub := range RunSubcommands {
+			if args[1] == sub {
+				n++
+				break
+			}
+		}
+	}
+	return n
+}
+
+// ancestorPIDs retorna os ancestrais de pid segundo /proc/<pid>/stat.
+func ancestorPIDs(pid int) map[int]bool {
+	ancestors := make(map[int]bool)
+	for pid > 1 && !ancestors[pid] {
+		data, err := os.ReadFile(filepath.Join(ProcRoot, strconv.Itoa(pid), "stat"))
+		if err != nil {
+			break
+		}
+		// O campo 4 (ppid) vem depois do nome entre parênteses, que pode conter espaços
+		i := strings.LastIndexByte(string(data), ')')
+		if i < 0 {
+			break
+		}
+		fields := strings.Fields(string(data[i+1:]))
+		if len(fields) < 2 {
+			break
+		}
+		ppid, err := strconv.Atoi(fields[1])
+		if err != nil {
+			break
+		}
+		ancestors[ppid] = true
+		pid = ppid
+	}
+	return ancestors
+}
+
+// powerSource informa "ac" se alguma fonte externa está conectada, "battery"
+// se há bateria e nenhuma fonte conectada, e "" se a máquina não informa
+// (desktops sem power_supply).
+func powerSource() string {
+	dir := filepath.Join(SysRoot, "class/power_supply")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	hasBattery := false
+	for _, e := range entries {
+		supply := filepath.Join(dir, e.Name())
+		switch readTrimmed(filepath.Join(supply, "type")) {
+		case "Mains", "USB":
+			if readTrimmed(filepath.Join(supply, "online")) == "1" {
+				return "ac"
+			}
+		case "Battery":
+			// Baterias de periféricos (mouse, fone) têm scope Device
+			if readTrimmed(filepath.Join(supply, "scope")) != "Device" {
+				hasBattery = true
+			}
+		}
+	}
+	if hasBattery {
+		return "battery"
+	}
+	return ""
+}
+
+// throttleCount soma os contadores de throttling térmico de todas as CPUs
+// (core e package; disponíveis em x86 com o driver therm_throt).
+func throttleCount() uint64 {
+	matches, _ := filepath.Glob(filepath.Join(SysRoot, "devices/system/cpu/cpu[0-9]*/thermal_throttle/*_throttle_count"))
+	var total uint64
+	for _, m := range matches {
+		if v, err := readUint(m); err == nil {
+			total += v
+		}
+	}
+	return total
+}
+
+func readTrimmed(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,86 @@
+package host
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"dev-metrics/internal/metrics"
+)
+
+// writeSys cria arquivos de um sysfs falso em root; files mapeia caminho -> conteúdo.
+func writeSys(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for path, content := range files {
+		full := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestContention(t *testing.T) {
+	withFixtures(t)
+	SysRoot = t.TempDir()
+	writeSys(t, SysRoot, map[string]string{
+		"class/power_supply/AC/type":                                      "Mains",
+		"class/power_supply/AC/online":                                    "0",
+		"class/power_supply/BAT0/type":                                    "Battery",
+		"class/power_supply/hidpp_battery_0/type":                         "Battery",
+		"class/power_supply/hidpp_battery_0/scope":                        "Device",
+		"devices/system/cpu/cpu0/cpufreq/scaling_governor":                "powersave",
+		"devices/system/cpu/cpu0/thermal_throttle/core_throttle_count":    "5",
+		"devices/system/cpu/cpu0/thermal_throttle/package_throttle_count": "2",
+		"devices/system/cpu/cpu1/thermal_throttle/core_throttle_count":    "1",
+	})
+
+	s := StartContention(202)
+	writeSys(t, SysRoot, map[string]string{
+		"devices/system/cpu/cpu1/thermal_throttle/core_throttle_count": "4",
+	})
+	got := s.Finish()
+
+	// 200, 204, 206 (bmt make) e 207 (bmt compare) medem outros comandos;
+	// 203 é o pai de 202 e 201 é um bmt report
+	want := metrics.Contention{LoadAvg1: 2.4, ConcurrentRuns: 4, PowerSource: "battery", Governor: "powersave", ThrottleEvents: 3}
+	if *got != want {
+		t.Errorf("Finish() = %+v, want %+v", *got, want)
+	}
+}
+
+func TestPowerSource(t *testing.T) {
+	withFixtures(t)
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{
+			name: "Na tomada",
+			files: map[string]string{
+				"class/power_supply/AC/type":   "Mains",
+				"class/power_supply/AC/online": "1",
+				"class/power_supply/BAT0/type": "Battery",
+			},
+			want: "ac",
+		},
+		{
+			name:  "Desktop só com bateria de periférico",
+			files: map[string]string{"class/power_supply/mouse/type": "Battery", "class/power_supply/mouse/scope": "Device"},
+			want:  "",
+		},
+		{name: "Sem power_supply", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SysRoot = t.TempDir()
+			writeSys(t, SysRoot, tt.files)
+			if got := powerSource(); got != tt.want {
+				t.Errorf("powerSource() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+// Package host coleta, a partir de /proc e /sys, o perfil da máquina (CPU,
+// memória, kernel e sistema de arquivos) e a contenção durante uma execução
+// (carga, builds simultâneos, energia e throttling).
+package host
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"dev-metrics/internal/metrics"
+)
+
+// ProcRoot e SysRoot são as raízes do procfs e do sysfs. Variáveis para
+// permitir testes com fixtures.
+var (
+	ProcRoot = "/proc"
+	SysRoot  = "/sys"
+)
+
+// Collect monta o perfil da máquina atual. buildDir é o diretório cujo sistema
+// de arquivos é registrado. Fora do Linux, só os campos de runtime são
+// preenchidos.
+func Collect(hostname, buildDir string) metrics.HostProfile {
+	p := metrics.HostProfile{
+		Hostname:     hostname,
+		OS:           runtime.GOOS,
+		Arch:         runtime.GOARCH,
+		LogicalCores: runtime.NumCPU(),
+	}
+	readCPUInfo(&p)
+	if cores := physicalCoresFromSys(); cores > 0 {
+		p.PhysicalCores = cores
+	}
+	if khz, err := readUint(filepath.Join(SysRoot, "devices/system/cpu/cpu0/cpufreq/cpuinfo_max_freq")); err == nil {
+		p.MaxFreqMHz = int(khz / 1000)
+	}
+	p.MemTotalBytes = memTotal()
+	if data, err := os.ReadFile(filepath.Join(ProcRoot, "sys/kernel/osrelease")); err == nil {
+		p.Kernel = strings.TrimSpace(string(data))
+	}
+	if buildDir != "" {
+		p.FSType = fsType(buildDir)
+	}
+	p.ID = p.Fingerprint()
+	return p
+}
+
+// readCPUInfo lê o modelo, os núcleos lógicos e, em x86, os físicos de /proc/cpuinfo.
+func readCPUInfo(p *metrics.HostProfile) {
+	data, err := os.ReadFile(filepath.Join(ProcRoot, "cpuinfo"))
+	if err != nil {
+		return
+	}
+	logical := 0
+	cores := make(map[string]bool)
+	var physicalID string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		switch key {
+		case "processor":
+			logical++
+		case "model name", "Hardware", "Model":
+			// "model name" em x86; ARM informa "Hardware" ou "Model"
+			if p.CPUModel == "" || key == "model name" {
+				p.CPUModel = value
+			}
+		case "physical id":
+			physicalID = value
+		case "core id":
+			cores[physicalID+"/"+value] = true
+		}
+	}
+	if logical > 0 {
+		p.LogicalCores = logical
+	}
+	if len(cores) > 0 {
+		p.PhysicalCores = len(cores)
+	}
+}
+
+var cpuDirPattern = regexp.MustCompile(`^cpu[0-9]+$`)
+
+// physicalCoresFromSys conta os núcleos físicos pela topologia do sysfs, que
+// também existe em ARM. Retorna 0 se a topologia não está disponível.
+func physicalCoresFromSys() int {
+	dir := filepath.Join(SysRoot, "devices/system/cpu")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0
+	}
+	cores := make(map[string]bool)
+	for _, e := range entries {
+		if !cpuDirPattern.MatchString(e.Name()) {
+			continue
+		}
+		pkg, err1 := readUint(filepath.Join(dir, e.Name(), "topology/physical_package_id"))
+		core, err2 := readUint(filepath.Join(dir, e.Name(), "topology/core_id"))
+		if err1 != nil || err2 != nil {
+			continue // CPU offline
+		}
+		cores[strconv.FormatUint(pkg, 10)+"/"+strconv.FormatUint(core, 10)] = true
+	}
+	return len(cores)
+}
+
+// memTotal lê MemTotal de /proc/meminfo, em bytes.
+func memTotal() uint64 {
+	data, err := os.ReadFile(filepath.Join(ProcRoot, "meminfo"))
+	if err != nil {
+		return 0
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if rest, ok := strings.CutPrefix(line, "MemTotal:"); ok {
+			fields := strings.Fields(rest)
+			if len(fields) > 0 {
+				kb, _ := strconv.ParseUint(fields[0], 10, 64)
+				return kb * 1024
+			}
+		}
+	}
+	return 0
+}
+
+// fsType retorna o tipo do sistema de arquivos montado mais próximo de dir,
+// segundo /proc/self/mounts.
+func fsType(dir string) string {
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+	data, err := os.ReadFile(filepath.Join(ProcRoot, "self/mounts"))
+	if err != nil {
+		return ""
+	}
+	best, bestType := "", ""
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		mountPoint := unescapeMount(fields[1])
+		if !within(dir, mountPoint) || len(mountPoint) < len(best) {
+			continue
+		}
+		// Montagens posteriores no mesmo ponto escondem as anteriores
+		best, bestType = mountPoint, fields[2]
+	}
+	return bestType
+}
+
+// within informa se path está em dir ou abaixo dele.
+func within(path, dir string) bool {
+	if dir == "/" || path == dir {
+		return true
+	}
+	return strings.HasPrefix(path, dir+"/")
+}
+
+// unescapeMount desfaz os escapes octais de /proc/self/mounts (ex.: \040 para espaço).
+func unescapeMount(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) {
+			if v, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(v))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func readUint(path string) (uint64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+}
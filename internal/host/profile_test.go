@@ -0,0 +1,75 @@
+package host
+
+import (
+	"path/filepath"
+	"testing"
+
+	"dev-metrics/internal/metrics"
+)
+
+func withFixtures(t *testing.T) {
+	t.Helper()
+	origProc, origSys := ProcRoot, SysRoot
+	ProcRoot, SysRoot = filepath.Join("testdata", "proc"), filepath.Join("testdata", "sys")
+	t.Cleanup(func() { ProcRoot, SysRoot = origProc, origSys })
+}
+
+func TestCollect(t *testing.T) {
+	withFixtures(t)
+	p := Collect("laptop", "/home/u/src/project")
+
+	if p.CPUModel != "11th Gen Intel(R) Core(TM) i7-1185G7 @ 3.00GHz" {
+		t.Errorf("CPUModel = %q", p.CPUModel)
+	}
+	if p.LogicalCores != 4 || p.PhysicalCores != 2 {
+		t.Errorf("núcleos = %d físicos / %d lógicos, want 2/4", p.PhysicalCores, p.LogicalCores)
+	}
+	if p.MaxFreqMHz != 4800 {
+		t.Errorf("MaxFreqMHz = %d, want 4800", p.MaxFreqMHz)
+	}
+	if p.MemTotalBytes != 32617372*1024 {
+		t.Errorf("MemTotalBytes = %d", p.MemTotalBytes)
+	}
+	if p.Kernel != "6.8.0-45-generic" || p.Hostname != "laptop" {
+		t.Errorf("Kernel = %q, Hostname = %q", p.Kernel, p.Hostname)
+	}
+	if p.FSType != "ext4" {
+		t.Errorf("FSType = %q, want ext4", p.FSType)
+	}
+	if p.ID == "" || p.ID != p.Fingerprint() {
+		t.Errorf("ID = %q, want Fingerprint() %q", p.ID, p.Fingerprint())
+	}
+}
+
+func TestFSType(t *testing.T) {
+	withFixtures(t)
+	tests := map[string]string{
+		"/home/u/build/out": "tmpfs",
+		"/home/u/builder":   "ext4",
+		"/var/tmp":          "btrfs",
+		"/mnt/my disk/src":  "cifs",
+	}
+	for dir, want := range tests {
+		if got := fsType(dir); got != want {
+			t.Errorf("fsType(%q) = %q, want %q", dir, got, want)
+		}
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	withFixtures(t)
+	a := Collect("laptop", "/home/u/src")
+	b := Collect("laptop", "/home/u/src")
+	b.FirstSeen = "2024-01-01T00:00:00Z"
+	if a.ID != b.Fingerprint() {
+		t.Error("Fingerprint() não deveria depender de FirstSeen")
+	}
+	other := a
+	other.Kernel = "6.9.0"
+	if other.Fingerprint() == a.ID {
+		t.Error("Fingerprint() deveria mudar com o kernel")
+	}
+	if got := (metrics.HostProfile{CPUModel: "M2", PhysicalCores: 8, LogicalCores: 8, MemTotalBytes: 16 << 30}).Label(); got != "M2 (8c/8t, 16.0 GB)" {
+		t.Errorf("Label() = %q", got)
+	}
+}
@@ -2,7 +2,6 @@ package metrics
 
 import (
 	"fmt"
-	"io"
 	"sort"
 	"time"
 )
@@ -15,16 +14,22 @@ type reportKey struct {
 
 // GenerateReport processa o log e retorna os dados estruturados
 // Agora aceita opções de filtro
-func GenerateReport(r io.Reader, opts ReportOptions) (*FullReport, error) {
+func GenerateReport(logs []LogFile, opts ReportOptions) (*FullReport, error) {
 	// 1. Estruturas temporárias para acumulação (Mapas)
 	// Map: [Projeto - Semana - ano] -> Stats
 	tempData := make(map[reportKey]*BuildStats)
-
-	// 2. Scan e Acumulação
-	_, err := ScanJSONL(r, false, func(m BuildMetric) error {
+	invalidTimestamps := 0
+	contended := 0
+	detailBuilds := 0 // builds com detalhamento (ferramentas ou fases)
+	// Builds com alvos (bmt make ou .ninja_log), contados uma vez no --by target
+	targetRuns := make(map[string]bool)
+
+	// 2. Scan e Acumulação (a agregação é comutativa, então a ordem de entrega não importa)
+	scanRes, err := ScanLogs(logs, ScanOptions{Unordered: true}, func(m BuildMetric) error {
 		t, err := time.Parse(time.RFC3339, m.Timestamp)
 		if err != nil {
-			return nil // Ignora erro de parse pontual
+			invalidTimestamps++ // Ignora erro de parse pontual, mas contabiliza
+			return nil
 		}
 
 		// Filtro de Data (--since / --until)
@@ -34,22 +39,88 @@ func GenerateReport(r io.Reader, opts ReportOptions) (*FullReport, error) {
 		if !opts.Until.IsZero() && t.After(opts.Until) {
 			return nil
 		}
+		if !opts.Benchmarks.Keep(m) {
+			return nil
+		}
+		// Os alvos são sempre filhos da execução de bmt make
+		if m.ParentID != "" && !opts.IncludeNested && opts.GroupBy != GroupByTarget {
+			return nil
+		}
+		// Durações informadas à mão (record, span) não são builds: só entram
+		// quando pedidas, e separadas dos builds do projeto
+		manual := m.Kind == KindRecord || m.Kind == KindSpan
+		if manual && !opts.Kinds[m.Kind] {
+			return nil
+		}
+		if opts.ExcludeContended && m.Contended() {
+			contended++
+			return nil
+		}
 
 		year, week := t.ISOWeek()
-
-		key := reportKey{
-			Project: m.Project,
-			Year:    year,
-			Week:    week,
+		add := func(name string, duration float64) {
+			key := reportKey{
+				Project: name,
+				Year:    year,
+				Week:    week,
+			}
+
+			if _, ok := tempData[key]; !ok {
+				tempData[key] = &BuildStats{}
+			}
+
+			stats := tempData[key]
+			stats.TotalDuration += duration
+			stats.Count++
 		}
 
-		if _, ok := tempData[key]; !ok {
-			tempData[key] = &BuildStats{}
+		// Por ferramenta, a duração é o tempo de CPU e a contagem é o número
+		// de builds em que a ferramenta apareceu
+		switch opts.GroupBy {
+		case GroupByTool:
+			if len(m.Tools) > 0 {
+				detailBuilds++
+			}
+			for _, tool := range m.Tools {
+				add(tool.Name, tool.CPUSec)
+			}
+			return nil
+		case GroupByPhase:
+			// Nomes de fase só fazem sentido dentro de um projeto
+			if len(m.Phases) > 0 {
+				detailBuilds++
+			}
+			for _, phase := range m.Phases {
+				add(m.Project+" / "+phase.Name, phase.DurationSec)
+			}
+			return nil
+		case GroupByTarget:
+			// Nomes de alvo só fazem sentido dentro de um projeto
+			if m.Kind == KindTarget {
+				targetRuns[m.ParentID] = true
+				add(m.Project+" / "+m.Command, m.DurationSec)
+			}
+			if len(m.Targets) > 0 {
+				targetRuns[m.ID] = true
+			}
+			for _, target := range m.Targets {
+				add(m.Project+" / "+target.Name, target.DurationSec)
+			}
+			return nil
+		case GroupByHost:
+			// Máquinas com o mesmo hardware são somadas; o projeto distingue builds diferentes
+			label := UnknownHostProfile
+			if p, ok := opts.HostProfiles[m.HostProfile]; ok {
+				label = p.Label()
+			}
+			add(label+" / "+m.Project, m.DurationSec)
+			return nil
 		}
-
-		stats := tempData[key]
-		stats.TotalDuration += m.DurationSec
-		stats.Count++
+		if manual {
+			add(m.Project+" / "+m.Kind, m.DurationSec)
+			return nil
+		}
+		add(m.Project, m.DurationSec)
 		return nil
 	})
 
@@ -60,6 +131,9 @@ func GenerateReport(r io.Reader, opts ReportOptions) (*FullReport, error) {
 	// 3. Transformação de Mapas para Slices (Struct Final)
 	report := &FullReport{}
 	report.ReportOptions = opts // Preserva opções para referência futura
+	report.Skipped = scanRes.Skipped
+	report.InvalidTimestamps = invalidTimestamps
+	report.Contended = contended
 	projectMap := make(map[string]*ProjectSummary)
 
 	for k, stat := range tempData {
@@ -96,9 +170,21 @@ func GenerateReport(r io.Reader, opts ReportOptions) (*FullReport, error) {
 		report.GlobalBuilds += proj.TotalBuilds
 	}
 
-	// Ordena projetos
+	if opts.GroupBy == GroupByTool || opts.GroupBy == GroupByPhase {
+		// Um build aparece em várias ferramentas ou fases; conta cada build uma vez só
+		report.GlobalBuilds = detailBuilds
+	}
+	if opts.GroupBy == GroupByTarget {
+		report.GlobalBuilds = len(targetRuns)
+	}
+
+	// Ordena projetos; ferramentas pelas que mais custaram
 	sort.Slice(report.Projects, func(i, j int) bool {
-		return report.Projects[i].Name < report.Projects[j].Name
+		pi, pj := report.Projects[i], report.Projects[j]
+		if opts.GroupBy == GroupByTool && pi.TotalDuration != pj.TotalDuration {
+			return pi.TotalDuration > pj.TotalDuration
+		}
+		return pi.Name < pj.Name
 	})
 
 	return report, nil
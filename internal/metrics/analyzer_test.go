@@ -2,12 +2,16 @@ package metrics
 
 import (
 	"fmt"
+	"io"
 	"reflect"
 	"strings"
 	"testing"
 	"time"
 )
 
+// singleLog entrega r aos relatórios como o único log lido.
+func singleLog(r io.Reader) []LogFile { return []LogFile{{Name: "log.jsonl", R: r}} }
+
 func TestGenerateReport(t *testing.T) {
 	// Definição dos cenários de teste
 	tests := []struct {
@@ -193,8 +197,9 @@ func TestGenerateReport(t *testing.T) {
 						},
 					},
 				},
-				GlobalDuration: 5,
-				GlobalBuilds:   1,
+				GlobalDuration:    5,
+				GlobalBuilds:      1,
+				InvalidTimestamps: 1,
 			},
 			wantErr: false,
 		},
@@ -226,9 +231,323 @@ func TestGenerateReport(t *testing.T) {
 				},
 				GlobalDuration: 30,
 				GlobalBuilds:   2,
+				Skipped:        2,
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Group by tool",
+			options: ReportOptions{GroupBy: GroupByTool},
+			input: `
+{"project": "backend", "timestamp": "2024-01-03T10:00:00Z", "duration_sec": 10, "tools": [{"name": "cc1plus", "cpu_sec": 8}, {"name": "ld", "cpu_sec": 1}]}
+{"project": "frontend", "timestamp": "2024-01-04T12:00:00Z", "duration_sec": 20, "tools": [{"name": "cc1plus", "cpu_sec": 4}]}
+{"project": "backend", "timestamp": "2024-01-04T13:00:00Z", "duration_sec": 5}
+`,
+			want: &FullReport{
+				Projects: []ProjectSummary{
+					{
+						Name:          "cc1plus",
+						TotalDuration: 12,
+						TotalBuilds:   2,
+						Weeks: []WeeklySummary{
+							{WeekLabel: "2024-W01", BuildStats: BuildStats{TotalDuration: 12, Count: 2}, AvgDuration: 6},
+						},
+					},
+					{
+						Name:          "ld",
+						TotalDuration: 1,
+						TotalBuilds:   1,
+						Weeks: []WeeklySummary{
+							{WeekLabel: "2024-W01", BuildStats: BuildStats{TotalDuration: 1, Count: 1}, AvgDuration: 1},
+						},
+					},
+				},
+				GlobalDuration: 13,
+				GlobalBuilds:   2, // builds com detalhamento, não aparições de ferramentas
+				ReportOptions:  ReportOptions{GroupBy: GroupByTool},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Group by phase",
+			options: ReportOptions{GroupBy: GroupByPhase},
+			input: `
+{"project": "backend", "timestamp": "2024-01-03T10:00:00Z", "duration_sec": 10, "phases": [{"name": "compile", "start_sec": 0, "duration_sec": 6}, {"name": "test", "start_sec": 6, "duration_sec": 4}]}
+{"project": "backend", "timestamp": "2024-01-10T10:00:00Z", "duration_sec": 12, "phases": [{"name": "compile", "start_sec": 0, "duration_sec": 8}]}
+{"project": "backend", "timestamp": "2024-01-10T11:00:00Z", "duration_sec": 5}
+`,
+			want: &FullReport{
+				Projects: []ProjectSummary{
+					{
+						Name:          "backend / compile",
+						TotalDuration: 14,
+						TotalBuilds:   2,
+						Weeks: []WeeklySummary{
+							{WeekLabel: "2024-W01", BuildStats: BuildStats{TotalDuration: 6, Count: 1}, AvgDuration: 6},
+							{WeekLabel: "2024-W02", BuildStats: BuildStats{TotalDuration: 8, Count: 1}, AvgDuration: 8},
+						},
+					},
+					{
+						Name:          "backend / test",
+						TotalDuration: 4,
+						TotalBuilds:   1,
+						Weeks: []WeeklySummary{
+							{WeekLabel: "2024-W01", BuildStats: BuildStats{TotalDuration: 4, Count: 1}, AvgDuration: 4},
+						},
+					},
+				},
+				GlobalDuration: 18,
+				GlobalBuilds:   2,
+				ReportOptions:  ReportOptions{GroupBy: GroupByPhase},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Group by host",
+			options: ReportOptions{GroupBy: GroupByHost, HostProfiles: map[string]HostProfile{
+				"h1": {ID: "h1", Hostname: "a", CPUModel: "i7-1185G7", PhysicalCores: 4, LogicalCores: 8, MemTotalBytes: 32 << 30},
+				"h2": {ID: "h2", Hostname: "b", CPUModel: "i7-1185G7", PhysicalCores: 4, LogicalCores: 8, MemTotalBytes: 32 << 30},
+			}},
+			input: `
+{"project": "backend", "timestamp": "2024-01-03T10:00:00Z", "duration_sec": 10, "host_profile": "h1"}
+{"project": "backend", "timestamp": "2024-01-03T11:00:00Z", "duration_sec": 20, "host_profile": "h2"}
+{"project": "backend", "timestamp": "2024-01-03T12:00:00Z", "duration_sec": 40}
+`,
+			want: &FullReport{
+				Projects: []ProjectSummary{
+					{
+						Name:          "i7-1185G7 (4c/8t, 32.0 GB) / backend",
+						TotalDuration: 30,
+						TotalBuilds:   2,
+						Weeks: []WeeklySummary{
+							{WeekLabel: "2024-W01", BuildStats: BuildStats{TotalDuration: 30, Count: 2}, AvgDuration: 15},
+						},
+					},
+					{
+						Name:          UnknownHostProfile + " / backend",
+						TotalDuration: 40,
+						TotalBuilds:   1,
+						Weeks: []WeeklySummary{
+							{WeekLabel: "2024-W01", BuildStats: BuildStats{TotalDuration: 40, Count: 1}, AvgDuration: 40},
+						},
+					},
+				},
+				GlobalDuration: 70,
+				GlobalBuilds:   3,
+				ReportOptions: ReportOptions{GroupBy: GroupByHost, HostProfiles: map[string]HostProfile{
+					"h1": {ID: "h1", Hostname: "a", CPUModel: "i7-1185G7", PhysicalCores: 4, LogicalCores: 8, MemTotalBytes: 32 << 30},
+					"h2": {ID: "h2", Hostname: "b", CPUModel: "i7-1185G7", PhysicalCores: 4, LogicalCores: 8, MemTotalBytes: 32 << 30},
+				}},
 			},
 			wantErr: false,
 		},
+		{
+			name: "Benchmark iterations are excluded by default",
+			input: `
+{"project": "backend", "timestamp": "2024-01-03T10:00:00Z", "duration_sec": 10}
+{"project": "backend", "timestamp": "2024-01-03T11:00:00Z", "duration_sec": 50, "benchmark_id": "B1", "iteration": 1}
+`,
+			want: &FullReport{
+				Projects: []ProjectSummary{
+					{
+						Name:          "backend",
+						TotalDuration: 10,
+						TotalBuilds:   1,
+						Weeks: []WeeklySummary{
+							{WeekLabel: "2024-W01", BuildStats: BuildStats{TotalDuration: 10, Count: 1}, AvgDuration: 10},
+						},
+					},
+				},
+				GlobalDuration: 10,
+				GlobalBuilds:   1,
+			},
+		},
+		{
+			name: "Nested runs are excluded by default",
+			input: `
+{"id": "01B", "parent_id": "01A", "depth": 1, "project": "backend", "timestamp": "2024-01-03T10:09:00Z", "duration_sec": 40}
+{"id": "01A", "project": "backend", "timestamp": "2024-01-03T10:10:00Z", "duration_sec": 60}
+`,
+			want: &FullReport{
+				Projects: []ProjectSummary{
+					{
+						Name:          "backend",
+						TotalDuration: 60,
+						TotalBuilds:   1,
+						Weeks: []WeeklySummary{
+							{WeekLabel: "2024-W01", BuildStats: BuildStats{TotalDuration: 60, Count: 1}, AvgDuration: 60},
+						},
+					},
+				},
+				GlobalDuration: 60,
+				GlobalBuilds:   1,
+			},
+		},
+		{
+			name:    "Nested runs included on request",
+			options: ReportOptions{IncludeNested: true},
+			input: `
+{"id": "01B", "parent_id": "01A", "depth": 1, "project": "backend", "timestamp": "2024-01-03T10:09:00Z", "duration_sec": 40}
+{"id": "01A", "project": "backend", "timestamp": "2024-01-03T10:10:00Z", "duration_sec": 60}
+`,
+			want: &FullReport{
+				Projects: []ProjectSummary{
+					{
+						Name:          "backend",
+						TotalDuration: 100,
+						TotalBuilds:   2,
+						Weeks: []WeeklySummary{
+							{WeekLabel: "2024-W01", BuildStats: BuildStats{TotalDuration: 100, Count: 2}, AvgDuration: 50},
+						},
+					},
+				},
+				GlobalDuration: 100,
+				GlobalBuilds:   2,
+				ReportOptions:  ReportOptions{IncludeNested: true},
+			},
+		},
+		{
+			name:    "Group by make target",
+			options: ReportOptions{GroupBy: GroupByTarget},
+			input: `
+{"id": "01A", "project": "backend", "command": "[make all]", "timestamp": "2024-01-03T10:10:00Z", "duration_sec": 60}
+{"id": "01B", "parent_id": "01A", "depth": 1, "kind": "target", "project": "backend", "command": "main.o", "timestamp": "2024-01-03T10:09:00Z", "duration_sec": 40}
+{"id": "01C", "parent_id": "01A", "depth": 1, "kind": "target", "project": "backend", "command": "app", "timestamp": "2024-01-03T10:10:00Z", "duration_sec": 5}
+{"id": "01E", "parent_id": "01D", "depth": 1, "kind": "target", "project": "backend", "command": "main.o", "timestamp": "2024-01-04T10:09:00Z", "duration_sec": 20}
+{"id": "01F", "parent_id": "01A", "depth": 1, "project": "backend", "command": "[cmake --build .]", "timestamp": "2024-01-03T10:10:00Z", "duration_sec": 30}
+`,
+			want: &FullReport{
+				Projects: []ProjectSummary{
+					{
+						Name:          "backend / app",
+						TotalDuration: 5,
+						TotalBuilds:   1,
+						Weeks: []WeeklySummary{
+							{WeekLabel: "2024-W01", BuildStats: BuildStats{TotalDuration: 5, Count: 1}, AvgDuration: 5},
+						},
+					},
+					{
+						Name:          "backend / main.o",
+						TotalDuration: 60,
+						TotalBuilds:   2,
+						Weeks: []WeeklySummary{
+							{WeekLabel: "2024-W01", BuildStats: BuildStats{TotalDuration: 60, Count: 2}, AvgDuration: 30},
+						},
+					},
+				},
+				GlobalDuration: 65,
+				GlobalBuilds:   2,
+				ReportOptions:  ReportOptions{GroupBy: GroupByTarget},
+			},
+		},
+		{
+			name:    "Group by ninja target",
+			options: ReportOptions{GroupBy: GroupByTarget},
+			input: `
+{"id": "01A", "project": "engine", "timestamp": "2024-01-03T10:10:00Z", "duration_sec": 60, "targets": [{"name": "render.o", "duration_sec": 30}, {"name": "ui.o", "duration_sec": 10}]}
+{"id": "01B", "project": "engine", "timestamp": "2024-01-04T10:10:00Z", "duration_sec": 50, "targets": [{"name": "render.o", "duration_sec": 20}]}
+`,
+			want: &FullReport{
+				Projects: []ProjectSummary{
+					{
+						Name:          "engine / render.o",
+						TotalDuration: 50,
+						TotalBuilds:   2,
+						Weeks: []WeeklySummary{
+							{WeekLabel: "2024-W01", BuildStats: BuildStats{TotalDuration: 50, Count: 2}, AvgDuration: 25},
+						},
+					},
+					{
+						Name:          "engine / ui.o",
+						TotalDuration: 10,
+						TotalBuilds:   1,
+						Weeks: []WeeklySummary{
+							{WeekLabel: "2024-W01", BuildStats: BuildStats{TotalDuration: 10, Count: 1}, AvgDuration: 10},
+						},
+					},
+				},
+				GlobalDuration: 60,
+				GlobalBuilds:   2,
+				ReportOptions:  ReportOptions{GroupBy: GroupByTarget},
+			},
+		},
+		{
+			name: "Records and spans are not builds",
+			input: `
+{"project": "backend", "timestamp": "2024-01-03T10:00:00Z", "duration_sec": 10}
+{"project": "backend", "timestamp": "2024-01-03T11:00:00Z", "duration_sec": 840, "command": "ci wait", "kind": "record"}
+{"project": "backend", "timestamp": "2024-01-03T12:00:00Z", "duration_sec": 3600, "command": "code review", "kind": "span"}
+`,
+			want: &FullReport{
+				Projects: []ProjectSummary{
+					{
+						Name:          "backend",
+						TotalDuration: 10,
+						TotalBuilds:   1,
+						Weeks: []WeeklySummary{
+							{WeekLabel: "2024-W01", BuildStats: BuildStats{TotalDuration: 10, Count: 1}, AvgDuration: 10},
+						},
+					},
+				},
+				GlobalDuration: 10,
+				GlobalBuilds:   1,
+			},
+		},
+		{
+			name:    "Records and spans on request",
+			options: ReportOptions{Kinds: map[string]bool{KindSpan: true}},
+			input: `
+{"project": "backend", "timestamp": "2024-01-03T10:00:00Z", "duration_sec": 10}
+{"project": "backend", "timestamp": "2024-01-03T11:00:00Z", "duration_sec": 840, "command": "ci wait", "kind": "record"}
+{"project": "backend", "timestamp": "2024-01-03T12:00:00Z", "duration_sec": 3600, "command": "code review", "kind": "span"}
+`,
+			want: &FullReport{
+				Projects: []ProjectSummary{
+					{
+						Name:          "backend",
+						TotalDuration: 10,
+						TotalBuilds:   1,
+						Weeks: []WeeklySummary{
+							{WeekLabel: "2024-W01", BuildStats: BuildStats{TotalDuration: 10, Count: 1}, AvgDuration: 10},
+						},
+					},
+					{
+						Name:          "backend / span",
+						TotalDuration: 3600,
+						TotalBuilds:   1,
+						Weeks: []WeeklySummary{
+							{WeekLabel: "2024-W01", BuildStats: BuildStats{TotalDuration: 3600, Count: 1}, AvgDuration: 3600},
+						},
+					},
+				},
+				GlobalDuration: 3610,
+				GlobalBuilds:   2,
+				ReportOptions:  ReportOptions{Kinds: map[string]bool{KindSpan: true}},
+			},
+		},
+		{
+			name:    "Only benchmark iterations",
+			options: ReportOptions{Benchmarks: BenchmarksOnly},
+			input: `
+{"project": "backend", "timestamp": "2024-01-03T10:00:00Z", "duration_sec": 10}
+{"project": "backend", "timestamp": "2024-01-03T11:00:00Z", "duration_sec": 50, "benchmark_id": "B1", "iteration": 1}
+`,
+			want: &FullReport{
+				Projects: []ProjectSummary{
+					{
+						Name:          "backend",
+						TotalDuration: 50,
+						TotalBuilds:   1,
+						Weeks: []WeeklySummary{
+							{WeekLabel: "2024-W01", BuildStats: BuildStats{TotalDuration: 50, Count: 1}, AvgDuration: 50},
+						},
+					},
+				},
+				GlobalDuration: 50,
+				GlobalBuilds:   1,
+				ReportOptions:  ReportOptions{Benchmarks: BenchmarksOnly},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -236,7 +555,7 @@ func TestGenerateReport(t *testing.T) {
 			// Cria um Reader a partir da string de input
 			r := strings.NewReader(tt.input)
 
-			got, err := GenerateReport(r, tt.options)
+			got, err := GenerateReport(singleLog(r), tt.options)
 
 			// Verifica se o erro ocorreu conforme esperado
 			if (err != nil) != tt.wantErr {
@@ -263,6 +582,18 @@ func TestGenerateReport(t *testing.T) {
 	}
 }
 
+func TestParseGroupBy(t *testing.T) {
+	for in, want := range map[string]GroupBy{"": GroupByProject, "project": GroupByProject, "tool": GroupByTool, "phase": GroupByPhase, "target": GroupByTarget} {
+		got, err := ParseGroupBy(in)
+		if err != nil || got != want {
+			t.Errorf("ParseGroupBy(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseGroupBy("semana"); err == nil {
+		t.Error("ParseGroupBy(\"semana\") deveria falhar")
+	}
+}
+
 type errorReader struct{}
 
 func (e *errorReader) Read(p []byte) (n int, err error) {
@@ -273,7 +604,7 @@ func TestGenerateReportWithInvalidReader(t *testing.T) {
 	// Simula um reader que retorna erro
 	r := &errorReader{}
 
-	_, err := GenerateReport(r, ReportOptions{})
+	_, err := GenerateReport(singleLog(r), ReportOptions{})
 	if err == nil {
 		t.Errorf("GenerateReport() expected error, got nil")
 	}
@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ArtifactsDirName é o diretório, ao lado do log, onde ficam os artefatos das execuções.
+const ArtifactsDirName = "artifacts"
+
+// SaveOutput grava a saída capturada da execução id em <dir do log>/artifacts/<id>.log.
+// Retorna o caminho relativo ao diretório do log, que é o valor guardado em
+// BuildMetric.OutputFile (assim o log e os artefatos podem ser movidos juntos).
+func SaveOutput(logPath, id string, output []byte) (string, error) {
+	if id == "" {
+		return "", fmt.Errorf("execução sem id; não é possível salvar a saída")
+	}
+	dir := filepath.Join(filepath.Dir(logPath), ArtifactsDirName)
+	if err := MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	rel := filepath.Join(ArtifactsDirName, id+".log")
+	// Saídas capturadas via pseudo-terminal usam \r\n
+	output = bytes.ReplaceAll(output, []byte("\r\n"), []byte("\n"))
+	if err := os.WriteFile(filepath.Join(filepath.Dir(logPath), rel), output, 0644); err != nil {
+		return "", err
+	}
+	return rel, nil
+}
+
+// ResolveArtifact retorna o caminho de um artefato (como guardado na métrica)
+// a partir do log onde o registro está.
+func ResolveArtifact(logPath, file string) string {
+	if filepath.IsAbs(file) {
+		return file
+	}
+	return filepath.Join(filepath.Dir(logPath), file)
+}
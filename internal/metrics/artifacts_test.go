@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSaveOutput(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "log.jsonl")
+
+	rel, err := SaveOutput(logPath, "01ABC", []byte("linha 1\r\nlinha 2\r\n"))
+	if err != nil {
+		t.Fatalf("SaveOutput() erro: %v", err)
+	}
+	if rel != filepath.Join("artifacts", "01ABC.log") {
+		t.Errorf("SaveOutput() = %q", rel)
+	}
+	data, err := os.ReadFile(ResolveArtifact(logPath, rel))
+	if err != nil || string(data) != "linha 1\nlinha 2\n" {
+		t.Errorf("artefato = %q (err %v)", data, err)
+	}
+
+	if _, err := SaveOutput(logPath, "", []byte("x")); err == nil {
+		t.Error("SaveOutput() sem id deveria falhar")
+	}
+	if got := ResolveArtifact(logPath, "/abs/out.log"); got != "/abs/out.log" {
+		t.Errorf("ResolveArtifact(abs) = %q", got)
+	}
+}
+
+func TestFindByID(t *testing.T) {
+	input := `{"id":"01AA","project":"a"}
+{"id":"01AAB","project":"b"}
+{"project":"sem id"}
+{"id":"01BB","project":"c"}
+`
+	tests := []struct {
+		prefix string
+		want   []string
+	}{
+		{"01AA", []string{"a"}}, // id exato tem prioridade
+		{"01A", []string{"a", "b"}},
+		{"01B", []string{"c"}},
+		{"02", nil},
+	}
+	for _, tt := range tests {
+		got, err := FindByID(strings.NewReader(input), tt.prefix)
+		if err != nil {
+			t.Fatalf("FindByID(%q) erro: %v", tt.prefix, err)
+		}
+		var projects []string
+		for _, m := range got {
+			projects = append(projects, m.Project)
+		}
+		if strings.Join(projects, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("FindByID(%q) = %v, want %v", tt.prefix, projects, tt.want)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package metrics
+
+import (
+	"sort"
+	"time"
+)
+
+// cacheBucketLabels são as faixas de taxa de acerto usadas em CacheSummary.Buckets.
+var cacheBucketLabels = []string{"0-25%", "25-50%", "50-75%", "75-100%"}
+
+// CacheBucket agrega os builds cuja taxa de acerto cai em uma faixa.
+type CacheBucket struct {
+	Label          string
+	Count          int
+	AvgDurationSec float64
+}
+
+// CacheSummary relaciona a taxa de acerto de uma probe de cache com a duração
+// dos builds de um projeto.
+type CacheSummary struct {
+	Project string
+	Probe   string
+	Builds  int
+	Hits    float64
+	Misses  float64
+	HitRate float64       // Hits / (Hits + Misses) somados no período
+	Buckets []CacheBucket // Uma entrada por faixa de taxa de acerto, da menor para a maior
+}
+
+// CacheReport mostra a taxa de acerto de cache (probes com hits e misses) por
+// projeto e a duração média dos builds em cada faixa de acerto.
+type CacheReport struct {
+	Entries []CacheSummary // Ordenadas por projeto e probe
+	ReportOptions
+
+	WithoutCache      int // Builds bem-sucedidos sem probe de cache ou sem compilações
+	Skipped           int // Linhas JSON inválidas ignoradas
+	InvalidTimestamps int // Registros ignorados por timestamp inválido
+	Contended         int // Execuções ignoradas por contenção (com ExcludeContended)
+}
+
+// GenerateCacheReport agrega os builds bem-sucedidos com contadores de acerto
+// e falha de cache. Falhas e interrupções ficam de fora porque a duração
+// delas não é comparável.
+func GenerateCacheReport(logs []LogFile, opts ReportOptions) (*CacheReport, error) {
+	report := &CacheReport{ReportOptions: opts}
+	type key struct{ project, probe string }
+	type acc struct {
+		CacheSummary
+		totals []float64
+	}
+	entries := make(map[key]*acc)
+
+	scanRes, err := ScanLogs(logs, ScanOptions{Unordered: true}, func(m BuildMetric) error {
+		t, err := time.Parse(time.RFC3339, m.Timestamp)
+		if err != nil {
+			report.InvalidTimestamps++
+			return nil
+		}
+		if !opts.Since.IsZero() && t.Before(opts.Since) {
+			return nil
+		}
+		if !opts.Until.IsZero() && t.After(opts.Until) {
+			return nil
+		}
+		if !opts.Benchmarks.Keep(m) {
+			return nil
+		}
+		if m.ParentID != "" && !opts.IncludeNested {
+			return nil
+		}
+		if opts.ExcludeContended && m.Contended() {
+			report.Contended++
+			return nil
+		}
+		if m.Status != "success" {
+			return nil
+		}
+
+		found := false
+		for name, counters := range m.Probes {
+			hits, hasHits := counters["hits"]
+			misses, hasMisses := counters["misses"]
+			if !hasHits || !hasMisses || hits+misses <= 0 {
+				continue
+			}
+			found = true
+			k := key{m.Project, name}
+			e := entries[k]
+			if e == nil {
+				e = &acc{CacheSummary: CacheSummary{Project: m.Project, Probe: name}, totals: make([]float64, len(cacheBucketLabels))}
+				for _, label := range cacheBucketLabels {
+					e.Buckets = append(e.Buckets, CacheBucket{Label: label})
+				}
+				entries[k] = e
+			}
+			e.Builds++
+			e.Hits += hits
+			e.Misses += misses
+			i := int(hits / (hits + misses) * float64(len(cacheBucketLabels)))
+			if i >= len(cacheBucketLabels) {
+				i = len(cacheBucketLabels) - 1
+			}
+			e.Buckets[i].Count++
+			e.totals[i] += m.DurationSec
+		}
+		if !found {
+			report.WithoutCache++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	report.Skipped = scanRes.Skipped
+
+	for _, e := range entries {
+		e.HitRate = e.Hits / (e.Hits + e.Misses)
+		for i := range e.Buckets {
+			if e.Buckets[i].Count > 0 {
+				e.Buckets[i].AvgDurationSec = e.totals[i] / float64(e.Buckets[i].Count)
+			}
+		}
+		report.Entries = append(report.Entries, e.CacheSummary)
+	}
+	sort.Slice(report.Entries, func(i, j int) bool {
+		if report.Entries[i].Project != report.Entries[j].Project {
+			return report.Entries[i].Project < report.Entries[j].Project
+		}
+		return report.Entries[i].Probe < report.Entries[j].Probe
+	})
+	return report, nil
+}
@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGenerateCacheReport(t *testing.T) {
+	input := `
+{"project": "backend", "timestamp": "2024-01-03T10:00:00Z", "duration_sec": 600, "status": "success", "probes": {"ccache": {"hits": 10, "misses": 90, "cache_size_bytes": 4096}}}
+{"project": "backend", "timestamp": "2024-01-03T11:00:00Z", "duration_sec": 60, "status": "success", "probes": {"ccache": {"hits": 95, "misses": 5}}}
+{"project": "backend", "timestamp": "2024-01-03T12:00:00Z", "duration_sec": 40, "status": "success", "probes": {"ccache": {"hits": 100, "misses": 0}}}
+{"project": "backend", "timestamp": "2024-01-03T13:00:00Z", "duration_sec": 900, "status": "failure", "probes": {"ccache": {"hits": 0, "misses": 50}}}
+{"project": "backend", "timestamp": "2024-01-03T14:00:00Z", "duration_sec": 2, "status": "success", "probes": {"ccache": {"hits": 0, "misses": 0}}}
+{"project": "backend", "timestamp": "2024-01-03T15:00:00Z", "duration_sec": 30, "status": "success"}
+{"project": "rust", "timestamp": "2024-01-04T10:00:00Z", "duration_sec": 120, "status": "success", "probes": {"sccache": {"hits": 30, "misses": 70}, "disk": {"used": 10}}}
+`
+	got, err := GenerateCacheReport(singleLog(strings.NewReader(input)), ReportOptions{})
+	if err != nil {
+		t.Fatalf("GenerateCacheReport() erro: %v", err)
+	}
+	want := &CacheReport{
+		Entries: []CacheSummary{
+			{
+				Project: "backend", Probe: "ccache", Builds: 3, Hits: 205, Misses: 95, HitRate: 205.0 / 300,
+				Buckets: []CacheBucket{
+					{Label: "0-25%", Count: 1, AvgDurationSec: 600},
+					{Label: "25-50%"},
+					{Label: "50-75%"},
+					{Label: "75-100%", Count: 2, AvgDurationSec: 50},
+				},
+			},
+			{
+				Project: "rust", Probe: "sccache", Builds: 1, Hits: 30, Misses: 70, HitRate: 0.3,
+				Buckets: []CacheBucket{
+					{Label: "0-25%"},
+					{Label: "25-50%", Count: 1, AvgDurationSec: 120},
+					{Label: "50-75%"},
+					{Label: "75-100%"},
+				},
+			},
+		},
+		WithoutCache: 2,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GenerateCacheReport() = \n%+v, \nwant \n%+v", got, want)
+	}
+}
@@ -40,17 +40,63 @@ func BuildMetricCSVRow(m BuildMetric) []string {
 	}
 }
 
-// ExportCSVFromJSONL converts a JSONL stream to CSV.
+// HostCSVHeader returns the host profile columns appended by ExportCSVWithHosts.
+func HostCSVHeader() []string {
+	return []string{
+		"host_profile",
+		"cpu_model",
+		"physical_cores",
+		"logical_cores",
+		"max_freq_mhz",
+		"mem_total_bytes",
+		"kernel",
+		"fs_type",
+	}
+}
+
+// HostCSVRow returns the host profile columns for a record. Profiles missing
+// from hosts (or records without one) leave every column but the id empty.
+func HostCSVRow(m BuildMetric, hosts map[string]HostProfile) []string {
+	p, ok := hosts[m.HostProfile]
+	if !ok || m.HostProfile == "" {
+		return []string{m.HostProfile, "", "", "", "", "", "", ""}
+	}
+	return []string{
+		m.HostProfile,
+		p.CPUModel,
+		strconv.Itoa(p.PhysicalCores),
+		strconv.Itoa(p.LogicalCores),
+		strconv.Itoa(p.MaxFreqMHz),
+		strconv.FormatUint(p.MemTotalBytes, 10),
+		p.Kernel,
+		p.FSType,
+	}
+}
+
+// ExportCSVFromJSONL converts JSONL logs to CSV, in the given order.
 //
 // The CSV header is always written as the first row.
-func ExportCSVFromJSONL(r io.Reader, w io.Writer, strict bool) (ScanResult, error) {
+func ExportCSVFromJSONL(logs []LogFile, w io.Writer, strict bool) (ScanResult, error) {
+	return exportCSV(logs, w, strict, CSVHeader(), BuildMetricCSVRow)
+}
+
+// ExportCSVWithHosts is ExportCSVFromJSONL joined with the host profiles
+// (see LoadHostProfiles): the HostCSVHeader columns are appended to each row.
+func ExportCSVWithHosts(logs []LogFile, w io.Writer, strict bool, hosts map[string]HostProfile) (ScanResult, error) {
+	header := append(CSVHeader(), HostCSVHeader()...)
+	return exportCSV(logs, w, strict, header, func(m BuildMetric) []string {
+		return append(BuildMetricCSVRow(m), HostCSVRow(m, hosts)...)
+	})
+}
+
+func exportCSV(logs []LogFile, w io.Writer, strict bool, header []string, row func(BuildMetric) []string) (ScanResult, error) {
 	csvw := csv.NewWriter(w)
-	if err := csvw.Write(CSVHeader()); err != nil {
+	if err := csvw.Write(header); err != nil {
 		return ScanResult{}, err
 	}
 
-	res, err := ScanJSONL(r, strict, func(m BuildMetric) error {
-		return csvw.Write(BuildMetricCSVRow(m))
+	res, err := ScanLogs(logs, ScanOptions{Strict: strict, Workers: 1}, func(m BuildMetric) error {
+		return csvw.Write(row(m))
 	})
 	csvw.Flush()
 	if err != nil {
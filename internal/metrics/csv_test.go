@@ -1,6 +1,7 @@
 package metrics_test
 
 import (
+	"bytes"
 	"dev-metrics/internal/metrics"
 	"io"
 	"reflect"
@@ -130,7 +131,7 @@ func TestExportCSVFromJSONL(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			writer := &mockWriter{}
-			got, gotErr := metrics.ExportCSVFromJSONL(tt.r, writer, tt.strict)
+			got, gotErr := metrics.ExportCSVFromJSONL([]metrics.LogFile{{Name: "log.jsonl", R: tt.r}}, writer, tt.strict)
 			if gotErr != nil {
 				if !tt.wantErr {
 					t.Errorf("ExportCSVFromJSONL() failed: %v", gotErr)
@@ -223,3 +224,28 @@ func equivalentStringSlices(t *testing.T, a, b []string) bool {
 func getCSVHeaderString() string {
 	return "timestamp,user,hostname,os,project,branch,commit,command,duration_sec,returncode,cpus,status"
 }
+
+func TestExportCSVWithHosts(t *testing.T) {
+	input := `{"timestamp":"2024-01-01T00:00:00Z","project":"p","duration_sec":1,"host_profile":"h1"}
+{"timestamp":"2024-01-02T00:00:00Z","project":"p","duration_sec":2,"host_profile":"gone"}
+{"timestamp":"2024-01-03T00:00:00Z","project":"p","duration_sec":3}
+`
+	hosts := map[string]metrics.HostProfile{
+		"h1": {ID: "h1", CPUModel: "i7", PhysicalCores: 4, LogicalCores: 8, MaxFreqMHz: 4800, MemTotalBytes: 1024, Kernel: "6.8", FSType: "ext4"},
+	}
+	var out bytes.Buffer
+	res, err := metrics.ExportCSVWithHosts([]metrics.LogFile{{Name: "log.jsonl", R: strings.NewReader(input)}}, &out, true, hosts)
+	if err != nil || res.Processed != 3 {
+		t.Fatalf("ExportCSVWithHosts() = %+v, %v", res, err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if !strings.HasSuffix(lines[0], ",status,host_profile,cpu_model,physical_cores,logical_cores,max_freq_mhz,mem_total_bytes,kernel,fs_type") {
+		t.Errorf("cabeçalho = %q", lines[0])
+	}
+	wantSuffixes := []string{",h1,i7,4,8,4800,1024,6.8,ext4", ",gone,,,,,,,", ",,,,,,,,"}
+	for i, want := range wantSuffixes {
+		if !strings.HasSuffix(lines[i+1], want) {
+			t.Errorf("linha %d = %q, want sufixo %q", i+1, lines[i+1], want)
+		}
+	}
+}
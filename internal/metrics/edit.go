@@ -0,0 +1,142 @@
+package metrics
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"regexp"
+	"slices"
+	"time"
+)
+
+// RecordFilter seleciona registros do log. Critérios vazios não filtram e
+// os demais precisam ser todos satisfeitos.
+type RecordFilter struct {
+	Before       time.Time      // Registros com timestamp anterior a este instante
+	Project      string         // Nome exato do projeto
+	CommandRegex *regexp.Regexp // Expressão aplicada ao campo command
+	Status       string         // success, failure ou interrupted
+	IDs          []string       // IDs exatos das execuções
+}
+
+// IsEmpty indica se o filtro não possui nenhum critério (selecionaria tudo).
+func (f RecordFilter) IsEmpty() bool {
+	return f.Before.IsZero() && f.Project == "" && f.CommandRegex == nil && f.Status == "" && len(f.IDs) == 0
+}
+
+// Match indica se o registro satisfaz todos os critérios do filtro.
+func (f RecordFilter) Match(m BuildMetric) bool {
+	if !f.Before.IsZero() {
+		t, err := time.Parse(time.RFC3339, m.Timestamp)
+		if err != nil || !t.Before(f.Before) {
+			return false
+		}
+	}
+	if f.Project != "" && m.Project != f.Project {
+		return false
+	}
+	if f.CommandRegex != nil && !f.CommandRegex.MatchString(m.Command) {
+		return false
+	}
+	if f.Status != "" && m.Status != f.Status {
+		return false
+	}
+	if len(f.IDs) > 0 && !slices.Contains(f.IDs, m.ID) {
+		return false
+	}
+	return true
+}
+
+// RecordEdit decide o destino de um registro. O registro bruto pode ser
+// alterado no próprio mapa; keep=false remove o registro e changed=true indica
+// que ele foi modificado.
+type RecordEdit func(m BuildMetric, rec RawRecord) (keep bool, changed bool)
+
+// RecordChange descreve um registro removido ou modificado por EditJSONL.
+type RecordChange struct {
+	Line    int
+	Before  BuildMetric
+	Removed bool
+}
+
+// EditResult resume uma execução de EditJSONL.
+type EditResult struct {
+	Kept     int
+	Removed  int
+	Modified int
+	Invalid  int // Linhas inválidas, copiadas sem alteração
+	Changes  []RecordChange
+}
+
+// EditJSONL aplica edit a cada registro de r e escreve o log resultante em w.
+// Registros não modificados e linhas inválidas são copiados byte a byte, e
+// registros modificados preservam campos desconhecidos.
+// Com w nil nada é escrito, o que permite simular a edição (dry-run).
+func EditJSONL(r io.Reader, w io.Writer, edit RecordEdit) (EditResult, error) {
+	var res EditResult
+	if w == nil {
+		w = io.Discard
+	}
+
+	scanner := bufio.NewScanner(r)
+	buf := make([]byte, 0, 64*1024)
+	scanner.Buffer(buf, maxLineSize)
+	bw := bufio.NewWriter(w)
+
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		out := line
+		m, err := decodeLine(line)
+		var rec RawRecord
+		if err == nil {
+			err = json.Unmarshal(line, &rec)
+		}
+		if err != nil {
+			res.Invalid++
+		} else {
+			keep, changed := edit(m, rec)
+			switch {
+			case !keep:
+				res.Removed++
+				res.Changes = append(res.Changes, RecordChange{Line: lineNo, Before: m, Removed: true})
+				continue
+			case changed:
+				if out, err = json.Marshal(rec); err != nil {
+					return res, err
+				}
+				res.Modified++
+				res.Changes = append(res.Changes, RecordChange{Line: lineNo, Before: m})
+			default:
+				res.Kept++
+			}
+		}
+
+		if _, err := bw.Write(out); err != nil {
+			return res, err
+		}
+		if err := bw.WriteByte('\n'); err != nil {
+			return res, err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return res, err
+	}
+	return res, bw.Flush()
+}
+
+// SetRecordField grava value (serializado em JSON) no campo key do registro bruto.
+func SetRecordField(rec RawRecord, key string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	rec[key] = data
+	return nil
+}
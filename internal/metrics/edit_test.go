@@ -0,0 +1,80 @@
+package metrics_test
+
+import (
+	"bytes"
+	"dev-metrics/internal/metrics"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecordFilter_Match(t *testing.T) {
+	m := metrics.BuildMetric{
+		ID:        "01JABC",
+		Timestamp: "2024-01-02T10:00:00Z",
+		Project:   "backend",
+		Command:   "[sleep 600]",
+		Status:    "interrupted",
+	}
+	tests := []struct {
+		name   string
+		filter metrics.RecordFilter
+		want   bool
+	}{
+		{"Before casa", metrics.RecordFilter{Before: time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)}, true},
+		{"Before não casa", metrics.RecordFilter{Before: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)}, false},
+		{"Projeto", metrics.RecordFilter{Project: "backend"}, true},
+		{"Projeto diferente", metrics.RecordFilter{Project: "frontend"}, false},
+		{"Regex", metrics.RecordFilter{CommandRegex: regexp.MustCompile(`^\[sleep `)}, true},
+		{"Status", metrics.RecordFilter{Status: "success"}, false},
+		{"IDs", metrics.RecordFilter{IDs: []string{"x", "01JABC"}}, true},
+		{"Combinação precisa casar tudo", metrics.RecordFilter{Project: "backend", Status: "success"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Match(m); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if !(metrics.RecordFilter{}).IsEmpty() {
+		t.Error("filtro vazio deveria ser IsEmpty()")
+	}
+}
+
+func TestEditJSONL(t *testing.T) {
+	input := `{"project":"old","timestamp":"2024-01-02T10:00:00Z","custom":[1,2]}
+{"project":"other","timestamp":"2024-01-02T11:00:00Z"}
+INVALID
+{"project":"drop","timestamp":"2024-01-02T12:00:00Z"}
+`
+	var out bytes.Buffer
+	res, err := metrics.EditJSONL(strings.NewReader(input), &out, func(m metrics.BuildMetric, rec metrics.RawRecord) (bool, bool) {
+		switch m.Project {
+		case "drop":
+			return false, false
+		case "old":
+			metrics.SetRecordField(rec, "project", "new")
+			return true, true
+		}
+		return true, false
+	})
+	if err != nil {
+		t.Fatalf("EditJSONL() erro: %v", err)
+	}
+	if res.Kept != 1 || res.Modified != 1 || res.Removed != 1 || res.Invalid != 1 {
+		t.Errorf("EditJSONL() = %+v", res)
+	}
+	if len(res.Changes) != 2 || res.Changes[0].Line != 1 || res.Changes[1].Line != 4 || !res.Changes[1].Removed {
+		t.Errorf("Changes = %+v", res.Changes)
+	}
+
+	want := `{"custom":[1,2],"project":"new","timestamp":"2024-01-02T10:00:00Z"}
+{"project":"other","timestamp":"2024-01-02T11:00:00Z"}
+INVALID
+`
+	if out.String() != want {
+		t.Errorf("saída = %q, want %q", out.String(), want)
+	}
+}
@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"time"
+
+	"dev-metrics/internal/stats"
+)
+
+// Estimate é a duração esperada de um comando, a partir das execuções recentes.
+type Estimate struct {
+	Runs   int // Execuções consideradas
+	Median float64
+	P90    float64
+}
+
+// SlowMargin é quanto acima da mediana uma execução precisa ficar, além de
+// passar do p90, para ser considerada lenta. Evita avisos quando o histórico
+// é muito estável e o p90 fica colado na mediana.
+const SlowMargin = 1.1
+
+// Slow informa se uma execução de durationSec foi mais lenta que o esperado.
+func (e Estimate) Slow(durationSec float64) bool {
+	return e.Runs > 0 && durationSec > e.P90 && durationSec > e.Median*SlowMargin
+}
+
+// estimateBlock é quanto do fim do log EstimateDuration lê de cada vez.
+var estimateBlock int64 = 256 << 10
+
+// EstimateDuration calcula a mediana e o p90 das últimas limit execuções com
+// sucesso do mesmo projeto e comando (ver BuildMetric.CommandFingerprint).
+// Iterações de benchmark e execuções sob contenção ficam de fora, como nos
+// relatórios. Sem histórico, retorna Runs zero.
+//
+// O log de size bytes é lido de trás para frente, em blocos, até reunir limit
+// execuções, para que o custo não cresça com o histórico; só com limit zero
+// ele é lido inteiro. Registros gravados fora de ordem (--at, por exemplo)
+// contam pela posição no log.
+func EstimateDuration(r io.ReaderAt, size int64, project, fingerprint string, limit int) (Estimate, error) {
+	type run struct {
+		at       time.Time
+		duration float64
+	}
+	var runs []run
+	collect := func(m BuildMetric) error {
+		if m.Status != "success" || m.Project != project || m.CommandFingerprint() != fingerprint {
+			return nil
+		}
+		if !BenchmarksExclude.Keep(m) || m.Contended() {
+			return nil
+		}
+		if t, err := time.Parse(time.RFC3339, m.Timestamp); err == nil {
+			runs = append(runs, run{t, m.DurationSec})
+		}
+		return nil
+	}
+
+	for end, block := size, estimateBlock; end > 0 && (limit <= 0 || len(runs) < limit); {
+		start := end - block
+		if start < 0 {
+			start = 0
+		}
+		buf := make([]byte, end-start)
+		if _, err := r.ReadAt(buf, start); err != nil && err != io.EOF {
+			return Estimate{}, err
+		}
+		if start > 0 {
+			// A primeira linha do bloco pode estar cortada: fica para o próximo
+			i := bytes.IndexByte(buf, '\n')
+			if i < 0 || i == len(buf)-1 {
+				block *= 2 // Nenhuma linha inteira no bloco
+				continue
+			}
+			buf = buf[i+1:]
+			start += int64(i) + 1
+		}
+		if _, err := ScanJSONL(bytes.NewReader(buf), false, collect); err != nil {
+			return Estimate{}, err
+		}
+		end = start
+	}
+
+	sort.Slice(runs, func(i, j int) bool { return runs[i].at.After(runs[j].at) })
+	if limit > 0 && len(runs) > limit {
+		runs = runs[:limit]
+	}
+	if len(runs) == 0 {
+		return Estimate{}, nil
+	}
+	durations := make([]float64, len(runs))
+	for i, r := range runs {
+		durations[i] = r.duration
+	}
+	summary := stats.Summarize(durations)
+	return Estimate{Runs: summary.Count, Median: summary.Median, P90: summary.P90}, nil
+}
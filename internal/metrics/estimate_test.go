@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestEstimateDuration(t *testing.T) {
+	fp := BuildMetric{Args: []string{"make", "-j8"}}.CommandFingerprint()
+	input := `
+{"project": "backend", "timestamp": "2024-01-01T10:00:00Z", "duration_sec": 500, "status": "success", "args": ["make", "-j8"]}
+{"project": "backend", "timestamp": "2024-01-03T10:00:00Z", "duration_sec": 100, "status": "success", "args": ["make", "-j8"]}
+{"project": "backend", "timestamp": "2024-01-04T10:00:00Z", "duration_sec": 120, "status": "success", "args": ["make", "-j8"]}
+{"project": "backend", "timestamp": "2024-01-02T10:00:00-03:00", "duration_sec": 110, "status": "success", "args": ["make", "-j8"]}
+{"project": "backend", "timestamp": "2024-01-05T10:00:00Z", "duration_sec": 5, "status": "failure", "args": ["make", "-j8"]}
+{"project": "backend", "timestamp": "2024-01-05T11:00:00Z", "duration_sec": 900, "status": "success", "args": ["make"]}
+{"project": "frontend", "timestamp": "2024-01-05T12:00:00Z", "duration_sec": 900, "status": "success", "args": ["make", "-j8"]}
+{"project": "backend", "timestamp": "2024-01-06T10:00:00Z", "duration_sec": 40, "status": "success", "args": ["make", "-j8"], "benchmark_id": "B1", "iteration": 1}
+{"project": "backend", "timestamp": "2024-01-06T11:00:00Z", "duration_sec": 900, "status": "success", "args": ["make", "-j8"], "contention": {"concurrent_runs": 2}}
+`
+	got, err := EstimateDuration(strings.NewReader(input), int64(len(input)), "backend", fp, 3)
+	if err != nil {
+		t.Fatalf("EstimateDuration() erro: %v", err)
+	}
+	// As 3 mais recentes: 120, 100 e 110 (a de 500 fica de fora, assim como o
+	// benchmark e a execução sob contenção)
+	want := Estimate{Runs: 3, Median: 110, P90: 118}
+	if got != want {
+		t.Errorf("EstimateDuration() = %+v, want %+v", got, want)
+	}
+	if !got.Slow(130) || got.Slow(115) {
+		t.Errorf("Slow() deve valer só acima do p90 (%v)", got.P90)
+	}
+	if stable := (Estimate{Runs: 5, Median: 100, P90: 101}); stable.Slow(105) || !stable.Slow(111) {
+		t.Errorf("Slow() com histórico estável deve exigir %v vezes a mediana", SlowMargin)
+	}
+
+	none, err := EstimateDuration(strings.NewReader(input), int64(len(input)), "backend", "outro", 3)
+	if err != nil || none.Runs != 0 || none.Slow(1000) {
+		t.Errorf("EstimateDuration() sem histórico = %+v, %v", none, err)
+	}
+}
+
+// offsetReader registra o menor offset lido.
+type offsetReader struct {
+	*strings.Reader
+	min int64
+}
+
+func (r *offsetReader) ReadAt(p []byte, off int64) (int, error) {
+	if off < r.min {
+		r.min = off
+	}
+	return r.Reader.ReadAt(p, off)
+}
+
+func TestEstimateDuration_ReadsOnlyTheTail(t *testing.T) {
+	defer func(b int64) { estimateBlock = b }(estimateBlock)
+	estimateBlock = 64 // Menor que uma linha: força o bloco a crescer
+
+	fp := BuildMetric{Args: []string{"make"}}.CommandFingerprint()
+	line := func(day, dur int) string {
+		return fmt.Sprintf(`{"project": "p", "timestamp": "2024-01-%02dT10:00:00Z", "duration_sec": %d, "status": "success", "args": ["make"]}`+"\n", day, dur)
+	}
+	var b strings.Builder
+	for day := 1; day <= 20; day++ {
+		b.WriteString(line(day, 900))
+	}
+	b.WriteString("INVALID\n")
+	for day, dur := range []int{100, 110, 120} {
+		b.WriteString(line(21+day, dur))
+	}
+	input := b.String()
+
+	r := &offsetReader{Reader: strings.NewReader(input), min: int64(len(input))}
+	got, err := EstimateDuration(r, int64(len(input)), "p", fp, 3)
+	if err != nil {
+		t.Fatalf("EstimateDuration() erro: %v", err)
+	}
+	if want := (Estimate{Runs: 3, Median: 110, P90: 118}); got != want {
+		t.Errorf("EstimateDuration() = %+v, want %+v", got, want)
+	}
+	if r.min < int64(len(input)/2) {
+		t.Errorf("EstimateDuration() leu a partir do byte %d de %d; esperava só o fim do log", r.min, len(input))
+	}
+
+	all, err := EstimateDuration(strings.NewReader(input), int64(len(input)), "p", fp, 0)
+	if err != nil || all.Runs != 23 {
+		t.Errorf("EstimateDuration() sem limite = %+v, %v; want 23 execuções", all, err)
+	}
+}
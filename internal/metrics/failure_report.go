@@ -0,0 +1,111 @@
+package metrics
+
+import (
+	"sort"
+	"time"
+)
+
+// UnclassifiedFailure é a classe usada para falhas sem failure_class (registros antigos).
+const UnclassifiedFailure = "unknown"
+
+// FailureSummary agrega as falhas de uma classe (em um projeto ou no total).
+type FailureSummary struct {
+	Project string // Vazio nos totais por classe
+	Class   string
+	Count   int
+	LostSec float64 // Soma da duração das execuções que falharam
+}
+
+// FailureReport resume o tempo perdido com falhas por classe e projeto.
+type FailureReport struct {
+	Entries []FailureSummary // Ordenadas por projeto e, dentro dele, pelo maior tempo perdido
+	ByClass []FailureSummary // Totais por classe, pelo maior tempo perdido
+	Runs    int              // Execuções no período
+	Failed  int
+	LostSec float64
+	ReportOptions
+
+	Skipped           int // Linhas JSON inválidas ignoradas
+	InvalidTimestamps int // Registros ignorados por timestamp inválido
+	Contended         int // Execuções ignoradas por contenção (com ExcludeContended)
+}
+
+// GenerateFailureReport agrega as execuções com status failure por projeto e classe.
+func GenerateFailureReport(logs []LogFile, opts ReportOptions) (*FailureReport, error) {
+	report := &FailureReport{ReportOptions: opts}
+	type key struct{ project, class string }
+	entries := make(map[key]*FailureSummary)
+	classes := make(map[string]*FailureSummary)
+
+	scanRes, err := ScanLogs(logs, ScanOptions{Unordered: true}, func(m BuildMetric) error {
+		t, err := time.Parse(time.RFC3339, m.Timestamp)
+		if err != nil {
+			report.InvalidTimestamps++
+			return nil
+		}
+		if !opts.Since.IsZero() && t.Before(opts.Since) {
+			return nil
+		}
+		if !opts.Until.IsZero() && t.After(opts.Until) {
+			return nil
+		}
+		if !opts.Benchmarks.Keep(m) {
+			return nil
+		}
+		if m.ParentID != "" && !opts.IncludeNested {
+			return nil
+		}
+		if opts.ExcludeContended && m.Contended() {
+			report.Contended++
+			return nil
+		}
+		report.Runs++
+		if m.Status != "failure" {
+			return nil
+		}
+
+		class := m.FailureClass
+		if class == "" {
+			class = UnclassifiedFailure
+		}
+		k := key{m.Project, class}
+		if entries[k] == nil {
+			entries[k] = &FailureSummary{Project: m.Project, Class: class}
+		}
+		if classes[class] == nil {
+			classes[class] = &FailureSummary{Class: class}
+		}
+		for _, s := range []*FailureSummary{entries[k], classes[class]} {
+			s.Count++
+			s.LostSec += m.DurationSec
+		}
+		report.Failed++
+		report.LostSec += m.DurationSec
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	report.Skipped = scanRes.Skipped
+
+	for _, s := range entries {
+		report.Entries = append(report.Entries, *s)
+	}
+	for _, s := range classes {
+		report.ByClass = append(report.ByClass, *s)
+	}
+	byLost := func(list []FailureSummary) func(i, j int) bool {
+		return func(i, j int) bool {
+			if list[i].Project != list[j].Project {
+				return list[i].Project < list[j].Project
+			}
+			if list[i].LostSec != list[j].LostSec {
+				return list[i].LostSec > list[j].LostSec
+			}
+			return list[i].Class < list[j].Class
+		}
+	}
+	sort.Slice(report.Entries, byLost(report.Entries))
+	sort.Slice(report.ByClass, byLost(report.ByClass))
+	return report, nil
+}
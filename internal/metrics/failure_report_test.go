@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGenerateFailureReport(t *testing.T) {
+	input := `
+{"project": "backend", "timestamp": "2024-01-03T10:00:00Z", "duration_sec": 100, "status": "failure", "failure_class": "compiler_error"}
+{"project": "backend", "timestamp": "2024-01-03T11:00:00Z", "duration_sec": 300, "status": "failure", "failure_class": "test_failure"}
+{"project": "backend", "timestamp": "2024-01-03T12:00:00Z", "duration_sec": 50, "status": "failure", "failure_class": "compiler_error"}
+{"project": "backend", "timestamp": "2024-01-03T13:00:00Z", "duration_sec": 500, "status": "success"}
+{"project": "frontend", "timestamp": "2024-01-04T10:00:00Z", "duration_sec": 20, "status": "failure"}
+{"project": "frontend", "timestamp": "2024-01-04T11:00:00Z", "duration_sec": 5, "status": "interrupted"}
+`
+	got, err := GenerateFailureReport(singleLog(strings.NewReader(input)), ReportOptions{})
+	if err != nil {
+		t.Fatalf("GenerateFailureReport() erro: %v", err)
+	}
+	want := &FailureReport{
+		Entries: []FailureSummary{
+			{Project: "backend", Class: "test_failure", Count: 1, LostSec: 300},
+			{Project: "backend", Class: "compiler_error", Count: 2, LostSec: 150},
+			{Project: "frontend", Class: UnclassifiedFailure, Count: 1, LostSec: 20},
+		},
+		ByClass: []FailureSummary{
+			{Class: "test_failure", Count: 1, LostSec: 300},
+			{Class: "compiler_error", Count: 2, LostSec: 150},
+			{Class: UnclassifiedFailure, Count: 1, LostSec: 20},
+		},
+		Runs:    6,
+		Failed:  4,
+		LostSec: 470,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GenerateFailureReport() = \n%+v, \nwant \n%+v", got, want)
+	}
+}
@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"io"
+	"sort"
+	"strings"
+)
+
+// FindByID retorna os registros cujo id começa com prefix. Um registro com o
+// id exatamente igual a prefix tem prioridade e é retornado sozinho.
+func FindByID(r io.Reader, prefix string) ([]BuildMetric, error) {
+	var found []BuildMetric
+	var exact *BuildMetric
+	_, err := ScanJSONL(r, false, func(m BuildMetric) error {
+		if m.ID == "" || !strings.HasPrefix(m.ID, prefix) {
+			return nil
+		}
+		if m.ID == prefix && exact == nil {
+			exact = &m
+		}
+		found = append(found, m)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if exact != nil {
+		return []BuildMetric{*exact}, nil
+	}
+	return found, nil
+}
+
+// RunNode é uma execução e as execuções aninhadas nela (bmt run dentro de bmt run).
+type RunNode struct {
+	Metric   BuildMetric
+	Children []*RunNode // Em ordem de início
+}
+
+// FindTree monta a árvore das execuções aninhadas na execução id (exato).
+// Retorna nil se id não estiver no log.
+func FindTree(r io.Reader, id string) (*RunNode, error) {
+	var root *RunNode
+	children := make(map[string][]BuildMetric)
+	_, err := ScanJSONL(r, false, func(m BuildMetric) error {
+		if m.ID == id && root == nil {
+			root = &RunNode{Metric: m}
+		}
+		if m.ParentID != "" {
+			children[m.ParentID] = append(children[m.ParentID], m)
+		}
+		return nil
+	})
+	if err != nil || root == nil {
+		return nil, err
+	}
+	seen := map[string]bool{id: true}
+	var attach func(n *RunNode)
+	attach = func(n *RunNode) {
+		kids := children[n.Metric.ID]
+		// O ID (ULID) é gerado no início da execução, então ordena por início
+		sort.Slice(kids, func(i, j int) bool { return kids[i].ID < kids[j].ID })
+		for _, m := range kids {
+			if seen[m.ID] { // Registros duplicados ou ciclos num log editado
+				continue
+			}
+			seen[m.ID] = true
+			child := &RunNode{Metric: m}
+			attach(child)
+			n.Children = append(n.Children, child)
+		}
+	}
+	attach(root)
+	return root, nil
+}
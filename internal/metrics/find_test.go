@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFindTree(t *testing.T) {
+	log := `{"id":"01C","parent_id":"01A","depth":1,"timestamp":"2024-01-01T00:09:00Z","duration_sec":120,"command":"[ctest]"}
+{"id":"01D","parent_id":"01C","depth":2,"timestamp":"2024-01-01T00:08:00Z","duration_sec":60,"command":"[./slow_test]"}
+{"id":"01B","parent_id":"01A","depth":1,"timestamp":"2024-01-01T00:05:00Z","duration_sec":240,"command":"[cmake --build .]"}
+{"id":"01A","timestamp":"2024-01-01T00:10:00Z","duration_sec":600,"command":"[make all]"}
+{"id":"01E","parent_id":"01X","depth":1,"timestamp":"2024-01-01T00:11:00Z","duration_sec":5,"command":"[outro]"}
+`
+	root, err := FindTree(strings.NewReader(log), "01A")
+	if err != nil || root == nil {
+		t.Fatalf("FindTree() = %v, %v", root, err)
+	}
+	if len(root.Children) != 2 || root.Children[0].Metric.ID != "01B" || root.Children[1].Metric.ID != "01C" {
+		t.Fatalf("filhos de 01A = %+v, want 01B e 01C, por início", root.Children)
+	}
+	if c := root.Children[1]; len(c.Children) != 1 || c.Children[0].Metric.ID != "01D" {
+		t.Errorf("filhos de 01C = %+v, want 01D", c.Children)
+	}
+
+	sub, _ := FindTree(strings.NewReader(log), "01C")
+	if sub == nil || len(sub.Children) != 1 {
+		t.Errorf("FindTree(01C) = %+v, want a subárvore", sub)
+	}
+	if missing, err := FindTree(strings.NewReader(log), "01Z"); missing != nil || err != nil {
+		t.Errorf("FindTree(01Z) = %+v, %v; want nil", missing, err)
+	}
+}
@@ -0,0 +1,120 @@
+package metrics
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CommandFingerprint identifica o comando executado (argv), para agrupar
+// execuções do mesmo comando. Registros antigos sem args usam o campo command.
+func (m BuildMetric) CommandFingerprint() string {
+	s := m.Command
+	if len(m.Args) > 0 {
+		s = strings.Join(m.Args, "\x00")
+	}
+	if m.Kind != "" {
+		// Um span "make" não é o comando make
+		s = m.Kind + "\x00" + s
+	}
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// FlakyCommand é um comando que falhou e passou no mesmo commit.
+type FlakyCommand struct {
+	Project     string
+	Commit      string
+	Command     string
+	Fingerprint string
+	Failures    int
+	Successes   int
+	WastedSec   float64 // Soma da duração das execuções que falharam
+	LastSeen    string  // Timestamp da execução mais recente
+}
+
+// FlakyReport lista os comandos instáveis pelo maior tempo perdido.
+type FlakyReport struct {
+	Entries   []FlakyCommand
+	WastedSec float64
+	ReportOptions
+
+	Skipped           int // Linhas JSON inválidas ignoradas
+	InvalidTimestamps int // Registros ignorados por timestamp inválido
+}
+
+// GenerateFlakyReport agrupa as execuções por projeto, commit e impressão
+// digital do comando e mantém os grupos com falhas e sucessos. Execuções
+// interrompidas e sem commit conhecido ficam de fora.
+func GenerateFlakyReport(logs []LogFile, opts ReportOptions) (*FlakyReport, error) {
+	report := &FlakyReport{ReportOptions: opts}
+	type key struct{ project, commit, fingerprint string }
+	groups := make(map[key]*FlakyCommand)
+
+	scanRes, err := ScanLogs(logs, ScanOptions{Unordered: true}, func(m BuildMetric) error {
+		t, err := time.Parse(time.RFC3339, m.Timestamp)
+		if err != nil {
+			report.InvalidTimestamps++
+			return nil
+		}
+		if !opts.Since.IsZero() && t.Before(opts.Since) {
+			return nil
+		}
+		if !opts.Until.IsZero() && t.After(opts.Until) {
+			return nil
+		}
+		if !opts.Benchmarks.Keep(m) {
+			return nil
+		}
+		if m.ParentID != "" && !opts.IncludeNested {
+			return nil
+		}
+		if m.Commit == "" || m.Commit == "unknown" || (m.Status != "success" && m.Status != "failure") {
+			return nil
+		}
+
+		k := key{m.Project, m.Commit, m.CommandFingerprint()}
+		g := groups[k]
+		if g == nil {
+			g = &FlakyCommand{Project: m.Project, Commit: m.Commit, Command: m.Command, Fingerprint: k.fingerprint}
+			groups[k] = g
+		}
+		if m.Status == "failure" {
+			g.Failures++
+			g.WastedSec += m.DurationSec
+		} else {
+			g.Successes++
+		}
+		if m.Timestamp > g.LastSeen {
+			g.LastSeen = m.Timestamp
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	report.Skipped = scanRes.Skipped
+
+	for _, g := range groups {
+		if g.Failures > 0 && g.Successes > 0 {
+			report.Entries = append(report.Entries, *g)
+			report.WastedSec += g.WastedSec
+		}
+	}
+	sort.Slice(report.Entries, func(i, j int) bool {
+		a, b := report.Entries[i], report.Entries[j]
+		if a.WastedSec != b.WastedSec {
+			return a.WastedSec > b.WastedSec
+		}
+		if a.Failures != b.Failures {
+			return a.Failures > b.Failures
+		}
+		if a.Project != b.Project {
+			return a.Project < b.Project
+		}
+		return a.Commit < b.Commit
+	})
+	return report, nil
+}
@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGenerateFlakyReport(t *testing.T) {
+	test := BuildMetric{Args: []string{"ctest", "-j8"}}.CommandFingerprint()
+	input := `
+{"project": "backend", "commit": "abc", "timestamp": "2024-01-03T10:00:00Z", "duration_sec": 100, "status": "failure", "command": "[ctest -j8]", "args": ["ctest", "-j8"], "attempt_group": "G1", "attempt": 1}
+{"project": "backend", "commit": "abc", "timestamp": "2024-01-03T10:02:00Z", "duration_sec": 90, "status": "success", "command": "[ctest -j8]", "args": ["ctest", "-j8"], "attempt_group": "G1", "attempt": 2}
+{"project": "backend", "commit": "abc", "timestamp": "2024-01-03T11:00:00Z", "duration_sec": 20, "status": "failure", "command": "[make]", "args": ["make"]}
+{"project": "backend", "commit": "def", "timestamp": "2024-01-03T12:00:00Z", "duration_sec": 20, "status": "success", "command": "[make]", "args": ["make"]}
+{"project": "frontend", "commit": "123", "timestamp": "2024-01-04T10:00:00Z", "duration_sec": 30, "status": "failure", "command": "[npm test]", "args": ["npm", "test"]}
+{"project": "frontend", "commit": "123", "timestamp": "2024-01-04T10:01:00Z", "duration_sec": 40, "status": "failure", "command": "[npm test]", "args": ["npm", "test"]}
+{"project": "frontend", "commit": "123", "timestamp": "2024-01-04T10:05:00Z", "duration_sec": 35, "status": "success", "command": "[npm test]", "args": ["npm", "test"]}
+{"project": "frontend", "commit": "123", "timestamp": "2024-01-04T11:00:00Z", "duration_sec": 5, "status": "interrupted", "command": "[npm run lint]", "args": ["npm", "run", "lint"]}
+{"project": "frontend", "commit": "123", "timestamp": "2024-01-04T11:01:00Z", "duration_sec": 5, "status": "success", "command": "[npm run lint]", "args": ["npm", "run", "lint"]}
+{"project": "tools", "commit": "unknown", "timestamp": "2024-01-04T12:00:00Z", "duration_sec": 5, "status": "failure", "command": "[go test]", "args": ["go", "test"]}
+{"project": "tools", "commit": "unknown", "timestamp": "2024-01-04T12:01:00Z", "duration_sec": 5, "status": "success", "command": "[go test]", "args": ["go", "test"]}
+`
+	got, err := GenerateFlakyReport(singleLog(strings.NewReader(input)), ReportOptions{})
+	if err != nil {
+		t.Fatalf("GenerateFlakyReport() erro: %v", err)
+	}
+	want := &FlakyReport{
+		Entries: []FlakyCommand{
+			{Project: "backend", Commit: "abc", Command: "[ctest -j8]", Fingerprint: test, Failures: 1, Successes: 1, WastedSec: 100, LastSeen: "2024-01-03T10:02:00Z"},
+			{Project: "frontend", Commit: "123", Command: "[npm test]", Fingerprint: BuildMetric{Args: []string{"npm", "test"}}.CommandFingerprint(),
+				Failures: 2, Successes: 1, WastedSec: 70, LastSeen: "2024-01-04T10:05:00Z"},
+		},
+		WastedSec: 170,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GenerateFlakyReport() = \n%+v, \nwant \n%+v", got, want)
+	}
+}
+
+func TestCommandFingerprint(t *testing.T) {
+	a := BuildMetric{Args: []string{"make", "-j8"}, Command: "[make -j8]"}
+	if a.CommandFingerprint() == (BuildMetric{Args: []string{"make -j8"}}).CommandFingerprint() {
+		t.Error("argv diferentes com o mesmo texto devem ter impressões digitais diferentes")
+	}
+	if len(a.CommandFingerprint()) != 12 {
+		t.Errorf("CommandFingerprint() = %q, want 12 caracteres", a.CommandFingerprint())
+	}
+	if (BuildMetric{Command: "[make]"}).CommandFingerprint() == (BuildMetric{Command: "[ninja]"}).CommandFingerprint() {
+		t.Error("registros sem args devem usar o campo command")
+	}
+	if (BuildMetric{Command: "make", Kind: KindSpan}).CommandFingerprint() == (BuildMetric{Args: []string{"make"}}).CommandFingerprint() {
+		t.Error("um span com o nome de um comando não deve se confundir com ele")
+	}
+}
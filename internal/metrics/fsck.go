@@ -0,0 +1,184 @@
+package metrics
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// FsckIssueKind identifica o tipo de problema encontrado por CheckJSONL.
+type FsckIssueKind string
+
+const (
+	IssueInvalidJSON      FsckIssueKind = "invalid_json"      // linha que não é JSON válido
+	IssueTornLine         FsckIssueKind = "torn_line"         // linha parcial ou registros colados
+	IssueBadTimestamp     FsckIssueKind = "bad_timestamp"     // timestamp ausente ou fora do RFC3339
+	IssueNegativeDuration FsckIssueKind = "negative_duration" // duration_sec < 0
+	IssueOutOfOrder       FsckIssueKind = "out_of_order"      // timestamp anterior ao de um registro anterior (exceto filhos dentro da execução pai)
+	IssueDuplicate        FsckIssueKind = "duplicate"         // linha idêntica a uma anterior
+)
+
+// FsckIssue descreve um problema em uma linha do log.
+type FsckIssue struct {
+	Line   int
+	Kind   FsckIssueKind
+	Detail string
+	Raw    string
+}
+
+// FsckReport resume a verificação de um log.
+type FsckReport struct {
+	Lines    int // Linhas não vazias lidas
+	Valid    int // Registros mantidos
+	Rejected int // Linhas rejeitadas
+	Issues   []FsckIssue
+}
+
+// CountByKind retorna a quantidade de problemas por tipo.
+func (r *FsckReport) CountByKind() map[FsckIssueKind]int {
+	counts := make(map[FsckIssueKind]int)
+	for _, is := range r.Issues {
+		counts[is.Kind]++
+	}
+	return counts
+}
+
+// FsckOptions define para onde CheckJSONL escreve o resultado do reparo.
+// Se ambos forem nil, o log é apenas verificado. Num reparo com RewriteLog,
+// Quarantine deve ser um buffer gravado só depois que a reescrita terminar:
+// se ela falhar, as linhas continuam no log original.
+type FsckOptions struct {
+	Clean      io.Writer // Recebe os registros válidos, ordenados por timestamp
+	Quarantine io.Writer // Recebe as linhas rejeitadas, exatamente como estavam, durante a leitura
+}
+
+type fsckRecord struct {
+	t   time.Time
+	raw []byte
+}
+
+// CheckJSONL verifica um log JSONL linha a linha, reportando linhas inválidas,
+// parciais, timestamps inválidos, durações negativas, registros fora de ordem e
+// duplicados. Os registros válidos são escritos sem alteração em opts.Clean;
+// registros fora de ordem não são rejeitados, apenas reordenados por timestamp.
+// Filhos gravados depois da execução pai (os alvos de bmt make) não estão fora
+// de ordem se terminaram dentro do intervalo dela.
+func CheckJSONL(r io.Reader, opts FsckOptions) (*FsckReport, error) {
+	report := &FsckReport{}
+	br := bufio.NewReader(r)
+	seen := make(map[[sha256.Size]byte]int)
+	runs := make(map[string][2]time.Time) // id -> início e fim da execução
+	var kept []fsckRecord
+	var latest time.Time
+	outOfOrder := false
+
+	reject := func(lineNo int, kind FsckIssueKind, detail string, raw []byte) error {
+		report.Issues = append(report.Issues, FsckIssue{Line: lineNo, Kind: kind, Detail: detail, Raw: string(raw)})
+		report.Rejected++
+		if opts.Quarantine != nil {
+			if _, err := opts.Quarantine.Write(append(raw, '\n')); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	lineNo := 0
+	for {
+		line, readErr := br.ReadBytes('\n')
+		if readErr != nil && readErr != io.EOF {
+			return report, readErr
+		}
+		if len(line) == 0 && readErr == io.EOF {
+			break
+		}
+		lineNo++
+		complete := bytes.HasSuffix(line, []byte{'\n'})
+		line = bytes.TrimRight(line, "\r\n")
+		trimmed := bytes.TrimSpace(line)
+		if len(trimmed) == 0 {
+			if readErr == io.EOF {
+				break
+			}
+			continue
+		}
+		report.Lines++
+
+		m, err := decodeLine(trimmed)
+		if err != nil {
+			kind, detail := classifyInvalidLine(trimmed, complete, err)
+			if err := reject(lineNo, kind, detail, line); err != nil {
+				return report, err
+			}
+		} else if t, terr := time.Parse(time.RFC3339, m.Timestamp); terr != nil {
+			if err := reject(lineNo, IssueBadTimestamp, fmt.Sprintf("timestamp inválido: %q", m.Timestamp), line); err != nil {
+				return report, err
+			}
+		} else if m.DurationSec < 0 {
+			if err := reject(lineNo, IssueNegativeDuration, fmt.Sprintf("duration_sec negativo: %v", m.DurationSec), line); err != nil {
+				return report, err
+			}
+		} else if first, dup := seen[sha256.Sum256(trimmed)]; dup {
+			if err := reject(lineNo, IssueDuplicate, fmt.Sprintf("duplicado da linha %d", first), line); err != nil {
+				return report, err
+			}
+		} else {
+			seen[sha256.Sum256(trimmed)] = lineNo
+			if m.ID != "" {
+				runs[m.ID] = [2]time.Time{t.Add(-time.Duration(m.DurationSec * float64(time.Second))), t}
+			}
+			parent, hasParent := runs[m.ParentID]
+			inParent := hasParent && m.ParentID != "" && !t.Before(parent[0]) && !t.After(parent[1])
+			if t.Before(latest) && !inParent {
+				outOfOrder = true
+				report.Issues = append(report.Issues, FsckIssue{
+					Line:   lineNo,
+					Kind:   IssueOutOfOrder,
+					Detail: fmt.Sprintf("%s é anterior a %s", t.Format(time.RFC3339), latest.Format(time.RFC3339)),
+					Raw:    string(line),
+				})
+			} else if t.After(latest) {
+				latest = t
+			}
+			report.Valid++
+			if opts.Clean != nil {
+				kept = append(kept, fsckRecord{t: t, raw: append([]byte(nil), trimmed...)})
+			}
+		}
+
+		if readErr == io.EOF {
+			break
+		}
+	}
+
+	if opts.Clean == nil {
+		return report, nil
+	}
+	if outOfOrder {
+		sort.SliceStable(kept, func(i, j int) bool { return kept[i].t.Before(kept[j].t) })
+	}
+	bw := bufio.NewWriter(opts.Clean)
+	for _, rec := range kept {
+		bw.Write(rec.raw)
+		bw.WriteByte('\n')
+	}
+	return report, bw.Flush()
+}
+
+// classifyInvalidLine diferencia linhas parciais (escrita interrompida ou
+// registros concorrentes colados) de JSON simplesmente inválido.
+func classifyInvalidLine(line []byte, complete bool, err error) (FsckIssueKind, string) {
+	switch {
+	case !complete:
+		return IssueTornLine, "última linha sem quebra de linha (escrita interrompida?)"
+	case bytes.Contains(line, []byte("}{")):
+		return IssueTornLine, "mais de um registro na mesma linha"
+	case (line[0] == '{') != (line[len(line)-1] == '}'):
+		return IssueTornLine, "registro parcial"
+	}
+	return IssueInvalidJSON, err.Error()
+}
@@ -0,0 +1,101 @@
+package metrics_test
+
+import (
+	"bytes"
+	"dev-metrics/internal/metrics"
+	"strings"
+	"testing"
+)
+
+func TestCheckJSONL(t *testing.T) {
+	input := strings.Join([]string{
+		`{"project":"A","timestamp":"2024-01-02T10:00:00Z","duration_sec":10}`,
+		`INVALID`,
+		`{"project":"A","timestamp":"2024-01-02T11:00:00Z"}{"project":"B","timestamp":"2024-01-02T11:00:01Z"}`,
+		`{"project":"A","timestamp":"ontem","duration_sec":1}`,
+		`{"project":"A","timestamp":"2024-01-02T12:00:00Z","duration_sec":-3}`,
+		`{"project":"A","timestamp":"2024-01-02T10:00:00Z","duration_sec":10}`,
+		``,
+		`{"project":"B","timestamp":"2024-01-01T09:00:00Z","duration_sec":5}`,
+		`{"project":"C","timestamp":"2024-01-03T0`,
+	}, "\n")
+
+	var clean, quarantine bytes.Buffer
+	report, err := metrics.CheckJSONL(strings.NewReader(input), metrics.FsckOptions{Clean: &clean, Quarantine: &quarantine})
+	if err != nil {
+		t.Fatalf("CheckJSONL() erro: %v", err)
+	}
+
+	wantIssues := []struct {
+		line int
+		kind metrics.FsckIssueKind
+	}{
+		{2, metrics.IssueInvalidJSON},
+		{3, metrics.IssueTornLine},
+		{4, metrics.IssueBadTimestamp},
+		{5, metrics.IssueNegativeDuration},
+		{6, metrics.IssueDuplicate},
+		{8, metrics.IssueOutOfOrder},
+		{9, metrics.IssueTornLine},
+	}
+	if len(report.Issues) != len(wantIssues) {
+		t.Fatalf("Issues = %+v, want %d problemas", report.Issues, len(wantIssues))
+	}
+	for i, want := range wantIssues {
+		got := report.Issues[i]
+		if got.Line != want.line || got.Kind != want.kind {
+			t.Errorf("Issues[%d] = linha %d %s, want linha %d %s", i, got.Line, got.Kind, want.line, want.kind)
+		}
+	}
+	if report.Lines != 8 || report.Valid != 2 || report.Rejected != 6 {
+		t.Errorf("report = %d linhas, %d válidas, %d rejeitadas", report.Lines, report.Valid, report.Rejected)
+	}
+
+	wantClean := `{"project":"B","timestamp":"2024-01-01T09:00:00Z","duration_sec":5}
+{"project":"A","timestamp":"2024-01-02T10:00:00Z","duration_sec":10}
+`
+	if clean.String() != wantClean {
+		t.Errorf("clean = %q, want %q (ordenado por timestamp)", clean.String(), wantClean)
+	}
+	if got := strings.Count(quarantine.String(), "\n"); got != 6 {
+		t.Errorf("quarentena com %d linhas, want 6", got)
+	}
+}
+
+func TestCheckJSONL_CleanLog(t *testing.T) {
+	input := `{"project":"A","timestamp":"2024-01-02T10:00:00Z","duration_sec":10}
+{"project":"A","timestamp":"2024-01-02T11:00:00Z","duration_sec":10}`
+	report, err := metrics.CheckJSONL(strings.NewReader(input), metrics.FsckOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Issues) != 0 || report.Valid != 2 {
+		t.Errorf("esperava log limpo, obtive %+v", report)
+	}
+}
+
+func TestCheckJSONL_ChildrenAfterParent(t *testing.T) {
+	// Os alvos de bmt make são gravados depois da execução pai, que termina por último
+	input := strings.Join([]string{
+		`{"id":"P","timestamp":"2024-01-02T10:00:00Z","duration_sec":60}`,
+		`{"id":"T1","parent_id":"P","kind":"target","timestamp":"2024-01-02T09:59:30Z","duration_sec":20}`,
+		`{"id":"T2","parent_id":"P","kind":"target","timestamp":"2024-01-02T09:58:00Z","duration_sec":5}`,
+		`{"id":"T3","parent_id":"X","kind":"target","timestamp":"2024-01-02T09:59:30Z","duration_sec":5}`,
+		`{"id":"Q","timestamp":"2024-01-02T10:05:00Z","duration_sec":10}`,
+	}, "\n")
+	report, err := metrics.CheckJSONL(strings.NewReader(input), metrics.FsckOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []int
+	for _, is := range report.Issues {
+		if is.Kind != metrics.IssueOutOfOrder {
+			t.Errorf("problema inesperado: %+v", is)
+		}
+		lines = append(lines, is.Line)
+	}
+	// T2 termina antes do início de P e T3 não tem pai no log
+	if len(lines) != 2 || lines[0] != 3 || lines[1] != 4 {
+		t.Errorf("fora de ordem nas linhas %v, want [3 4]", lines)
+	}
+}
@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+)
+
+// HostsFileName é o arquivo, ao lado do log, com os perfis das máquinas
+// referenciados por BuildMetric.HostProfile.
+const HostsFileName = "hosts.jsonl"
+
+// UnknownHostProfile agrupa registros sem perfil de máquina (anteriores à
+// coleta ou com perfil ausente de hosts.jsonl).
+const UnknownHostProfile = "sem perfil"
+
+// HostProfile descreve o hardware e o sistema de uma máquina. Campos que não
+// puderam ser lidos ficam zerados.
+type HostProfile struct {
+	ID            string `json:"id"` // Hash dos demais campos, exceto FirstSeen
+	FirstSeen     string `json:"first_seen,omitempty"`
+	Hostname      string `json:"hostname"`
+	OS            string `json:"os"`
+	Arch          string `json:"arch"`
+	CPUModel      string `json:"cpu_model,omitempty"`
+	PhysicalCores int    `json:"physical_cores,omitempty"`
+	LogicalCores  int    `json:"logical_cores"`
+	MaxFreqMHz    int    `json:"max_freq_mhz,omitempty"`
+	MemTotalBytes uint64 `json:"mem_total_bytes,omitempty"`
+	Kernel        string `json:"kernel,omitempty"`
+	FSType        string `json:"fs_type,omitempty"` // Sistema de arquivos do diretório do build
+}
+
+// Fingerprint calcula o ID do perfil: um hash curto de todos os campos exceto
+// ID e FirstSeen. Uma troca de kernel ou de disco gera um perfil novo.
+func (p HostProfile) Fingerprint() string {
+	p.ID, p.FirstSeen = "", ""
+	data, _ := json.Marshal(p)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// Label resume o hardware em uma linha, ex.: "Intel i7-1185G7 (4c/8t, 31.2 GB)".
+// Não inclui hostname, para agrupar máquinas iguais.
+func (p HostProfile) Label() string {
+	model := p.CPUModel
+	if model == "" {
+		model = p.OS + "/" + p.Arch
+	}
+	cores := fmt.Sprintf("%dt", p.LogicalCores)
+	if p.PhysicalCores > 0 {
+		cores = fmt.Sprintf("%dc/%s", p.PhysicalCores, cores)
+	}
+	if p.MemTotalBytes > 0 {
+		return fmt.Sprintf("%s (%s, %.1f GB)", model, cores, float64(p.MemTotalBytes)/(1<<30))
+	}
+	return fmt.Sprintf("%s (%s)", model, cores)
+}
+
+func hostKey(p HostProfile) string { return p.ID }
+
+// HostsPath retorna o caminho do registro de perfis de máquina do log.
+func HostsPath(logPath string) string {
+	return filepath.Join(filepath.Dir(logPath), HostsFileName)
+}
+
+// LoadHostProfiles lê os perfis registrados ao lado do log, por ID. A ausência
+// do arquivo não é erro.
+func LoadHostProfiles(logPath string) (map[string]HostProfile, error) {
+	return loadStore(HostsPath(logPath), hostKey)
+}
+
+// SaveHostProfile registra p ao lado do log se o ID ainda não for conhecido.
+func SaveHostProfile(logPath string, p HostProfile) error {
+	if p.ID == "" {
+		return fmt.Errorf("perfil de máquina sem id")
+	}
+	return appendToStore(HostsPath(logPath), p, hostKey)
+}
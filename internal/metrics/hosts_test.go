@@ -0,0 +1,29 @@
+package metrics
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSaveAndLoadHostProfiles(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "build.jsonl")
+	p := HostProfile{Hostname: "laptop", OS: "linux", Arch: "amd64", CPUModel: "i7", LogicalCores: 8, FSType: "ext4"}
+	p.ID = p.Fingerprint()
+
+	if err := SaveHostProfile(logPath, HostProfile{Hostname: "x"}); err == nil {
+		t.Error("SaveHostProfile() sem id deveria falhar")
+	}
+	for i := 0; i < 2; i++ {
+		if err := SaveHostProfile(logPath, p); err != nil {
+			t.Fatalf("SaveHostProfile() erro: %v", err)
+		}
+	}
+	got, err := LoadHostProfiles(logPath)
+	if err != nil {
+		t.Fatalf("LoadHostProfiles() erro: %v", err)
+	}
+	if want := map[string]HostProfile{p.ID: p}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadHostProfiles() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"crypto/rand"
+	"io"
+	"time"
+)
+
+// crockford é o alfabeto base32 de Crockford usado pelo formato ULID.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// NewID gera um identificador único e ordenável por tempo no formato ULID:
+// 48 bits de timestamp em milissegundos seguidos de 80 bits aleatórios,
+// codificados em 26 caracteres base32 (Crockford).
+func NewID() string {
+	return newIDAt(time.Now(), rand.Reader)
+}
+
+func newIDAt(t time.Time, entropy io.Reader) string {
+	var b [16]byte
+	ms := uint64(t.UnixMilli())
+	for i := 5; i >= 0; i-- {
+		b[i] = byte(ms)
+		ms >>= 8
+	}
+	if _, err := io.ReadFull(entropy, b[6:]); err != nil {
+		// crypto/rand não falha em plataformas suportadas; mantemos apenas o timestamp.
+		clear(b[6:])
+	}
+
+	// 128 bits -> 26 caracteres de 5 bits (os 2 bits mais altos do primeiro são zero).
+	var out [26]byte
+	var acc uint32
+	bits := 2 // alinhamento: 26*5 = 130 bits
+	j := 0
+	for _, v := range b {
+		acc = acc<<8 | uint32(v)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			out[j] = crockford[(acc>>bits)&31]
+			j++
+		}
+	}
+	return string(out[:])
+}
@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"bytes"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestNewIDAt(t *testing.T) {
+	// Vetor do exemplo da especificação ULID (timestamp 1469918176385).
+	ts := time.UnixMilli(1469918176385)
+	got := newIDAt(ts, bytes.NewReader(make([]byte, 10)))
+	if want := "01ARYZ6S410000000000000000"; got != want {
+		t.Errorf("newIDAt() = %q, want %q", got, want)
+	}
+
+	got = newIDAt(ts, bytes.NewReader(bytes.Repeat([]byte{0xff}, 10)))
+	if want := "01ARYZ6S41ZZZZZZZZZZZZZZZZ"; got != want {
+		t.Errorf("newIDAt() = %q, want %q", got, want)
+	}
+}
+
+func TestNewID_SortableAndUnique(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	var ids []string
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		id := newIDAt(base.Add(time.Duration(i)*time.Millisecond), bytes.NewReader(bytes.Repeat([]byte{byte(100 - i)}, 10)))
+		if len(id) != 26 {
+			t.Fatalf("len(%q) = %d, want 26", id, len(id))
+		}
+		ids = append(ids, id)
+	}
+	if !sort.StringsAreSorted(ids) {
+		t.Error("IDs gerados em sequência deveriam ser ordenáveis lexicograficamente")
+	}
+	for i := 0; i < 1000; i++ {
+		id := NewID()
+		if seen[id] {
+			t.Fatalf("ID duplicado: %s", id)
+		}
+		seen[id] = true
+	}
+}
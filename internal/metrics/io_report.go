@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"time"
+)
+
+// BuildIO é uma linha do relatório de I/O (uma execução).
+type BuildIO struct {
+	Timestamp   string
+	Project     string
+	Command     string
+	DurationSec float64
+	IOStats
+}
+
+// IOReport lista a atividade de I/O de cada execução, na ordem do log.
+type IOReport struct {
+	Builds []BuildIO
+	Total  IOStats
+	ReportOptions
+
+	WithoutIO         int // Registros sem dados de I/O (anteriores à coleta ou fora de Unix)
+	Skipped           int // Linhas JSON inválidas ignoradas
+	InvalidTimestamps int // Registros ignorados por timestamp inválido
+	Contended         int // Execuções ignoradas por contenção (com ExcludeContended)
+}
+
+// GenerateIOReport lista as execuções com dados de I/O no período de opts.
+func GenerateIOReport(logs []LogFile, opts ReportOptions) (*IOReport, error) {
+	report := &IOReport{ReportOptions: opts}
+	scanRes, err := ScanLogs(logs, ScanOptions{}, func(m BuildMetric) error {
+		t, err := time.Parse(time.RFC3339, m.Timestamp)
+		if err != nil {
+			report.InvalidTimestamps++
+			return nil
+		}
+		if !opts.Since.IsZero() && t.Before(opts.Since) {
+			return nil
+		}
+		if !opts.Until.IsZero() && t.After(opts.Until) {
+			return nil
+		}
+		if !opts.Benchmarks.Keep(m) {
+			return nil
+		}
+		if m.ParentID != "" && !opts.IncludeNested {
+			return nil
+		}
+		if opts.ExcludeContended && m.Contended() {
+			report.Contended++
+			return nil
+		}
+		if m.IO == nil {
+			report.WithoutIO++
+			return nil
+		}
+
+		report.Builds = append(report.Builds, BuildIO{
+			Timestamp:   m.Timestamp,
+			Project:     m.Project,
+			Command:     m.Command,
+			DurationSec: m.DurationSec,
+			IOStats:     *m.IO,
+		})
+		report.Total.add(*m.IO)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	report.Skipped = scanRes.Skipped
+	return report, nil
+}
+
+func (s *IOStats) add(o IOStats) {
+	s.ReadBytes += o.ReadBytes
+	s.WriteBytes += o.WriteBytes
+	s.ReadChars += o.ReadChars
+	s.WriteChars += o.WriteChars
+	s.ReadSyscalls += o.ReadSyscalls
+	s.WriteSyscalls += o.WriteSyscalls
+	s.BlkioWaitSec += o.BlkioWaitSec
+}
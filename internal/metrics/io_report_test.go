@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGenerateIOReport(t *testing.T) {
+	input := `
+{"project": "backend", "timestamp": "2024-01-03T10:00:00Z", "duration_sec": 10, "command": "make", "io": {"read_bytes": 4096, "write_bytes": 1024, "rchar": 10000, "blkio_wait_sec": 1.5}}
+{"project": "backend", "timestamp": "2024-01-04T10:00:00Z", "duration_sec": 5, "command": "make test"}
+{"project": "frontend", "timestamp": "2024-01-05T10:00:00Z", "duration_sec": 20, "command": "npm ci", "io": {"read_bytes": 100, "write_bytes": 200, "syscw": 3}}
+{"project": "frontend", "timestamp": "2024-02-05T10:00:00Z", "duration_sec": 20, "command": "npm ci", "io": {"read_bytes": 1, "write_bytes": 1}}
+{"project": "x", "timestamp": "ontem", "duration_sec": 1}
+`
+	opts := ReportOptions{Until: parseTime(t, "2024-02-01T00:00:00Z")}
+	got, err := GenerateIOReport(singleLog(strings.NewReader(input)), opts)
+	if err != nil {
+		t.Fatalf("GenerateIOReport() erro: %v", err)
+	}
+
+	want := &IOReport{
+		Builds: []BuildIO{
+			{Timestamp: "2024-01-03T10:00:00Z", Project: "backend", Command: "make", DurationSec: 10,
+				IOStats: IOStats{ReadBytes: 4096, WriteBytes: 1024, ReadChars: 10000, BlkioWaitSec: 1.5}},
+			{Timestamp: "2024-01-05T10:00:00Z", Project: "frontend", Command: "npm ci", DurationSec: 20,
+				IOStats: IOStats{ReadBytes: 100, WriteBytes: 200, WriteSyscalls: 3}},
+		},
+		Total:             IOStats{ReadBytes: 4196, WriteBytes: 1224, ReadChars: 10000, WriteSyscalls: 3, BlkioWaitSec: 1.5},
+		ReportOptions:     opts,
+		WithoutIO:         1,
+		InvalidTimestamps: 1,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GenerateIOReport() = \n%+v, \nwant \n%+v", got, want)
+	}
+}
+
+func TestGenerateIOReportWithInvalidReader(t *testing.T) {
+	if _, err := GenerateIOReport(singleLog(&errorReader{}), ReportOptions{}); err == nil {
+		t.Error("GenerateIOReport() expected error, got nil")
+	}
+}
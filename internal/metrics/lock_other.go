@@ -0,0 +1,8 @@
+//go:build !unix
+
+package metrics
+
+// lockFile não faz nada em plataformas sem flock.
+func lockFile(lockPath string, exclusive bool) (func() error, error) {
+	return func() error { return nil }, nil
+}
@@ -0,0 +1,28 @@
+//go:build unix
+
+package metrics
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile adquire um flock no arquivo de lock associado ao log.
+func lockFile(lockPath string, exclusive bool) (func() error, error) {
+	f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	how := syscall.LOCK_SH
+	if exclusive {
+		how = syscall.LOCK_EX
+	}
+	if err := syscall.Flock(int(f.Fd()), how); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() error {
+		syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		return f.Close()
+	}, nil
+}
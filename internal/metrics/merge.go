@@ -0,0 +1,233 @@
+package metrics
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// MergeInput é um log de entrada para MergeJSONL.
+type MergeInput struct {
+	Name string // Usado apenas para identificar a origem em mensagens
+	R    io.Reader
+}
+
+// MergeConflict descreve registros com o mesmo id mas conteúdo diferente.
+// O primeiro registro (Kept) é mantido e o outro (Dropped) é descartado.
+type MergeConflict struct {
+	ID      string
+	Kept    string // origem no formato "arquivo:linha"
+	Dropped string
+}
+
+// MergeResult resume uma execução de MergeJSONL.
+type MergeResult struct {
+	Written    int
+	Duplicates int // Registros idênticos descartados
+	Invalid    int // Linhas inválidas descartadas
+	Conflicts  []MergeConflict
+}
+
+type mergeEntry struct {
+	t      time.Time
+	raw    []byte
+	source string
+	hash   string
+}
+
+// MergeJSONL combina vários logs em w, ordenados por timestamp.
+//
+// Registros são identificados pelo id; registros antigos, sem id, pelo hash do
+// conteúdo. Registros repetidos são descartados e ids repetidos com conteúdo
+// diferente são reportados como conflito (mantendo o primeiro encontrado).
+// Registros com timestamp inválido vão para o início, na ordem de leitura.
+func MergeJSONL(inputs []MergeInput, w io.Writer, strict bool) (MergeResult, error) {
+	var res MergeResult
+	var entries []mergeEntry
+	byKey := make(map[string]int)
+
+	for _, in := range inputs {
+		scanner := bufio.NewScanner(in.R)
+		buf := make([]byte, 0, 64*1024)
+		scanner.Buffer(buf, maxLineSize)
+
+		lineNo := 0
+		for scanner.Scan() {
+			lineNo++
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			source := fmt.Sprintf("%s:%d", in.Name, lineNo)
+
+			m, err := decodeLine(line)
+			var hash string
+			if err == nil {
+				hash, err = contentHash(line)
+			}
+			if err != nil {
+				res.Invalid++
+				if strict {
+					return res, fmt.Errorf("%s: %w", in.Name, JSONLLineError{Line: lineNo, Err: err, Raw: string(line)})
+				}
+				continue
+			}
+
+			key := "hash:" + hash
+			if m.ID != "" {
+				key = "id:" + m.ID
+			}
+			if idx, ok := byKey[key]; ok {
+				if entries[idx].hash == hash {
+					res.Duplicates++
+				} else {
+					res.Conflicts = append(res.Conflicts, MergeConflict{ID: m.ID, Kept: entries[idx].source, Dropped: source})
+				}
+				continue
+			}
+
+			t, _ := time.Parse(time.RFC3339, m.Timestamp)
+			byKey[key] = len(entries)
+			entries = append(entries, mergeEntry{t: t, raw: append([]byte(nil), line...), source: source, hash: hash})
+		}
+		if err := scanner.Err(); err != nil {
+			return res, fmt.Errorf("%s: %w", in.Name, err)
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].t.Before(entries[j].t) })
+
+	bw := bufio.NewWriter(w)
+	for _, e := range entries {
+		bw.Write(e.raw)
+		bw.WriteByte('\n')
+		res.Written++
+	}
+	return res, bw.Flush()
+}
+
+// contentHash calcula o hash do registro independente da ordem das chaves e
+// da formatação da linha.
+func contentHash(line []byte) (string, error) {
+	var v map[string]any
+	if err := json.Unmarshal(line, &v); err != nil {
+		return "", err
+	}
+	canonical, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// SidecarResult resume uma execução de MergeSidecars.
+type SidecarResult struct {
+	Hosts      int // Perfis de máquina novos no destino
+	Toolchains int // Toolchains novas no destino
+	Artifacts  int // Artefatos copiados
+}
+
+// MergeSidecars leva para o diretório de outLog os arquivos que os registros
+// de cada log de entrada referenciam: perfis de máquina (hosts.jsonl),
+// toolchains (toolchains.jsonl) e artefatos (artifacts/). Entradas já
+// presentes no destino são mantidas.
+func MergeSidecars(inputs []string, outLog string) (SidecarResult, error) {
+	var res SidecarResult
+	hosts, err := LoadHostProfiles(outLog)
+	if err != nil {
+		return res, err
+	}
+	toolchains, err := LoadToolchains(outLog)
+	if err != nil {
+		return res, err
+	}
+	outDir := filepath.Clean(filepath.Dir(outLog))
+	for _, in := range inputs {
+		if filepath.Clean(filepath.Dir(in)) == outDir {
+			continue
+		}
+		n, err := mergeStore(HostsPath(in), HostsPath(outLog), hosts, hostKey)
+		res.Hosts += n
+		if err != nil {
+			return res, err
+		}
+		n, err = mergeStore(ToolchainsPath(in), ToolchainsPath(outLog), toolchains, toolchainKey)
+		res.Toolchains += n
+		if err != nil {
+			return res, err
+		}
+		n, err = copyArtifacts(filepath.Join(filepath.Dir(in), ArtifactsDirName), filepath.Join(outDir, ArtifactsDirName))
+		res.Artifacts += n
+		if err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+// mergeStore acrescenta a dst as entradas de src cuja chave não está em known,
+// em ordem de chave, e retorna quantas foram gravadas.
+func mergeStore[T any](src, dst string, known map[string]T, key func(T) string) (int, error) {
+	entries, err := loadStore(src, key)
+	if err != nil {
+		return 0, err
+	}
+	keys := make([]string, 0, len(entries))
+	for k := range entries {
+		if _, ok := known[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for i, k := range keys {
+		if err := appendToStore(dst, entries[k], key); err != nil {
+			return i, err
+		}
+		known[k] = entries[k]
+	}
+	return len(keys), nil
+}
+
+// copyArtifacts copia os artefatos de src que ainda não existem em dst. Um
+// diretório src ausente não é erro.
+func copyArtifacts(src, dst string) (int, error) {
+	files, err := os.ReadDir(src)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	copied := 0
+	for _, f := range files {
+		if !f.Type().IsRegular() {
+			continue
+		}
+		target := filepath.Join(dst, f.Name())
+		if _, err := os.Stat(target); err == nil {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(src, f.Name()))
+		if err != nil {
+			return copied, err
+		}
+		if err := MkdirAll(dst, 0755); err != nil {
+			return copied, err
+		}
+		if err := os.WriteFile(target, data, 0644); err != nil {
+			return copied, err
+		}
+		copied++
+	}
+	return copied, nil
+}
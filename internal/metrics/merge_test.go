@@ -0,0 +1,111 @@
+package metrics_test
+
+import (
+	"bytes"
+	"dev-metrics/internal/metrics"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMergeJSONL(t *testing.T) {
+	laptop := `{"id":"01A","project":"p","timestamp":"2024-01-02T10:00:00Z","duration_sec":1}
+{"project":"legacy","timestamp":"2024-01-01T08:00:00Z","duration_sec":2}
+{"id":"01C","project":"p","timestamp":"2024-01-04T10:00:00Z","duration_sec":3}
+`
+	ci := `{"timestamp":"2024-01-01T08:00:00Z","duration_sec":2,"project":"legacy"}
+{"id":"01A","project":"p","timestamp":"2024-01-02T10:00:00Z","duration_sec":1}
+{"id":"01B","project":"ci","timestamp":"2024-01-03T10:00:00Z","duration_sec":4}
+{"id":"01C","project":"p","timestamp":"2024-01-04T10:00:00Z","duration_sec":99}
+INVALID
+`
+	var out bytes.Buffer
+	res, err := metrics.MergeJSONL([]metrics.MergeInput{
+		{Name: "laptop.jsonl", R: strings.NewReader(laptop)},
+		{Name: "ci.jsonl", R: strings.NewReader(ci)},
+	}, &out, false)
+	if err != nil {
+		t.Fatalf("MergeJSONL() erro: %v", err)
+	}
+
+	if res.Written != 4 || res.Duplicates != 2 || res.Invalid != 1 {
+		t.Errorf("MergeJSONL() = %+v", res)
+	}
+	if len(res.Conflicts) != 1 || res.Conflicts[0].ID != "01C" || res.Conflicts[0].Kept != "laptop.jsonl:3" || res.Conflicts[0].Dropped != "ci.jsonl:4" {
+		t.Errorf("Conflicts = %+v", res.Conflicts)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	wantOrder := []string{`"legacy"`, `"01A"`, `"01B"`, `"01C"`}
+	for i, want := range wantOrder {
+		if !strings.Contains(lines[i], want) {
+			t.Errorf("linha %d = %s, want %s", i, lines[i], want)
+		}
+	}
+	if !strings.Contains(lines[3], `"duration_sec":3`) {
+		t.Errorf("conflito deveria manter o primeiro registro: %s", lines[3])
+	}
+}
+
+func TestMergeJSONL_Strict(t *testing.T) {
+	_, err := metrics.MergeJSONL([]metrics.MergeInput{{Name: "a.jsonl", R: strings.NewReader("INVALID\n")}}, &bytes.Buffer{}, true)
+	if err == nil || !strings.Contains(err.Error(), "a.jsonl") {
+		t.Errorf("esperava erro identificando o arquivo, obtive %v", err)
+	}
+}
+
+func TestMergeSidecars(t *testing.T) {
+	root := t.TempDir()
+	laptop := filepath.Join(root, "laptop", "build.jsonl")
+	ci := filepath.Join(root, "ci", "build.jsonl")
+	out := filepath.Join(root, "merged", "build.jsonl")
+
+	shared := metrics.HostProfile{ID: "h1", Hostname: "laptop"}
+	for _, save := range []struct {
+		log  string
+		host metrics.HostProfile
+		tc   metrics.ToolchainInfo
+		id   string
+	}{
+		{laptop, shared, metrics.ToolchainInfo{Fingerprint: "t1"}, "01A"},
+		{ci, metrics.HostProfile{ID: "h2", Hostname: "ci"}, metrics.ToolchainInfo{Fingerprint: "t1"}, "01B"},
+	} {
+		if err := metrics.SaveHostProfile(save.log, save.host); err != nil {
+			t.Fatal(err)
+		}
+		if err := metrics.SaveToolchain(save.log, save.tc); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := metrics.SaveOutput(save.log, save.id, []byte(save.id)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := metrics.SaveHostProfile(out, shared); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := metrics.MergeSidecars([]string{laptop, ci}, out)
+	if err != nil {
+		t.Fatalf("MergeSidecars() erro: %v", err)
+	}
+	if want := (metrics.SidecarResult{Hosts: 1, Toolchains: 1, Artifacts: 2}); res != want {
+		t.Errorf("MergeSidecars() = %+v, want %+v", res, want)
+	}
+	hosts, _ := metrics.LoadHostProfiles(out)
+	toolchains, _ := metrics.LoadToolchains(out)
+	if len(hosts) != 2 || len(toolchains) != 1 {
+		t.Errorf("destino com %d perfis e %d toolchains, want 2 e 1", len(hosts), len(toolchains))
+	}
+	for _, id := range []string{"01A", "01B"} {
+		data, err := os.ReadFile(filepath.Join(root, "merged", metrics.ArtifactsDirName, id+".log"))
+		if err != nil || string(data) != id {
+			t.Errorf("artefato %s = %q, %v", id, data, err)
+		}
+	}
+
+	res, err = metrics.MergeSidecars([]string{laptop, ci}, out)
+	if err != nil || res != (metrics.SidecarResult{}) {
+		t.Errorf("segunda execução = %+v, %v; want nada novo", res, err)
+	}
+}
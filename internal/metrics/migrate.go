@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+// MigrateResult resume uma execução de MigrateJSONL.
+type MigrateResult struct {
+	Processed int // Registros válidos escritos na saída
+	Upgraded  int // Registros que precisaram de upgrade de schema
+	Invalid   int // Linhas inválidas (copiadas sem alteração no modo não estrito)
+}
+
+// MigrateJSONL reescreve um log JSONL de r em w com todos os registros na versão
+// CurrentSchemaVersion. Campos desconhecidos são preservados.
+//
+// Se strict for true, para na primeira linha inválida e retorna JSONLLineError.
+// Caso contrário, linhas inválidas são copiadas como estão para não perder dados.
+func MigrateJSONL(r io.Reader, w io.Writer, strict bool) (MigrateResult, error) {
+	var res MigrateResult
+
+	scanner := bufio.NewScanner(r)
+	buf := make([]byte, 0, 64*1024)
+	scanner.Buffer(buf, maxLineSize)
+	bw := bufio.NewWriter(w)
+
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		out, upgraded, err := migrateLine(line)
+		if err != nil {
+			res.Invalid++
+			if strict {
+				bw.Flush()
+				return res, JSONLLineError{Line: lineNo, Err: err, Raw: string(line)}
+			}
+			out = line
+		} else {
+			res.Processed++
+			if upgraded {
+				res.Upgraded++
+			}
+		}
+
+		if _, err := bw.Write(out); err != nil {
+			return res, err
+		}
+		if err := bw.WriteByte('\n'); err != nil {
+			return res, err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		bw.Flush()
+		return res, err
+	}
+	return res, bw.Flush()
+}
+
+func migrateLine(line []byte) ([]byte, bool, error) {
+	var rec RawRecord
+	if err := json.Unmarshal(line, &rec); err != nil {
+		return nil, false, err
+	}
+	upgraded, err := UpgradeRecord(rec)
+	if err != nil {
+		return nil, false, err
+	}
+	if !upgraded {
+		return line, false, nil
+	}
+	out, err := json.Marshal(rec)
+	return out, true, err
+}
@@ -0,0 +1,61 @@
+package metrics_test
+
+import (
+	"bytes"
+	"dev-metrics/internal/metrics"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestMigrateJSONL(t *testing.T) {
+	input := `{"project":"A","command":"[make]","extra":{"keep":true}}
+INVALID
+
+{"schema_version":2,"project":"B","command":"[x]","args":["x"]}
+`
+	var out bytes.Buffer
+	res, err := metrics.MigrateJSONL(strings.NewReader(input), &out, false)
+	if err != nil {
+		t.Fatalf("MigrateJSONL() erro: %v", err)
+	}
+	want := metrics.MigrateResult{Processed: 2, Upgraded: 1, Invalid: 1}
+	if res != want {
+		t.Errorf("MigrateJSONL() = %+v, want %+v", res, want)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("esperava 3 linhas, obtive %d: %q", len(lines), out.String())
+	}
+
+	var rec map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &rec); err != nil {
+		t.Fatal(err)
+	}
+	if rec["schema_version"] != float64(metrics.CurrentSchemaVersion) {
+		t.Errorf("schema_version = %v", rec["schema_version"])
+	}
+	if extra, ok := rec["extra"].(map[string]any); !ok || extra["keep"] != true {
+		t.Errorf("campo desconhecido não preservado: %v", rec["extra"])
+	}
+	if lines[1] != "INVALID" {
+		t.Errorf("linha inválida deveria ser copiada, obtive %q", lines[1])
+	}
+	if lines[2] != `{"schema_version":2,"project":"B","command":"[x]","args":["x"]}` {
+		t.Errorf("registro atual deveria ser copiado sem alteração, obtive %q", lines[2])
+	}
+}
+
+func TestMigrateJSONL_Strict(t *testing.T) {
+	input := "{\"project\":\"A\"}\nINVALID\n"
+	var out bytes.Buffer
+	_, err := metrics.MigrateJSONL(strings.NewReader(input), &out, true)
+	lineErr, ok := err.(metrics.JSONLLineError)
+	if !ok {
+		t.Fatalf("esperava JSONLLineError, obtive %v", err)
+	}
+	if lineErr.Line != 2 {
+		t.Errorf("Line = %d, want 2", lineErr.Line)
+	}
+}
@@ -1,21 +1,119 @@
 package metrics
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
 // BuildMetric representa os dados coletados de uma execução de build
 type BuildMetric struct {
-	Timestamp   string  `json:"timestamp"`
-	User        string  `json:"user"`
-	Hostname    string  `json:"hostname"`
-	OS          string  `json:"os"`
-	Project     string  `json:"project"`
-	Branch      string  `json:"branch"`
-	Commit      string  `json:"commit"`
-	Command     string  `json:"command"`
+	SchemaVersion int      `json:"schema_version"`
+	ID            string   `json:"id,omitempty"` // Identificador único da execução (ausente em registros antigos)
+	Timestamp     string   `json:"timestamp"`
+	User          string   `json:"user"`
+	Hostname      string   `json:"hostname"`
+	OS            string   `json:"os"`
+	Project       string   `json:"project"`
+	Branch        string   `json:"branch"`
+	Commit        string   `json:"commit"`
+	Command       string   `json:"command"`
+	Args          []string `json:"args,omitempty"`
+	DurationSec   float64  `json:"duration_sec"`
+	ReturnCode    int      `json:"returncode"`
+	CPUs          int      `json:"cpus"`
+	Toolchain     string   `json:"toolchain,omitempty"`    // Impressão digital das versões das ferramentas (ver toolchains.jsonl)
+	HostProfile   string   `json:"host_profile,omitempty"` // ID do perfil da máquina (ver hosts.jsonl)
+	Status        string   `json:"status"`
+	FailureClass  string   `json:"failure_class,omitempty"` // Causa provável de uma falha (ex.: compiler_error, oom_killed)
+	BenchmarkID   string   `json:"benchmark_id,omitempty"`  // Compartilhado pelas iterações de um bmt run --repeat
+	Iteration     int      `json:"iteration,omitempty"`     // Posição da iteração no benchmark, a partir de 1
+	Variant       string   `json:"variant,omitempty"`       // Comando comparado ("A" ou "B") em bmt compare
+	AttemptGroup  string   `json:"attempt_group,omitempty"` // Compartilhado pelas tentativas de um bmt run --retries
+	Attempt       int      `json:"attempt,omitempty"`       // Número da tentativa no grupo, a partir de 1
+	Kind          string   `json:"kind,omitempty"`          // Vazio para comandos medidos; KindRecord, KindSpan, KindTarget ou KindNinja
+	ParentID      string   `json:"parent_id,omitempty"`     // Execução que rodou esta (bmt run dentro de bmt run)
+	Depth         int      `json:"depth,omitempty"`         // Nível de aninhamento; zero para execuções de nível superior
+
+	CPUSec   float64          `json:"cpu_sec,omitempty"`  // Tempo de CPU (user+sys) de toda a árvore de processos
+	Sampling *SamplingSummary `json:"sampling,omitempty"` // Presente apenas com bmt run --sample
+	Tools    []ToolUsage      `json:"tools,omitempty"`    // Executáveis que mais consumiram CPU (com --sample)
+	IO       *IOStats         `json:"io,omitempty"`       // Atividade de disco e I/O da árvore de processos
+
+	Contention *Contention `json:"contention,omitempty"` // Carga, builds simultâneos e energia durante a execução
+
+	Phases     []Phase `json:"phases,omitempty"`      // Fases marcadas pelo comando (::bmt-phase name=x::)
+	OutputFile string  `json:"output_file,omitempty"` // Final da saída (relativo ao diretório do log), salvo em falhas ou com --keep-output
+
+	Targets []TargetDuration `json:"targets,omitempty"` // Saídas mais lentas do último build do ninja (.ninja_log)
+
+	Probes map[string]ProbeCounters `json:"probes,omitempty"` // Delta dos contadores de cada probe (ex.: acertos do ccache)
+}
+
+// Tipos de registro (BuildMetric.Kind) que não vêm de um comando medido.
+const (
+	KindRecord = "record" // Duração informada com bmt record
+	KindSpan   = "span"   // Intervalo entre bmt span start e bmt span stop
+	KindTarget = "target" // Receitas de um alvo do make, filho da execução de bmt make
+	KindNinja  = "ninja"  // Build do ninja importado do .ninja_log com bmt ninja-log
+)
+
+// ProbeCounters é a variação dos contadores de uma probe durante a execução.
+type ProbeCounters map[string]float64
+
+// TargetDuration é o tempo de uma saída do ninja (arquivo objeto, biblioteca...)
+// no build medido.
+type TargetDuration struct {
+	Name        string  `json:"name"`
 	DurationSec float64 `json:"duration_sec"`
-	ReturnCode  int     `json:"returncode"`
-	CPUs        int     `json:"cpus"`
-	Status      string  `json:"status"`
+}
+
+// Phase é um trecho de uma execução delimitado por marcadores.
+type Phase struct {
+	Name        string  `json:"name"`
+	StartSec    float64 `json:"start_sec"` // Desde o início do comando
+	DurationSec float64 `json:"duration_sec"`
+}
+
+// IOStats agrega a atividade de I/O da árvore de processos de uma execução.
+// ReadBytes/WriteBytes vêm do rusage (sempre, em Unix); os demais contadores
+// vêm de /proc/<pid>/io e só existem com amostragem.
+type IOStats struct {
+	ReadBytes     uint64  `json:"read_bytes"`      // Lidos do armazenamento local
+	WriteBytes    uint64  `json:"write_bytes"`     // Enviados ao armazenamento local
+	ReadChars     uint64  `json:"rchar,omitempty"` // Passados por read(), inclui cache e disco de rede
+	WriteChars    uint64  `json:"wchar,omitempty"`
+	ReadSyscalls  uint64  `json:"syscr,omitempty"`
+	WriteSyscalls uint64  `json:"syscw,omitempty"`
+	BlkioWaitSec  float64 `json:"blkio_wait_sec,omitempty"` // Espera por I/O de bloco (requer delayacct)
+}
+
+// ToolUsage agrega o consumo de um executável (nome do processo) durante uma execução.
+type ToolUsage struct {
+	Name    string  `json:"name"`
+	CPUSec  float64 `json:"cpu_sec"`
+	WallSec float64 `json:"wall_sec"` // Soma do tempo de vida dos processos
+	Procs   int     `json:"procs"`
+}
+
+// SamplingSummary resume a amostragem da árvore de processos durante uma execução.
+type SamplingSummary struct {
+	IntervalSec     float64 `json:"interval_sec"`
+	Samples         int     `json:"samples"`
+	AvgParallelism  float64 `json:"avg_parallelism"`  // Média de núcleos em uso
+	PeakParallelism float64 `json:"peak_parallelism"` // Maior número de núcleos em uso em uma amostra
+	IdleFraction    float64 `json:"idle_fraction"`    // Fração da capacidade de CPU da máquina não usada
+	PeakProcs       int     `json:"peak_procs"`
+	PeakRSSBytes    uint64  `json:"peak_rss_bytes"` // Soma do RSS da árvore no pico
+	SeriesFile      string  `json:"series_file,omitempty"`
+}
+
+// ProcessSample é uma amostra da árvore de processos (série temporal de bmt run --sample).
+type ProcessSample struct {
+	OffsetSec float64 `json:"t"`
+	CPUs      float64 `json:"cpus"` // Núcleos em uso desde a amostra anterior
+	Procs     int     `json:"procs"`
+	RSSBytes  uint64  `json:"rss_bytes"`
 }
 
 // BuildStats armazena estatísticas agregadas por semana
@@ -41,14 +139,138 @@ type ProjectSummary struct {
 
 // ReportOptions define filtros e opções para geração do relatório
 type ReportOptions struct {
-	Since time.Time // Desde quando olhar os dados. Se zero, olha desde o início.
-	Until time.Time // Até quando olhar os dados. Se zero, olha até o último dado.
+	Since   time.Time // Desde quando olhar os dados. Se zero, olha desde o início.
+	Until   time.Time // Até quando olhar os dados. Se zero, olha até o último dado.
+	GroupBy GroupBy   // Dimensão de agrupamento. Se vazio, agrupa por projeto.
+
+	HostProfiles     map[string]HostProfile // Perfis de máquina por ID (ver LoadHostProfiles), usados por GroupByHost
+	ExcludeContended bool                   // Ignora execuções sob contenção (ver BuildMetric.Contended)
+	Benchmarks       BenchmarkFilter        // Execuções de benchmark consideradas. Se vazio, ficam de fora.
+	IncludeNested    bool                   // Considera também execuções aninhadas (com parent_id), cujo tempo já está na execução pai
+	Kinds            map[string]bool        // Registros manuais (KindRecord, KindSpan) incluídos. Se vazio, ficam de fora.
+}
+
+// ParseReportKinds valida o valor da flag --kinds: tipos de registro manual
+// separados por vírgula.
+func ParseReportKinds(s string) (map[string]bool, error) {
+	kinds := make(map[string]bool)
+	for _, k := range strings.Split(s, ",") {
+		switch k = strings.TrimSpace(k); k {
+		case "":
+		case KindRecord, KindSpan:
+			kinds[k] = true
+		default:
+			return nil, fmt.Errorf("tipo de registro inválido: %q (use record|span)", k)
+		}
+	}
+	return kinds, nil
+}
+
+// BenchmarkFilter define como os relatórios tratam as iterações de benchmark
+// (bmt run --repeat), que distorceriam as estatísticas das execuções do dia a dia.
+type BenchmarkFilter string
+
+const (
+	BenchmarksExclude BenchmarkFilter = "exclude" // só execuções comuns
+	BenchmarksInclude BenchmarkFilter = "include" // todas as execuções
+	BenchmarksOnly    BenchmarkFilter = "only"    // só iterações de benchmark
+)
+
+// ParseBenchmarkFilter valida o valor da flag --benchmarks.
+func ParseBenchmarkFilter(s string) (BenchmarkFilter, error) {
+	switch f := BenchmarkFilter(s); f {
+	case "", BenchmarksExclude:
+		return BenchmarksExclude, nil
+	case BenchmarksInclude, BenchmarksOnly:
+		return f, nil
+	}
+	return "", fmt.Errorf("filtro de benchmarks inválido: %q (use exclude|include|only)", s)
+}
+
+// Keep informa se m entra no relatório segundo o filtro.
+func (f BenchmarkFilter) Keep(m BuildMetric) bool {
+	switch f {
+	case BenchmarksInclude:
+		return true
+	case BenchmarksOnly:
+		return m.BenchmarkID != ""
+	}
+	return m.BenchmarkID == ""
+}
+
+// GroupBy define a dimensão pela qual o relatório agrupa os registros.
+type GroupBy string
+
+const (
+	GroupByProject   GroupBy = "project"   // duração total dos builds por projeto
+	GroupByTool      GroupBy = "tool"      // tempo de CPU por executável (requer registros com tools)
+	GroupByPhase     GroupBy = "phase"     // duração de cada fase marcada, por projeto
+	GroupByToolchain GroupBy = "toolchain" // distribuição das durações por versão das ferramentas, por projeto
+	GroupByHost      GroupBy = "host"      // duração dos builds por hardware (requer ReportOptions.HostProfiles)
+	GroupByTarget    GroupBy = "target"    // duração de cada alvo do make, por projeto (requer bmt make)
+)
+
+// ParseGroupBy valida o valor da flag --by.
+func ParseGroupBy(s string) (GroupBy, error) {
+	switch g := GroupBy(s); g {
+	case "", GroupByProject:
+		return GroupByProject, nil
+	case GroupByTool, GroupByPhase, GroupByToolchain, GroupByHost, GroupByTarget:
+		return g, nil
+	}
+	return "", fmt.Errorf("agrupamento inválido: %q (use project|tool|phase|toolchain|host|target)", s)
 }
 
 // FullReport contém todos os dados prontos para exibição
 type FullReport struct {
-	Projects       []ProjectSummary // Ordenar alfabeticamente
+	Projects       []ProjectSummary // Ordenar alfabeticamente (por ferramenta: pelo maior total)
 	GlobalDuration float64
 	GlobalBuilds   int
 	ReportOptions
+
+	Skipped           int // Linhas JSON inválidas ignoradas
+	InvalidTimestamps int // Registros ignorados por timestamp inválido
+	Contended         int // Execuções ignoradas por contenção (com ExcludeContended)
+}
+
+// Contention descreve a concorrência pela máquina durante uma execução.
+// Execuções sob contenção distorcem as estatísticas e podem ser excluídas dos
+// relatórios (report --exclude-contended).
+type Contention struct {
+	LoadAvg1       float64 `json:"load_avg_1"`                // Load average de 1 minuto no início
+	ConcurrentRuns int     `json:"concurrent_runs"`           // Outros bmt run ativos no início ou no fim
+	PowerSource    string  `json:"power_source,omitempty"`    // "ac" ou "battery"; vazio se a máquina não informa
+	Governor       string  `json:"governor,omitempty"`        // cpufreq scaling_governor da cpu0
+	ThrottleEvents uint64  `json:"throttle_events,omitempty"` // Eventos de throttling térmico durante a execução
+}
+
+// ContendedLoadPerCPU é a carga por CPU, no início, a partir da qual a
+// máquina é considerada ocupada.
+const ContendedLoadPerCPU = 0.5
+
+// Reasons lista por que a execução foi considerada sob contenção (vazio se
+// não foi). cpus é o número de CPUs da máquina.
+func (c *Contention) Reasons(cpus int) []string {
+	if c == nil {
+		return nil
+	}
+	var reasons []string
+	if cpus > 0 && c.LoadAvg1/float64(cpus) > ContendedLoadPerCPU {
+		reasons = append(reasons, fmt.Sprintf("carga %.1f em %d CPUs", c.LoadAvg1, cpus))
+	}
+	if c.ConcurrentRuns > 0 {
+		reasons = append(reasons, fmt.Sprintf("%d outro(s) bmt run", c.ConcurrentRuns))
+	}
+	if c.PowerSource == "battery" {
+		reasons = append(reasons, "na bateria")
+	}
+	if c.ThrottleEvents > 0 {
+		reasons = append(reasons, fmt.Sprintf("%d eventos de throttling térmico", c.ThrottleEvents))
+	}
+	return reasons
+}
+
+// Contended informa se a execução m rodou sob contenção.
+func (m BuildMetric) Contended() bool {
+	return len(m.Contention.Reasons(m.CPUs)) > 0
 }
@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestContentionReasons(t *testing.T) {
+	tests := []struct {
+		name string
+		c    *Contention
+		cpus int
+		want []string
+	}{
+		{name: "Sem dados", c: nil, cpus: 8, want: nil},
+		{name: "Máquina livre", c: &Contention{LoadAvg1: 3.9, PowerSource: "ac", Governor: "powersave"}, cpus: 8, want: nil},
+		{
+			name: "Tudo ao mesmo tempo",
+			c:    &Contention{LoadAvg1: 4.5, ConcurrentRuns: 1, PowerSource: "battery", ThrottleEvents: 12},
+			cpus: 8,
+			want: []string{"carga 4.5 em 8 CPUs", "1 outro(s) bmt run", "na bateria", "12 eventos de throttling térmico"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.c.Reasons(tt.cpus); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Reasons() = %v, want %v", got, tt.want)
+			}
+			m := BuildMetric{CPUs: tt.cpus, Contention: tt.c}
+			if m.Contended() != (tt.want != nil) {
+				t.Errorf("Contended() = %v", m.Contended())
+			}
+		})
+	}
+}
+
+func TestParseReportKinds(t *testing.T) {
+	got, err := ParseReportKinds("record, span")
+	if err != nil || !reflect.DeepEqual(got, map[string]bool{KindRecord: true, KindSpan: true}) {
+		t.Errorf("ParseReportKinds() = %v, %v", got, err)
+	}
+	if got, err := ParseReportKinds(""); err != nil || len(got) != 0 {
+		t.Errorf("ParseReportKinds(\"\") = %v, %v", got, err)
+	}
+	if _, err := ParseReportKinds("target"); err == nil {
+		t.Error("ParseReportKinds(\"target\") deveria falhar")
+	}
+}
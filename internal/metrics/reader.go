@@ -8,6 +8,9 @@ import (
 	"strings"
 )
 
+// maxLineSize é o tamanho máximo aceito para uma linha JSONL.
+const maxLineSize = 1024 * 1024 // 1MB
+
 type ScanResult struct {
 	Processed int
 	Skipped   int
@@ -40,7 +43,7 @@ func ScanJSONL(r io.Reader, strict bool, fn func(BuildMetric) error) (ScanResult
 	scanner := bufio.NewScanner(r)
 	// Default scanner token limit (64K) can be too small for very long JSON lines.
 	buf := make([]byte, 0, 64*1024)
-	scanner.Buffer(buf, 1024*1024) // 1MB
+	scanner.Buffer(buf, maxLineSize)
 
 	lineNo := 0
 	for scanner.Scan() {
@@ -50,8 +53,8 @@ func ScanJSONL(r io.Reader, strict bool, fn func(BuildMetric) error) (ScanResult
 			continue
 		}
 
-		var m BuildMetric
-		if err := json.Unmarshal([]byte(line), &m); err != nil {
+		m, err := decodeLine([]byte(line))
+		if err != nil {
 			res.Skipped++
 			if strict {
 				return res, JSONLLineError{Line: lineNo, Err: err, Raw: line}
@@ -69,3 +72,52 @@ func ScanJSONL(r io.Reader, strict bool, fn func(BuildMetric) error) (ScanResult
 	}
 	return res, nil
 }
+
+// decodeLine converte uma linha JSONL em BuildMetric, aplicando os upgrades
+// de schema necessários para registros gravados por versões antigas.
+func decodeLine(line []byte) (BuildMetric, error) {
+	var m BuildMetric
+	if err := json.Unmarshal(line, &m); err != nil {
+		return m, err
+	}
+	if m.SchemaVersion >= CurrentSchemaVersion {
+		return m, nil
+	}
+
+	var rec RawRecord
+	if err := json.Unmarshal(line, &rec); err != nil {
+		return m, err
+	}
+	if _, err := UpgradeRecord(rec); err != nil {
+		return m, err
+	}
+	upgraded, err := json.Marshal(rec)
+	if err != nil {
+		return m, err
+	}
+	m = BuildMetric{}
+	err = json.Unmarshal(upgraded, &m)
+	return m, err
+}
+
+// LogFile é um log JSONL e o nome (caminho) que o identifica nos erros.
+type LogFile struct {
+	Name string
+	R    io.Reader
+}
+
+// ScanLogs lê os logs em sequência com ScanJSONLParallel, somando os
+// resultados. As linhas são numeradas dentro de cada arquivo e os erros levam
+// o nome do log em que ocorreram.
+func ScanLogs(logs []LogFile, opts ScanOptions, fn func(BuildMetric) error) (ScanResult, error) {
+	var total ScanResult
+	for _, l := range logs {
+		res, err := ScanJSONLParallel(l.R, opts, fn)
+		total.Processed += res.Processed
+		total.Skipped += res.Skipped
+		if err != nil {
+			return total, fmt.Errorf("%s: %w", l.Name, err)
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,258 @@
+package metrics
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+)
+
+// DefaultScanChunkSize é o tamanho aproximado (em bytes) de cada bloco lido por ScanJSONLParallel.
+const DefaultScanChunkSize = 256 * 1024
+
+// ScanOptions configura ScanJSONLParallel.
+type ScanOptions struct {
+	Strict    bool // Mesma semântica do parâmetro strict de ScanJSONL.
+	Workers   int  // Número de goroutines de decodificação. Se <= 0, usa runtime.NumCPU().
+	Unordered bool // Entrega as métricas na ordem em que os blocos terminam (para agregações comutativas).
+	ChunkSize int  // Tamanho aproximado de cada bloco. Se <= 0, usa DefaultScanChunkSize.
+}
+
+type scanChunk struct {
+	seq       int
+	firstLine int
+	data      []byte
+	err       error // erro de leitura encontrado após este bloco
+}
+
+type scanRecord struct {
+	line  int
+	m     BuildMetric
+	err   error  // erro de decodificação (linha inválida)
+	fatal error  // erro que interrompe a leitura (ex.: linha longa demais)
+	raw   []byte // linha original, apenas quando err != nil
+}
+
+type scanBatch struct {
+	seq     int
+	records []scanRecord
+	err     error
+}
+
+// ScanJSONLParallel lê JSONL de r decodificando as linhas em paralelo.
+//
+// O conteúdo é dividido em blocos sempre em fronteiras de linha e decodificado
+// por um pool de workers. O callback fn é sempre chamado a partir da goroutine
+// de quem chamou, nunca concorrentemente.
+//
+// Por padrão as métricas são entregues na ordem original e o comportamento é
+// idêntico ao de ScanJSONL (que também é usado quando há apenas um worker).
+// Com Unordered, os blocos são entregues assim que ficam prontos; no modo
+// strict a leitura vai até o fim para que o erro retornado aponte a primeira
+// linha inválida, mas linhas posteriores a ela podem já ter sido entregues.
+func ScanJSONLParallel(r io.Reader, opts ScanOptions, fn func(BuildMetric) error) (ScanResult, error) {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers == 1 {
+		// Sem paralelismo disponível o pipeline só adiciona overhead.
+		return ScanJSONL(r, opts.Strict, fn)
+	}
+	chunkSize := opts.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultScanChunkSize
+	}
+
+	done := make(chan struct{})
+	chunks := make(chan scanChunk, workers)
+	batches := make(chan scanBatch, workers)
+	// Limita quantos blocos podem estar em memória ao mesmo tempo.
+	inflight := make(chan struct{}, workers*4)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(chunks)
+		readChunks(r, chunkSize, done, inflight, chunks)
+	}()
+
+	var workersWG sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		workersWG.Add(1)
+		go func() {
+			defer workersWG.Done()
+			for c := range chunks {
+				b := decodeChunk(c)
+				select {
+				case batches <- b:
+				case <-done:
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		workersWG.Wait()
+		close(batches)
+	}()
+
+	var res ScanResult
+	err := collectBatches(batches, inflight, opts, &res, fn)
+
+	close(done)
+	// Drena o canal para liberar workers bloqueados e aguarda o leitor.
+	for range batches {
+	}
+	wg.Wait()
+	return res, err
+}
+
+// readChunks lê r em blocos de aproximadamente chunkSize bytes, sempre
+// estendidos até o próximo '\n'.
+func readChunks(r io.Reader, chunkSize int, done <-chan struct{}, inflight chan<- struct{}, out chan<- scanChunk) {
+	br := bufio.NewReaderSize(r, chunkSize)
+	line := 1
+	for seq := 0; ; seq++ {
+		select {
+		case inflight <- struct{}{}:
+		case <-done:
+			return
+		}
+
+		buf := make([]byte, chunkSize)
+		n, err := io.ReadFull(br, buf)
+		buf = buf[:n]
+		last := false
+		switch {
+		case err == nil:
+			tail, terr := br.ReadBytes('\n')
+			buf = append(buf, tail...)
+			if terr == io.EOF {
+				last = true
+			} else if terr != nil {
+				err = terr
+			}
+		case err == io.EOF || err == io.ErrUnexpectedEOF:
+			err = nil
+			last = true
+		}
+
+		c := scanChunk{seq: seq, firstLine: line, data: buf, err: err}
+		select {
+		case out <- c:
+		case <-done:
+			return
+		}
+		if last || err != nil {
+			return
+		}
+		line += bytes.Count(buf, []byte{'\n'})
+	}
+}
+
+// decodeChunk decodifica todas as linhas de um bloco.
+func decodeChunk(c scanChunk) scanBatch {
+	b := scanBatch{seq: c.seq, err: c.err}
+	data := c.data
+	lineNo := c.firstLine
+	for len(data) > 0 {
+		var line []byte
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			line, data = data[:i], data[i+1:]
+		} else {
+			line, data = data, nil
+		}
+		n := lineNo
+		lineNo++
+
+		if len(line) > maxLineSize {
+			b.records = append(b.records, scanRecord{line: n, fatal: bufio.ErrTooLong})
+			return b
+		}
+		line = bytes.TrimSuffix(line, []byte{'\r'})
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		m, err := decodeLine(line)
+		if err != nil {
+			b.records = append(b.records, scanRecord{line: n, err: err, raw: line})
+			continue
+		}
+		b.records = append(b.records, scanRecord{line: n, m: m})
+	}
+	return b
+}
+
+// collectBatches entrega os registros ao callback, respeitando a ordem se necessário.
+func collectBatches(batches <-chan scanBatch, inflight <-chan struct{}, opts ScanOptions, res *ScanResult, fn func(BuildMetric) error) error {
+	pending := make(map[int]scanBatch)
+	next := 0
+	// Fora de ordem, o erro de um bloco pode chegar antes do de um bloco
+	// anterior; guarda o do bloco mais cedo e não entrega os posteriores a ele
+	var firstErr error
+	errSeq := 0
+	for b := range batches {
+		if opts.Unordered {
+			<-inflight
+			if firstErr != nil && b.seq > errSeq {
+				continue
+			}
+			err, cbErr := deliverBatch(b, opts.Strict, res, fn)
+			if cbErr != nil {
+				return cbErr
+			}
+			if err != nil {
+				firstErr, errSeq = err, b.seq
+			}
+			continue
+		}
+
+		pending[b.seq] = b
+		for {
+			nb, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+			<-inflight
+			if err, cbErr := deliverBatch(nb, opts.Strict, res, fn); cbErr != nil {
+				return cbErr
+			} else if err != nil {
+				return err
+			}
+		}
+	}
+	return firstErr
+}
+
+// deliverBatch entrega um bloco ao callback. Erros de leitura e de
+// decodificação voltam em err; o erro do callback, em cbErr.
+func deliverBatch(b scanBatch, strict bool, res *ScanResult, fn func(BuildMetric) error) (err, cbErr error) {
+	for _, rec := range b.records {
+		if rec.fatal != nil {
+			return rec.fatal, nil
+		}
+		if rec.err != nil {
+			res.Skipped++
+			if strict {
+				return JSONLLineError{Line: rec.line, Err: rec.err, Raw: string(rec.raw)}, nil
+			}
+			continue
+		}
+		if err := fn(rec.m); err != nil {
+			return nil, fmt.Errorf("processing jsonl line %d: %w", rec.line, err)
+		}
+		res.Processed++
+	}
+	if b.err != nil && !errors.Is(b.err, io.EOF) {
+		return b.err, nil
+	}
+	return nil, nil
+}
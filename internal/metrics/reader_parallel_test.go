@@ -0,0 +1,167 @@
+package metrics_test
+
+import (
+	"dev-metrics/internal/metrics"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func makeJSONL(n int) string {
+	var sb strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&sb, `{"timestamp":"2026-01-01T00:00:00Z","user":"dev","hostname":"host","os":"linux","project":"p%d","branch":"main","commit":"abc1234","command":"[make -j8]","duration_sec":%d.5,"returncode":0,"cpus":8,"status":"success"}`+"\n", i%7, i)
+	}
+	return sb.String()
+}
+
+func collectProjects(t *testing.T, input string, opts metrics.ScanOptions) ([]string, metrics.ScanResult, error) {
+	t.Helper()
+	var got []string
+	res, err := metrics.ScanJSONLParallel(strings.NewReader(input), opts, func(m metrics.BuildMetric) error {
+		got = append(got, fmt.Sprintf("%s/%v", m.Project, m.DurationSec))
+		return nil
+	})
+	return got, res, err
+}
+
+func TestScanJSONLParallel_MatchesSequential(t *testing.T) {
+	inputs := map[string]string{
+		"vazio":               "",
+		"muitas linhas":       makeJSONL(500),
+		"sem newline final":   strings.TrimSuffix(makeJSONL(20), "\n"),
+		"CRLF":                strings.ReplaceAll(makeJSONL(20), "\n", "\r\n"),
+		"linhas em branco":    "\n\n" + makeJSONL(5) + "\n   \n" + makeJSONL(5),
+		"linha inválida meio": makeJSONL(30) + "INVALID\n" + makeJSONL(30),
+	}
+
+	for name, input := range inputs {
+		for _, chunk := range []int{1, 64, 1000, 0} {
+			t.Run(fmt.Sprintf("%s/chunk=%d", name, chunk), func(t *testing.T) {
+				var want []string
+				wantRes, wantErr := metrics.ScanJSONL(strings.NewReader(input), false, func(m metrics.BuildMetric) error {
+					want = append(want, fmt.Sprintf("%s/%v", m.Project, m.DurationSec))
+					return nil
+				})
+
+				got, gotRes, gotErr := collectProjects(t, input, metrics.ScanOptions{Workers: 4, ChunkSize: chunk})
+				if (gotErr != nil) != (wantErr != nil) {
+					t.Fatalf("erro = %v, sequencial = %v", gotErr, wantErr)
+				}
+				if gotRes != wantRes {
+					t.Errorf("ScanResult = %+v, want %+v", gotRes, wantRes)
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("ordem das métricas difere do modo sequencial")
+				}
+
+				unordered, _, _ := collectProjects(t, input, metrics.ScanOptions{Workers: 4, ChunkSize: chunk, Unordered: true})
+				sort.Strings(unordered)
+				sortedWant := append([]string(nil), want...)
+				sort.Strings(sortedWant)
+				if !reflect.DeepEqual(unordered, sortedWant) {
+					t.Errorf("modo não ordenado entregou métricas diferentes")
+				}
+			})
+		}
+	}
+}
+
+func TestScanJSONLParallel_StrictLineNumber(t *testing.T) {
+	input := makeJSONL(100) + "INVALID\n" + makeJSONL(100)
+
+	for _, unordered := range []bool{false, true} {
+		t.Run(fmt.Sprintf("unordered=%v", unordered), func(t *testing.T) {
+			_, res, err := collectProjects(t, input, metrics.ScanOptions{Strict: true, Workers: 3, ChunkSize: 128, Unordered: unordered})
+			var lineErr metrics.JSONLLineError
+			if !errors.As(err, &lineErr) {
+				t.Fatalf("esperava JSONLLineError, obtive %v", err)
+			}
+			if lineErr.Line != 101 {
+				t.Errorf("Line = %d, want 101", lineErr.Line)
+			}
+			if lineErr.Raw != "INVALID" {
+				t.Errorf("Raw = %q, want %q", lineErr.Raw, "INVALID")
+			}
+			if !unordered && res.Processed != 100 {
+				t.Errorf("Processed = %d, want 100", res.Processed)
+			}
+		})
+	}
+}
+
+func TestScanJSONLParallel_UnorderedFirstError(t *testing.T) {
+	// O primeiro bloco termina com a linha inválida; o segundo, bem menor e
+	// decodificado antes, tem outra
+	valid := makeJSONL(3000)
+	input := valid + "INVALID\n" + "LATER\n"
+	_, _, err := collectProjects(t, input, metrics.ScanOptions{Strict: true, Workers: 2, ChunkSize: len(valid), Unordered: true})
+	var lineErr metrics.JSONLLineError
+	if !errors.As(err, &lineErr) || lineErr.Line != 3001 {
+		t.Fatalf("erro = %v, want a linha 3001", err)
+	}
+}
+
+func TestScanJSONLParallel_CallbackError(t *testing.T) {
+	input := makeJSONL(1000)
+	calls := 0
+	_, err := metrics.ScanJSONLParallel(strings.NewReader(input), metrics.ScanOptions{Workers: 4, ChunkSize: 256}, func(m metrics.BuildMetric) error {
+		calls++
+		if calls == 10 {
+			return errors.New("callback error")
+		}
+		return nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "processing jsonl line 10") {
+		t.Fatalf("erro = %v, want erro na linha 10", err)
+	}
+	if calls != 10 {
+		t.Errorf("callback chamado %d vezes após erro, want 10", calls)
+	}
+}
+
+func TestScanJSONLParallel_LineTooLong(t *testing.T) {
+	input := makeJSONL(2) + `{"project":"` + strings.Repeat("x", 2*1024*1024) + `"}` + "\n"
+	_, _, err := collectProjects(t, input, metrics.ScanOptions{Workers: 2})
+	if err == nil {
+		t.Fatal("esperava erro para linha maior que o limite")
+	}
+}
+
+func benchmarkInput(b *testing.B) string {
+	b.Helper()
+	input := makeJSONL(50000)
+	b.SetBytes(int64(len(input)))
+	b.ResetTimer()
+	return input
+}
+
+func BenchmarkScanJSONL(b *testing.B) {
+	input := benchmarkInput(b)
+	for i := 0; i < b.N; i++ {
+		if _, err := metrics.ScanJSONL(strings.NewReader(input), false, func(metrics.BuildMetric) error { return nil }); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkScanJSONLParallel(b *testing.B) {
+	input := benchmarkInput(b)
+	for i := 0; i < b.N; i++ {
+		if _, err := metrics.ScanJSONLParallel(strings.NewReader(input), metrics.ScanOptions{}, func(metrics.BuildMetric) error { return nil }); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkScanJSONLParallelUnordered(b *testing.B) {
+	input := benchmarkInput(b)
+	for i := 0; i < b.N; i++ {
+		if _, err := metrics.ScanJSONLParallel(strings.NewReader(input), metrics.ScanOptions{Unordered: true}, func(metrics.BuildMetric) error { return nil }); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package metrics_test
 import (
 	"dev-metrics/internal/metrics"
 	"errors"
+	"fmt"
 	"io"
 	"strings"
 	"testing"
@@ -108,6 +109,33 @@ func TestScanJSONL(t *testing.T) {
 	}
 }
 
+func TestScanLogs(t *testing.T) {
+	newLogs := func() []metrics.LogFile {
+		return []metrics.LogFile{
+			{Name: "a.jsonl", R: strings.NewReader(`{"project":"A"}` + "\n" + `{"project":"A"}`)},
+			{Name: "b.jsonl", R: strings.NewReader(`{"project":"B"}` + "\nINVALID\n")},
+		}
+	}
+	for _, workers := range []int{1, 2} {
+		t.Run(fmt.Sprintf("workers=%d", workers), func(t *testing.T) {
+			var projects []string
+			res, err := metrics.ScanLogs(newLogs(), metrics.ScanOptions{Workers: workers}, func(m metrics.BuildMetric) error {
+				projects = append(projects, m.Project)
+				return nil
+			})
+			if err != nil || res.Processed != 3 || res.Skipped != 1 || strings.Join(projects, ",") != "A,A,B" {
+				t.Errorf("ScanLogs() = %+v, %v, %v", res, projects, err)
+			}
+
+			_, err = metrics.ScanLogs(newLogs(), metrics.ScanOptions{Strict: true, Workers: workers}, func(metrics.BuildMetric) error { return nil })
+			var lineErr metrics.JSONLLineError
+			if !errors.As(err, &lineErr) || lineErr.Line != 2 || !strings.HasPrefix(err.Error(), "b.jsonl: ") {
+				t.Errorf("ScanLogs() strict erro = %v, want b.jsonl na linha 2", err)
+			}
+		})
+	}
+}
+
 // TODO: Refazer esse teste ruim para duas funções sem uso: JSONLLineError.Error() e JSONLLineError.Unwrap().
 func TestJSONLLineError_Error(t *testing.T) {
 	tests := []struct {
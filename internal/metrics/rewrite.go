@@ -0,0 +1,90 @@
+package metrics
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// LockLog adquire o lock associado ao log em logPath (arquivo "<log>.lock").
+//
+// Appenders (Save) usam o lock compartilhado, pois escritas em modo append são
+// atômicas entre si. Quem reescreve o arquivo usa o lock exclusivo para não
+// perder registros gravados durante a reescrita.
+var LockLog = func(logPath string, exclusive bool) (func() error, error) {
+	return lockFile(logPath+".lock", exclusive)
+}
+
+// RewriteLog substitui atomicamente o log em path pelo conteúdo que fn escreve em w,
+// a partir do conteúdo atual lido de r. A escrita é feita em um arquivo temporário
+// no mesmo diretório, renomeado sobre o original apenas se fn terminar sem erro,
+// tudo sob o lock exclusivo do log.
+//
+// Se backupPath não for vazio, uma cópia do log original é gravada nele antes da reescrita.
+func RewriteLog(path, backupPath string, fn func(r io.Reader, w io.Writer) error) error {
+	unlock, err := LockLog(path, true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	if backupPath != "" {
+		if err := copyFile(in, backupPath, info.Mode().Perm()); err != nil {
+			return err
+		}
+		if _, err := in.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath) // sem efeito após o rename
+
+	if err := fn(in, tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(info.Mode().Perm()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+func copyFile(src io.Reader, dst string, perm os.FileMode) error {
+	f, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, src); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,69 @@
+package metrics_test
+
+import (
+	"dev-metrics/internal/metrics"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRewriteLog(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "log.jsonl")
+	backup := filepath.Join(dir, "log.jsonl.bak")
+	if err := os.WriteFile(path, []byte("a\nb\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	err := metrics.RewriteLog(path, backup, func(r io.Reader, w io.Writer) error {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(w, strings.ToUpper(string(data)))
+		return err
+	})
+	if err != nil {
+		t.Fatalf("RewriteLog() erro: %v", err)
+	}
+
+	got, _ := os.ReadFile(path)
+	if string(got) != "A\nB\n" {
+		t.Errorf("log = %q, want %q", got, "A\nB\n")
+	}
+	bak, _ := os.ReadFile(backup)
+	if string(bak) != "a\nb\n" {
+		t.Errorf("backup = %q, want %q", bak, "a\nb\n")
+	}
+	info, _ := os.Stat(path)
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("permissões = %v, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestRewriteLog_ErrorKeepsOriginal(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "log.jsonl")
+	os.WriteFile(path, []byte("a\n"), 0644)
+
+	err := metrics.RewriteLog(path, "", func(r io.Reader, w io.Writer) error {
+		io.WriteString(w, "parcial")
+		return errors.New("falhou")
+	})
+	if err == nil {
+		t.Fatal("esperava erro")
+	}
+	got, _ := os.ReadFile(path)
+	if string(got) != "a\n" {
+		t.Errorf("log original alterado: %q", got)
+	}
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		if strings.Contains(e.Name(), ".tmp-") {
+			t.Errorf("arquivo temporário não removido: %s", e.Name())
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package metrics
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// SaveSamples grava a série temporal de amostras em path, uma amostra JSON por linha.
+func SaveSamples(path string, samples []ProcessSample) error {
+	if err := EnsureDir(filepath.Dir(path)); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	bw := bufio.NewWriter(f)
+	enc := json.NewEncoder(bw)
+	for _, s := range samples {
+		if err := enc.Encode(s); err != nil {
+			return err
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	return f.Close()
+}
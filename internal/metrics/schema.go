@@ -0,0 +1,104 @@
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// CurrentSchemaVersion é a versão do schema gravada por esta versão do bmt.
+//
+// Histórico:
+//   - 1: registros sem o campo schema_version (releases anteriores ao versionamento).
+//   - 2: adiciona schema_version e args (argv do comando medido).
+const CurrentSchemaVersion = 2
+
+// legacySchemaVersion é a versão assumida para registros sem schema_version.
+const legacySchemaVersion = 1
+
+// RawRecord é um registro JSONL decodificado de forma genérica.
+// Diferente de BuildMetric, preserva campos desconhecidos.
+type RawRecord map[string]json.RawMessage
+
+// SchemaUpgrade converte, no próprio registro, a versão N para N+1.
+type SchemaUpgrade func(RawRecord) error
+
+// SchemaUpgrades mapeia a versão de origem para a função que a converte na versão seguinte.
+// Ao mudar o schema, incremente CurrentSchemaVersion e registre aqui a conversão
+// da versão anterior; nunca remova entradas antigas, pois logs antigos precisam
+// continuar legíveis.
+var SchemaUpgrades = map[int]SchemaUpgrade{
+	1: upgradeV1ToV2,
+}
+
+// SchemaVersionOf retorna a versão do schema de um registro bruto.
+func SchemaVersionOf(rec RawRecord) (int, error) {
+	raw, ok := rec["schema_version"]
+	if !ok || string(raw) == "null" {
+		return legacySchemaVersion, nil
+	}
+	var v int
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return 0, fmt.Errorf("schema_version inválido: %w", err)
+	}
+	if v <= 0 {
+		return legacySchemaVersion, nil
+	}
+	return v, nil
+}
+
+// UpgradeRecord aplica em sequência os upgrades registrados até CurrentSchemaVersion.
+// Registros de versões mais novas que a atual são mantidos como estão.
+// Retorna true se o registro foi alterado.
+func UpgradeRecord(rec RawRecord) (bool, error) {
+	v, err := SchemaVersionOf(rec)
+	if err != nil {
+		return false, err
+	}
+	if v >= CurrentSchemaVersion {
+		return false, nil
+	}
+	for ; v < CurrentSchemaVersion; v++ {
+		up, ok := SchemaUpgrades[v]
+		if !ok {
+			return false, fmt.Errorf("nenhum upgrade registrado para schema_version %d", v)
+		}
+		if err := up(rec); err != nil {
+			return false, fmt.Errorf("upgrade do schema %d para %d: %w", v, v+1, err)
+		}
+		rec["schema_version"] = json.RawMessage(fmt.Sprint(v + 1))
+	}
+	return true, nil
+}
+
+// upgradeV1ToV2 preenche args a partir de command.
+//
+// Registros antigos gravavam o comando como fmt.Sprint(args), ex.: "[go test ./...]".
+// A reconstrução é a melhor possível: argumentos com espaços não podem ser recuperados.
+func upgradeV1ToV2(rec RawRecord) error {
+	if _, ok := rec["args"]; ok {
+		return nil
+	}
+	raw, ok := rec["command"]
+	if !ok {
+		return nil
+	}
+	var command string
+	if err := json.Unmarshal(raw, &command); err != nil {
+		return nil // command fora do padrão: deixa args ausente
+	}
+	command = strings.TrimSpace(command)
+	if strings.HasPrefix(command, "[") && strings.HasSuffix(command, "]") {
+		command = command[1 : len(command)-1]
+	}
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(args)
+	if err != nil {
+		return err
+	}
+	rec["args"] = data
+	return nil
+}
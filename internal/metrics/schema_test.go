@@ -0,0 +1,117 @@
+package metrics_test
+
+import (
+	"dev-metrics/internal/metrics"
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestUpgradeRecord(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		wantUpgraded bool
+		wantArgs     []string
+		wantVersion  int
+	}{
+		{
+			name:         "Legado sem schema_version",
+			input:        `{"command":"[go test ./...]"}`,
+			wantUpgraded: true,
+			wantArgs:     []string{"go", "test", "./..."},
+			wantVersion:  metrics.CurrentSchemaVersion,
+		},
+		{
+			name:         "Legado com args já presentes",
+			input:        `{"command":"[a b]","args":["a b"]}`,
+			wantUpgraded: true,
+			wantArgs:     []string{"a b"},
+			wantVersion:  metrics.CurrentSchemaVersion,
+		},
+		{
+			name:         "Versão atual não muda",
+			input:        `{"schema_version":2,"command":"[x]"}`,
+			wantUpgraded: false,
+			wantVersion:  2,
+		},
+		{
+			name:         "Versão futura é preservada",
+			input:        `{"schema_version":99,"command":"[x]"}`,
+			wantUpgraded: false,
+			wantVersion:  99,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rec metrics.RawRecord
+			if err := json.Unmarshal([]byte(tt.input), &rec); err != nil {
+				t.Fatal(err)
+			}
+			upgraded, err := metrics.UpgradeRecord(rec)
+			if err != nil {
+				t.Fatalf("UpgradeRecord() erro: %v", err)
+			}
+			if upgraded != tt.wantUpgraded {
+				t.Errorf("upgraded = %v, want %v", upgraded, tt.wantUpgraded)
+			}
+			v, _ := metrics.SchemaVersionOf(rec)
+			if v != tt.wantVersion {
+				t.Errorf("versão = %d, want %d", v, tt.wantVersion)
+			}
+			if tt.wantArgs != nil {
+				var args []string
+				json.Unmarshal(rec["args"], &args)
+				if !reflect.DeepEqual(args, tt.wantArgs) {
+					t.Errorf("args = %v, want %v", args, tt.wantArgs)
+				}
+			}
+		})
+	}
+}
+
+func TestUpgradeRecord_MissingUpgrade(t *testing.T) {
+	orig := metrics.SchemaUpgrades
+	defer func() { metrics.SchemaUpgrades = orig }()
+	metrics.SchemaUpgrades = map[int]metrics.SchemaUpgrade{}
+
+	rec := metrics.RawRecord{"command": json.RawMessage(`"[x]"`)}
+	if _, err := metrics.UpgradeRecord(rec); err == nil {
+		t.Error("esperava erro quando não há upgrade registrado")
+	}
+}
+
+// Logs gravados por releases antigas precisam continuar legíveis para sempre.
+func TestScanJSONL_LegacyLog(t *testing.T) {
+	f, err := os.Open("testdata/legacy_v1.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var got []metrics.BuildMetric
+	res, err := metrics.ScanJSONL(f, true, func(m metrics.BuildMetric) error {
+		got = append(got, m)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ScanJSONL() erro: %v", err)
+	}
+	if res.Processed != 3 {
+		t.Fatalf("Processed = %d, want 3", res.Processed)
+	}
+	first := got[0]
+	if first.SchemaVersion != metrics.CurrentSchemaVersion {
+		t.Errorf("SchemaVersion = %d, want %d", first.SchemaVersion, metrics.CurrentSchemaVersion)
+	}
+	if first.Project != "dev-metrics" || first.DurationSec != 12.84 || first.Status != "success" {
+		t.Errorf("campos legados não preservados: %+v", first)
+	}
+	if !reflect.DeepEqual(first.Args, []string{"go", "test", "./..."}) {
+		t.Errorf("Args = %v", first.Args)
+	}
+	if got[2].ReturnCode != -1 || got[2].Status != "interrupted" {
+		t.Errorf("registro interrompido não preservado: %+v", got[2])
+	}
+}
@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// Registros auxiliares (toolchains.jsonl, hosts.jsonl) ficam ao lado do log e
+// guardam uma vez só dados que os registros referenciam por hash, para que as
+// linhas do log continuem pequenas.
+
+// loadStore lê um registro auxiliar, indexado por key. A ausência do arquivo
+// não é erro; linhas inválidas ou sem chave são ignoradas e, para chaves
+// repetidas, vale a primeira linha.
+func loadStore[T any](path string, key func(T) string) (map[string]T, error) {
+	entries := make(map[string]T)
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var v T
+		if json.Unmarshal(scanner.Bytes(), &v) != nil {
+			continue
+		}
+		k := key(v)
+		if _, ok := entries[k]; ok || k == "" {
+			continue
+		}
+		entries[k] = v
+	}
+	return entries, scanner.Err()
+}
+
+// appendToStore acrescenta v ao registro auxiliar se a chave ainda não existir.
+func appendToStore[T any](path string, v T, key func(T) string) error {
+	known, err := loadStore(path, key)
+	if err != nil {
+		return err
+	}
+	if _, ok := known[key(v)]; ok {
+		return nil
+	}
+	if err := EnsureDir(filepath.Dir(path)); err != nil {
+		return err
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
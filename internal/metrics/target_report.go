@@ -0,0 +1,139 @@
+package metrics
+
+import (
+	"sort"
+	"time"
+)
+
+// TargetHistoryLen é quantos builds recentes de cada alvo o relatório guarda
+// para o histórico.
+const TargetHistoryLen = 8
+
+// minTrendBuilds é o mínimo de builds para comparar as duas metades do histórico.
+const minTrendBuilds = 4
+
+// TargetTrend é um alvo (saída do ninja ou alvo do make) ao longo dos builds.
+type TargetTrend struct {
+	Project string
+	Name    string
+	Builds  int
+	AvgSec  float64
+	LastSec float64
+	// TrendPct é a variação da média da metade recente dos builds sobre a da
+	// metade antiga; só vale com HasTrend
+	TrendPct float64
+	HasTrend bool
+	History  []float64 // Durações dos últimos builds, do mais antigo ao mais recente
+}
+
+// TargetReport lista os alvos mais lentos pela duração média.
+type TargetReport struct {
+	Entries []TargetTrend
+	Builds  int // Builds com alvos no período
+	ReportOptions
+
+	Contended         int // Execuções ignoradas por contenção (com ExcludeContended)
+	Skipped           int // Linhas JSON inválidas ignoradas
+	InvalidTimestamps int // Registros ignorados por timestamp inválido
+}
+
+// GenerateTargetReport junta os alvos do .ninja_log (BuildMetric.Targets) e
+// os de bmt make (registros KindTarget) por projeto e nome e retorna os top
+// mais lentos (todos, com top zero).
+func GenerateTargetReport(logs []LogFile, opts ReportOptions, top int) (*TargetReport, error) {
+	report := &TargetReport{ReportOptions: opts}
+	type key struct{ project, name string }
+	type sample struct {
+		at  time.Time
+		sec float64
+	}
+	samples := make(map[key][]sample)
+	builds := make(map[string]bool)
+
+	scanRes, err := ScanLogs(logs, ScanOptions{Unordered: true}, func(m BuildMetric) error {
+		t, err := time.Parse(time.RFC3339, m.Timestamp)
+		if err != nil {
+			report.InvalidTimestamps++
+			return nil
+		}
+		if !opts.Since.IsZero() && t.Before(opts.Since) {
+			return nil
+		}
+		if !opts.Until.IsZero() && t.After(opts.Until) {
+			return nil
+		}
+		if !opts.Benchmarks.Keep(m) {
+			return nil
+		}
+		// Os alvos do make são sempre filhos da execução de bmt make
+		if m.ParentID != "" && !opts.IncludeNested && m.Kind != KindTarget {
+			return nil
+		}
+		if opts.ExcludeContended && m.Contended() {
+			report.Contended++
+			return nil
+		}
+
+		if m.Kind == KindTarget {
+			builds[m.ParentID] = true
+			k := key{m.Project, m.Command}
+			samples[k] = append(samples[k], sample{t, m.DurationSec})
+			return nil
+		}
+		if len(m.Targets) > 0 {
+			builds[m.ID] = true
+		}
+		for _, target := range m.Targets {
+			k := key{m.Project, target.Name}
+			samples[k] = append(samples[k], sample{t, target.DurationSec})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	report.Skipped = scanRes.Skipped
+	report.Builds = len(builds)
+
+	for k, s := range samples {
+		sort.SliceStable(s, func(i, j int) bool { return s[i].at.Before(s[j].at) })
+		e := TargetTrend{Project: k.project, Name: k.name, Builds: len(s), LastSec: s[len(s)-1].sec}
+		durations := make([]float64, len(s))
+		for i, x := range s {
+			durations[i] = x.sec
+			e.AvgSec += x.sec
+		}
+		e.AvgSec /= float64(len(s))
+		if len(s) >= minTrendBuilds {
+			half := len(s) / 2
+			older, recent := mean(durations[:half]), mean(durations[len(s)-half:])
+			if older > 0 {
+				e.TrendPct, e.HasTrend = (recent-older)/older*100, true
+			}
+		}
+		e.History = durations[max(0, len(durations)-TargetHistoryLen):]
+		report.Entries = append(report.Entries, e)
+	}
+	sort.Slice(report.Entries, func(i, j int) bool {
+		a, b := report.Entries[i], report.Entries[j]
+		if a.AvgSec != b.AvgSec {
+			return a.AvgSec > b.AvgSec
+		}
+		if a.Project != b.Project {
+			return a.Project < b.Project
+		}
+		return a.Name < b.Name
+	})
+	if top > 0 && len(report.Entries) > top {
+		report.Entries = report.Entries[:top]
+	}
+	return report, nil
+}
+
+func mean(values []float64) float64 {
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
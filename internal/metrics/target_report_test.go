@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGenerateTargetReport(t *testing.T) {
+	input := `
+{"id": "01A", "project": "engine", "timestamp": "2024-01-01T10:00:00Z", "duration_sec": 60, "targets": [{"name": "render.o", "duration_sec": 10}, {"name": "ui.o", "duration_sec": 4}]}
+{"id": "01B", "project": "engine", "timestamp": "2024-01-02T10:00:00Z", "duration_sec": 60, "targets": [{"name": "render.o", "duration_sec": 10}]}
+{"id": "01D", "project": "engine", "timestamp": "2024-01-04T10:00:00Z", "duration_sec": 60, "targets": [{"name": "render.o", "duration_sec": 15}]}
+{"id": "01C", "project": "engine", "timestamp": "2024-01-03T10:00:00Z", "duration_sec": 60, "targets": [{"name": "render.o", "duration_sec": 15}, {"name": "ui.o", "duration_sec": 2}]}
+{"id": "01F", "parent_id": "01E", "depth": 1, "kind": "target", "project": "tools", "command": "gen", "timestamp": "2024-01-03T10:00:00Z", "duration_sec": 5}
+{"id": "01G", "parent_id": "01A", "depth": 1, "project": "engine", "timestamp": "2024-01-03T10:00:00Z", "duration_sec": 60, "targets": [{"name": "render.o", "duration_sec": 99}]}
+{"id": "01H", "project": "engine", "timestamp": "2024-01-03T10:00:00Z", "duration_sec": 60}
+`
+	got, err := GenerateTargetReport(singleLog(strings.NewReader(input)), ReportOptions{}, 2)
+	if err != nil {
+		t.Fatalf("GenerateTargetReport() erro: %v", err)
+	}
+	want := &TargetReport{
+		Entries: []TargetTrend{
+			{Project: "engine", Name: "render.o", Builds: 4, AvgSec: 12.5, LastSec: 15, TrendPct: 50, HasTrend: true, History: []float64{10, 10, 15, 15}},
+			{Project: "tools", Name: "gen", Builds: 1, AvgSec: 5, LastSec: 5, History: []float64{5}},
+		},
+		Builds: 5,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GenerateTargetReport() = \n%+v, \nwant \n%+v", got, want)
+	}
+}
@@ -0,0 +1,162 @@
+package metrics
+
+import (
+	"sort"
+	"time"
+
+	"dev-metrics/internal/stats"
+)
+
+// ToolchainChange é uma ferramenta que mudou entre dois períodos. From vazio
+// indica uma ferramenta nova; To vazio, uma removida.
+type ToolchainChange struct {
+	Tool string
+	From string
+	To   string
+}
+
+// ToolchainPeriod agrupa os builds de um projeto feitos com a mesma toolchain.
+type ToolchainPeriod struct {
+	Fingerprint string
+	FirstRun    time.Time
+	LastRun     time.Time
+	Versions    map[string]string // De toolchains.jsonl; nil se a impressão digital não foi registrada
+	Changes     []ToolchainChange // Em relação ao período anterior do projeto
+	Duration    stats.Summary
+}
+
+// ToolchainProject lista os períodos de um projeto em ordem cronológica.
+type ToolchainProject struct {
+	Name    string
+	Periods []ToolchainPeriod
+}
+
+// ToolchainReport mostra a distribuição da duração dos builds antes e depois
+// de cada mudança de toolchain.
+type ToolchainReport struct {
+	Projects []ToolchainProject // Ordenados por nome
+	ReportOptions
+
+	WithoutToolchain  int // Builds bem-sucedidos sem impressão digital (anteriores à coleta)
+	Skipped           int // Linhas JSON inválidas ignoradas
+	InvalidTimestamps int // Registros ignorados por timestamp inválido
+	Contended         int // Execuções ignoradas por contenção (com ExcludeContended)
+}
+
+// GenerateToolchainReport agrupa os builds bem-sucedidos por projeto e
+// toolchain. toolchains traduz as impressões digitais em versões (ver
+// LoadToolchains) e pode ser nil.
+func GenerateToolchainReport(logs []LogFile, toolchains map[string]ToolchainInfo, opts ReportOptions) (*ToolchainReport, error) {
+	report := &ToolchainReport{ReportOptions: opts}
+	type key struct{ project, fingerprint string }
+	type acc struct {
+		first, last time.Time
+		durations   []float64
+	}
+	groups := make(map[key]*acc)
+
+	scanRes, err := ScanLogs(logs, ScanOptions{Unordered: true}, func(m BuildMetric) error {
+		t, err := time.Parse(time.RFC3339, m.Timestamp)
+		if err != nil {
+			report.InvalidTimestamps++
+			return nil
+		}
+		if !opts.Since.IsZero() && t.Before(opts.Since) {
+			return nil
+		}
+		if !opts.Until.IsZero() && t.After(opts.Until) {
+			return nil
+		}
+		if !opts.Benchmarks.Keep(m) {
+			return nil
+		}
+		if m.ParentID != "" && !opts.IncludeNested {
+			return nil
+		}
+		if opts.ExcludeContended && m.Contended() {
+			report.Contended++
+			return nil
+		}
+		if m.Status != "success" {
+			return nil
+		}
+		if m.Toolchain == "" {
+			report.WithoutToolchain++
+			return nil
+		}
+
+		k := key{m.Project, m.Toolchain}
+		g := groups[k]
+		if g == nil {
+			g = &acc{first: t, last: t}
+			groups[k] = g
+		}
+		if t.Before(g.first) {
+			g.first = t
+		}
+		if t.After(g.last) {
+			g.last = t
+		}
+		g.durations = append(g.durations, m.DurationSec)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	report.Skipped = scanRes.Skipped
+
+	projects := make(map[string]*ToolchainProject)
+	for k, g := range groups {
+		if projects[k.project] == nil {
+			projects[k.project] = &ToolchainProject{Name: k.project}
+		}
+		period := ToolchainPeriod{
+			Fingerprint: k.fingerprint,
+			FirstRun:    g.first,
+			LastRun:     g.last,
+			Duration:    stats.Summarize(g.durations),
+		}
+		if info, ok := toolchains[k.fingerprint]; ok {
+			period.Versions = info.Versions
+		}
+		projects[k.project].Periods = append(projects[k.project].Periods, period)
+	}
+
+	for _, p := range projects {
+		sort.Slice(p.Periods, func(i, j int) bool {
+			if !p.Periods[i].FirstRun.Equal(p.Periods[j].FirstRun) {
+				return p.Periods[i].FirstRun.Before(p.Periods[j].FirstRun)
+			}
+			return p.Periods[i].Fingerprint < p.Periods[j].Fingerprint
+		})
+		for i := 1; i < len(p.Periods); i++ {
+			p.Periods[i].Changes = diffToolchains(p.Periods[i-1].Versions, p.Periods[i].Versions)
+		}
+		report.Projects = append(report.Projects, *p)
+	}
+	sort.Slice(report.Projects, func(i, j int) bool {
+		return report.Projects[i].Name < report.Projects[j].Name
+	})
+	return report, nil
+}
+
+// diffToolchains lista as ferramentas que mudaram de before para after, em
+// ordem alfabética. Retorna nil se alguma das versões é desconhecida.
+func diffToolchains(before, after map[string]string) []ToolchainChange {
+	if before == nil || after == nil {
+		return nil
+	}
+	var changes []ToolchainChange
+	for tool, to := range after {
+		if from := before[tool]; from != to {
+			changes = append(changes, ToolchainChange{Tool: tool, From: from, To: to})
+		}
+	}
+	for tool, from := range before {
+		if _, ok := after[tool]; !ok {
+			changes = append(changes, ToolchainChange{Tool: tool, From: from})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Tool < changes[j].Tool })
+	return changes
+}
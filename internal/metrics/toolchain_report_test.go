@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"dev-metrics/internal/stats"
+)
+
+func TestGenerateToolchainReport(t *testing.T) {
+	input := `
+{"project": "backend", "timestamp": "2024-01-01T10:00:00Z", "duration_sec": 100, "status": "success", "toolchain": "aaa"}
+{"project": "backend", "timestamp": "2024-01-02T10:00:00Z", "duration_sec": 120, "status": "success", "toolchain": "aaa"}
+{"project": "backend", "timestamp": "2024-01-03T10:00:00Z", "duration_sec": 200, "status": "success", "toolchain": "bbb"}
+{"project": "backend", "timestamp": "2024-01-03T11:00:00Z", "duration_sec": 999, "status": "failure", "toolchain": "bbb"}
+{"project": "backend", "timestamp": "2024-01-04T10:00:00Z", "duration_sec": 220, "status": "success", "toolchain": "bbb"}
+{"project": "backend", "timestamp": "2023-12-31T10:00:00Z", "duration_sec": 90, "status": "success"}
+{"project": "docs", "timestamp": "2024-01-02T10:00:00Z", "duration_sec": 5, "status": "success", "toolchain": "zzz"}
+`
+	toolchains := map[string]ToolchainInfo{
+		"aaa": {Fingerprint: "aaa", Versions: map[string]string{"gcc": "gcc 12.2.0", "cmake": "cmake 3.27", "ninja": "1.10"}},
+		"bbb": {Fingerprint: "bbb", Versions: map[string]string{"gcc": "gcc 13.2.0", "cmake": "cmake 3.27", "go": "go1.22"}},
+	}
+	got, err := GenerateToolchainReport(singleLog(strings.NewReader(input)), toolchains, ReportOptions{})
+	if err != nil {
+		t.Fatalf("GenerateToolchainReport() erro: %v", err)
+	}
+	day := func(d, h int) time.Time { return time.Date(2024, 1, d, h, 0, 0, 0, time.UTC) }
+	want := &ToolchainReport{
+		Projects: []ToolchainProject{
+			{Name: "backend", Periods: []ToolchainPeriod{
+				{
+					Fingerprint: "aaa", FirstRun: day(1, 10), LastRun: day(2, 10),
+					Versions: toolchains["aaa"].Versions,
+					Duration: stats.Summarize([]float64{100, 120}),
+				},
+				{
+					Fingerprint: "bbb", FirstRun: day(3, 10), LastRun: day(4, 10),
+					Versions: toolchains["bbb"].Versions,
+					Changes: []ToolchainChange{
+						{Tool: "gcc", From: "gcc 12.2.0", To: "gcc 13.2.0"},
+						{Tool: "go", To: "go1.22"},
+						{Tool: "ninja", From: "1.10"},
+					},
+					Duration: stats.Summarize([]float64{200, 220}),
+				},
+			}},
+			{Name: "docs", Periods: []ToolchainPeriod{
+				{Fingerprint: "zzz", FirstRun: day(2, 10), LastRun: day(2, 10), Duration: stats.Summarize([]float64{5})},
+			}},
+		},
+		WithoutToolchain: 1,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GenerateToolchainReport() = \n%+v, \nwant \n%+v", got, want)
+	}
+}
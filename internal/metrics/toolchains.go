@@ -0,0 +1,36 @@
+package metrics
+
+import (
+	"path/filepath"
+)
+
+// ToolchainsFileName é o arquivo, ao lado do log, que associa cada impressão
+// digital de toolchain às versões que a compõem.
+const ToolchainsFileName = "toolchains.jsonl"
+
+// ToolchainInfo descreve uma impressão digital (BuildMetric.Toolchain).
+type ToolchainInfo struct {
+	Fingerprint string            `json:"fingerprint"`
+	Hostname    string            `json:"hostname"`
+	FirstSeen   string            `json:"first_seen"` // RFC3339
+	Versions    map[string]string `json:"versions"`   // Ferramenta -> primeira linha da saída de --version
+}
+
+func toolchainKey(info ToolchainInfo) string { return info.Fingerprint }
+
+// ToolchainsPath retorna o caminho do registro de toolchains do log.
+func ToolchainsPath(logPath string) string {
+	return filepath.Join(filepath.Dir(logPath), ToolchainsFileName)
+}
+
+// LoadToolchains lê as toolchains registradas ao lado do log, por impressão
+// digital. A ausência do arquivo não é erro.
+func LoadToolchains(logPath string) (map[string]ToolchainInfo, error) {
+	return loadStore(ToolchainsPath(logPath), toolchainKey)
+}
+
+// SaveToolchain registra info ao lado do log se a impressão digital ainda não
+// for conhecida.
+func SaveToolchain(logPath string, info ToolchainInfo) error {
+	return appendToStore(ToolchainsPath(logPath), info, toolchainKey)
+}
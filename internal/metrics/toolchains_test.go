@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSaveAndLoadToolchains(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "logs", "build.jsonl")
+
+	if infos, err := LoadToolchains(logPath); err != nil || len(infos) != 0 {
+		t.Fatalf("LoadToolchains(ausente) = %v, %v; want vazio", infos, err)
+	}
+
+	first := ToolchainInfo{Fingerprint: "abc", Hostname: "h", FirstSeen: "2024-01-01T00:00:00Z", Versions: map[string]string{"gcc": "gcc 13"}}
+	again := first
+	again.FirstSeen = "2024-02-01T00:00:00Z"
+	other := ToolchainInfo{Fingerprint: "def", Hostname: "h", FirstSeen: "2024-03-01T00:00:00Z", Versions: map[string]string{"gcc": "gcc 14"}}
+	for _, info := range []ToolchainInfo{first, again, other} {
+		if err := SaveToolchain(logPath, info); err != nil {
+			t.Fatalf("SaveToolchain() erro: %v", err)
+		}
+	}
+
+	data, _ := os.ReadFile(ToolchainsPath(logPath))
+	if n := strings.Count(string(data), "\n"); n != 2 {
+		t.Errorf("%s tem %d linhas, want 2 (impressões digitais repetidas não são gravadas)", ToolchainsFileName, n)
+	}
+	infos, err := LoadToolchains(logPath)
+	if err != nil {
+		t.Fatalf("LoadToolchains() erro: %v", err)
+	}
+	want := map[string]ToolchainInfo{"abc": first, "def": other}
+	if !reflect.DeepEqual(infos, want) {
+		t.Errorf("LoadToolchains() = %+v, want %+v", infos, want)
+	}
+}
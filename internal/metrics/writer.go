@@ -22,6 +22,10 @@ var (
 	}
 )
 
+// atomicAppendSize é o maior append que o bmt trata como atômico entre
+// processos concorrentes (PIPE_BUF no Linux).
+const atomicAppendSize = 4096
+
 // Save writes the metric to the specified file path in JSONL format
 func Save(m BuildMetric, filePath string) error {
 	logDir := filepath.Dir(filePath)
@@ -29,19 +33,32 @@ func Save(m BuildMetric, filePath string) error {
 		return err
 	}
 
-	// Usando append + JSONL obtemos escrita atômica sem problemas de concorrência, dispensando locks adicionais.
-	// A atomicidade é garantida se a string for escrita em uma única chamada de WriteString com tamanho menor que o page size do sistema (geralmente 4KB).
-	f, err := OpenFile(filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if m.SchemaVersion == 0 {
+		m.SchemaVersion = CurrentSchemaVersion
+	}
+	jsonData, err := json.Marshal(m)
 	if err != nil {
 		return err
 	}
-	defer f.Close()
+	line := string(jsonData) + "\n"
 
-	jsonData, err := json.Marshal(m)
+	// Com O_APPEND, cada linha vai para o fim do arquivo numa única chamada de
+	// WriteString. Até atomicAppendSize (PIPE_BUF) bytes, appenders concorrentes
+	// não se intercalam e o lock compartilhado só impede que o log seja reescrito
+	// (RewriteLog) durante a gravação. Registros maiores (ex.: com amostras ou
+	// muitos alvos) usam o lock exclusivo, que também serializa os appenders.
+	unlock, err := LockLog(filePath, len(line) > atomicAppendSize)
 	if err != nil {
 		return err
 	}
+	defer unlock()
+
+	f, err := OpenFile(filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
 
-	_, err = f.WriteString(string(jsonData) + "\n")
+	_, err = f.WriteString(line)
 	return err
 }
@@ -27,11 +27,23 @@ func TestSave(t *testing.T) {
 
 	origOpenFile := OpenFile
 	origEnsureDir := EnsureDir
+	origLockLog := LockLog
 	defer func() {
 		OpenFile = origOpenFile
 		EnsureDir = origEnsureDir
+		LockLog = origLockLog
 	}()
 
+	var gotExclusive bool
+	LockLog = func(logPath string, exclusive bool) (func() error, error) {
+		gotExclusive = exclusive
+		return func() error { return nil }, nil
+	}
+	manyTargets := make([]TargetDuration, 200)
+	for i := range manyTargets {
+		manyTargets[i] = TargetDuration{Name: fmt.Sprintf("out/obj/src/module_%03d.o", i), DurationSec: 1.5}
+	}
+
 	var fakeF *fakeFile
 	OpenFile = func(name string, flag int, perm os.FileMode) (fileWriter, error) {
 		fakeF = &fakeFile{}
@@ -48,11 +60,12 @@ func TestSave(t *testing.T) {
 	}
 
 	tests := []struct {
-		name     string
-		m        BuildMetric
-		filePath string
-		wantErr  bool
-		wantDir  string
+		name          string
+		m             BuildMetric
+		filePath      string
+		wantErr       bool
+		wantDir       string
+		wantExclusive bool
 	}{
 		{
 			name:     "Sucesso salva JSONL",
@@ -61,6 +74,13 @@ func TestSave(t *testing.T) {
 			wantErr:  false,
 			wantDir:  "/tmp",
 		},
+		{
+			name:          "Registro maior que PIPE_BUF usa lock exclusivo",
+			m:             BuildMetric{Project: "C", Timestamp: "2026-01-01T00:00:00Z", Targets: manyTargets},
+			filePath:      "/tmp/test.jsonl",
+			wantDir:       "/tmp",
+			wantExclusive: true,
+		},
 		{
 			name:     "Erro em ensureDir",
 			m:        BuildMetric{Project: "B", Timestamp: "2026-01-02T00:00:00Z"},
@@ -86,6 +106,9 @@ func TestSave(t *testing.T) {
 			if tt.wantErr {
 				t.Fatal("Save() deveria falhar, mas não falhou")
 			}
+			if gotExclusive != tt.wantExclusive {
+				t.Errorf("lock exclusivo = %v, want %v", gotExclusive, tt.wantExclusive)
+			}
 			if fakeF == nil || !fakeF.closed {
 				t.Errorf("Arquivo não foi fechado corretamente")
 			}
@@ -0,0 +1,220 @@
+// Package ninja lê o .ninja_log, onde o ninja anota o início e o fim de cada
+// saída construída, e extrai o tempo de cada alvo do último build.
+package ninja
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"dev-metrics/internal/metrics"
+)
+
+// LogName é o nome do log do ninja no diretório do build.
+const LogName = ".ninja_log"
+
+// DefaultTopTargets é o número padrão de alvos guardados por build.
+const DefaultTopTargets = 50
+
+// Versões do formato com as mesmas colunas (início, fim, mtime, saída, hash).
+const (
+	minVersion = 5
+	maxVersion = 7
+)
+
+// ErrNoLog indica que o diretório não tem .ninja_log.
+var ErrNoLog = errors.New("nenhum " + LogName + " encontrado")
+
+// Entry é uma saída construída, com tempos em milissegundos desde o início do build.
+type Entry struct {
+	Output  string
+	StartMs int64
+	EndMs   int64
+}
+
+// Build são as entradas do último build do log.
+type Build struct {
+	Entries []Entry
+	ModTime time.Time // Última escrita no log, ou seja, o fim do build
+}
+
+// DurationSec é a duração do build, do primeiro início ao último fim.
+func (b Build) DurationSec() float64 {
+	if len(b.Entries) == 0 {
+		return 0
+	}
+	start, end := b.Entries[0].StartMs, b.Entries[0].EndMs
+	for _, e := range b.Entries[1:] {
+		start, end = min(start, e.StartMs), max(end, e.EndMs)
+	}
+	return float64(end-start) / 1000
+}
+
+// Targets retorna as top saídas mais lentas (todas, com top zero).
+func (b Build) Targets(top int) []metrics.TargetDuration {
+	targets := make([]metrics.TargetDuration, 0, len(b.Entries))
+	for _, e := range b.Entries {
+		targets = append(targets, metrics.TargetDuration{Name: e.Output, DurationSec: float64(e.EndMs-e.StartMs) / 1000})
+	}
+	sort.SliceStable(targets, func(i, j int) bool {
+		return targets[i].DurationSec > targets[j].DurationSec
+	})
+	if top > 0 && len(targets) > top {
+		targets = targets[:top]
+	}
+	return targets
+}
+
+// ParseLog lê um .ninja_log e retorna as entradas do último build. O ninja
+// acrescenta as saídas na ordem em que terminam, com tempos relativos ao início
+// de cada build; um fim menor que o anterior marca o começo de um novo build.
+func ParseLog(r io.Reader) ([]Entry, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, nil // Log vazio
+	}
+	header := scanner.Text()
+	version, err := strconv.Atoi(strings.TrimPrefix(header, "# ninja log v"))
+	if err != nil || !strings.HasPrefix(header, "# ninja log v") {
+		return nil, fmt.Errorf("cabeçalho inválido no %s: %q", LogName, header)
+	}
+	if version < minVersion || version > maxVersion {
+		return nil, fmt.Errorf("%s v%d não suportado (versões %d a %d)", LogName, version, minVersion, maxVersion)
+	}
+
+	var entries []Entry
+	index := make(map[string]int) // Posição de cada saída no build atual
+	var lastEnd int64
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) < 5 {
+			continue // Linha truncada por um build interrompido
+		}
+		start, errStart := strconv.ParseInt(fields[0], 10, 64)
+		end, errEnd := strconv.ParseInt(fields[1], 10, 64)
+		if errStart != nil || errEnd != nil || end < start {
+			continue
+		}
+		if end < lastEnd {
+			entries, index = entries[:0], make(map[string]int)
+		}
+		lastEnd = end
+		e := Entry{Output: fields[3], StartMs: start, EndMs: end}
+		if i, ok := index[e.Output]; ok {
+			entries[i] = e // Reconstruída no mesmo build (ex.: restat)
+			continue
+		}
+		index[e.Output] = len(entries)
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// ReadLatest lê o último build do .ninja_log em dir.
+func ReadLatest(dir string) (Build, error) {
+	f, err := os.Open(filepath.Join(dir, LogName))
+	if errors.Is(err, os.ErrNotExist) {
+		return Build{}, fmt.Errorf("%w em %s", ErrNoLog, dir)
+	}
+	if err != nil {
+		return Build{}, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return Build{}, err
+	}
+	entries, err := ParseLog(f)
+	if err != nil {
+		return Build{}, err
+	}
+	return Build{Entries: entries, ModTime: info.ModTime()}, nil
+}
+
+// logState identifica uma versão do .ninja_log. O tamanho não depende da
+// granularidade da data de modificação, que pode ficar antes do início do comando.
+type logState struct {
+	exists  bool
+	size    int64
+	modTime time.Time
+}
+
+func statLog(dir string) logState {
+	info, err := os.Stat(filepath.Join(dir, LogName))
+	if err != nil {
+		return logState{}
+	}
+	return logState{exists: true, size: info.Size(), modTime: info.ModTime()}
+}
+
+func (s logState) changedFrom(before logState) bool {
+	return s.exists && (!before.exists || s.size != before.size || !s.modTime.Equal(before.modTime))
+}
+
+// Watch guarda o estado do .ninja_log em cada diretório de dirs. A função
+// retornada lê as top saídas mais lentas do último build do primeiro log que
+// mudou desde então; sem nenhum, retorna nil: o comando não usou o ninja ou
+// não havia nada a construir.
+func Watch(dirs []string, top int) func() ([]metrics.TargetDuration, error) {
+	before := make([]logState, len(dirs))
+	for i, dir := range dirs {
+		before[i] = statLog(dir)
+	}
+	return func() ([]metrics.TargetDuration, error) {
+		for i, dir := range dirs {
+			if !statLog(dir).changedFrom(before[i]) {
+				continue
+			}
+			b, err := ReadLatest(dir)
+			if err != nil {
+				return nil, err
+			}
+			return b.Targets(top), nil
+		}
+		return nil, nil
+	}
+}
+
+// BuildDirs adivinha, pelo comando, onde pode estar o .ninja_log: o -C do
+// ninja, o --build do cmake ou, para outros comandos (ex.: um script de build),
+// o diretório atual e ./build.
+func BuildDirs(args []string) []string {
+	if len(args) == 0 {
+		return nil
+	}
+	switch filepath.Base(args[0]) {
+	case "ninja", "ninja-build", "samu":
+		dir := "."
+		for i := 1; i < len(args); i++ {
+			switch a := args[i]; {
+			case a == "--":
+				return []string{dir}
+			case a == "-C" && i+1 < len(args):
+				i++
+				dir = args[i]
+			case strings.HasPrefix(a, "-C"):
+				dir = a[2:]
+			}
+		}
+		return []string{dir}
+	case "cmake":
+		for i := 1; i < len(args)-1; i++ {
+			if args[i] == "--build" {
+				return []string{args[i+1]}
+			}
+		}
+		return nil // Configuração; o ninja ainda não rodou
+	}
+	return []string{".", "build"}
+}
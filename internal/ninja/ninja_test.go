@@ -0,0 +1,135 @@
+package ninja
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"dev-metrics/internal/metrics"
+)
+
+func TestParseLog(t *testing.T) {
+	tests := []struct {
+		name    string
+		log     string
+		want    []Entry
+		wantErr string
+	}{
+		{
+			name: "Último build (v5)",
+			log: "# ninja log v5\n" +
+				"0\t900\t0\ta.o\tdeadbeef\n" +
+				"0\t1500\t0\tb.o\tdeadbeef\n" +
+				"1500\t2000\t0\tapp\tdeadbeef\n" +
+				// Novo build: o fim volta para perto de zero
+				"0\t700\t0\tb.o\tdeadbeef\n" +
+				"700\t1000\t0\tapp\tdeadbeef\n",
+			want: []Entry{{Output: "b.o", StartMs: 0, EndMs: 700}, {Output: "app", StartMs: 700, EndMs: 1000}},
+		},
+		{
+			name: "v7 com linha truncada",
+			log: "# ninja log v7\n" +
+				"10\t400\t1729000000000000000\tlib/x.o\t9a3f00c1b2d4e5f6\n" +
+				"400\t450",
+			want: []Entry{{Output: "lib/x.o", StartMs: 10, EndMs: 400}},
+		},
+		{
+			name: "Saída repetida no mesmo build",
+			log: "# ninja log v6\n" +
+				"0\t100\t0\tgen.h\tab\n" +
+				"0\t300\t0\tgen.h\tab\n",
+			want: []Entry{{Output: "gen.h", StartMs: 0, EndMs: 300}},
+		},
+		{name: "Versão antiga", log: "# ninja log v4\n0 1 0 a.o\n", wantErr: "v4 não suportado"},
+		{name: "Cabeçalho inválido", log: "not a ninja log\n", wantErr: "cabeçalho inválido"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLog(strings.NewReader(tt.log))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseLog() erro = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseLog() erro: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseLog() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuild(t *testing.T) {
+	b := Build{Entries: []Entry{
+		{Output: "a.o", StartMs: 100, EndMs: 600},
+		{Output: "b.o", StartMs: 100, EndMs: 2100},
+		{Output: "app", StartMs: 2100, EndMs: 2300},
+	}}
+	if got := b.DurationSec(); got != 2.2 {
+		t.Errorf("DurationSec() = %v, want 2.2", got)
+	}
+	want := []metrics.TargetDuration{{Name: "b.o", DurationSec: 2}, {Name: "a.o", DurationSec: 0.5}}
+	if got := b.Targets(2); !reflect.DeepEqual(got, want) {
+		t.Errorf("Targets(2) = %+v, want %+v", got, want)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := ReadLatest(dir); !errors.Is(err, ErrNoLog) {
+		t.Errorf("ReadLatest() sem log = %v, want ErrNoLog", err)
+	}
+	path := filepath.Join(dir, LogName)
+	write := func(content string) {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// O log é criado pelo comando
+	targets := Watch([]string{"/nao/existe", dir}, 0)
+	write("# ninja log v5\n0\t1000\t0\ta.o\tff\n")
+	got, err := targets()
+	if want := []metrics.TargetDuration{{Name: "a.o", DurationSec: 1}}; err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("targets() = %+v, %v; want %+v", got, err, want)
+	}
+
+	// Nada a construir: o log não muda
+	targets = Watch([]string{dir}, 0)
+	if got, err := targets(); got != nil || err != nil {
+		t.Errorf("targets() com log inalterado = %v, %v", got, err)
+	}
+
+	// Novo build acrescentado
+	targets = Watch([]string{dir}, 0)
+	write("# ninja log v5\n0\t1000\t0\ta.o\tff\n0\t500\t0\ta.o\tff\n")
+	got, err = targets()
+	if want := []metrics.TargetDuration{{Name: "a.o", DurationSec: 0.5}}; err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("targets() após novo build = %+v, %v; want %+v", got, err, want)
+	}
+}
+
+func TestBuildDirs(t *testing.T) {
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"ninja"}, []string{"."}},
+		{[]string{"ninja", "-C", "out/Release", "chrome"}, []string{"out/Release"}},
+		{[]string{"/usr/bin/ninja", "-Cbuild"}, []string{"build"}},
+		{[]string{"cmake", "--build", "build", "-j8"}, []string{"build"}},
+		{[]string{"cmake", "-S", ".", "-B", "build", "-G", "Ninja"}, nil},
+		{[]string{"./build.sh"}, []string{".", "build"}},
+	}
+	for _, tt := range tests {
+		if got := BuildDirs(tt.args); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("BuildDirs(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+// Package notify avisa o usuário quando uma execução longa termina, por
+// notificação do desktop, sequências de escape do terminal, o sino do terminal
+// ou um comando próprio.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Backends aceitos em Settings.Backends.
+const (
+	BackendAuto    = "auto"    // desktop numa sessão gráfica, senão osc9; mais o sino
+	BackendDesktop = "desktop" // notify-send, ou D-Bus via gdbus
+	BackendOSC9    = "osc9"    // OSC 9 (iTerm2, WezTerm, Windows Terminal, ...)
+	BackendOSC777  = "osc777"  // OSC 777 (rxvt-unicode, foot, Ghostty, ...)
+	BackendBell    = "bell"    // sino do terminal (BEL)
+	BackendCommand = "command" // Settings.Command, com a mensagem em variáveis de ambiente
+)
+
+// DefaultTimeout limita cada comando externo de notificação.
+const DefaultTimeout = 5 * time.Second
+
+// Settings é a seção notify de config.json.
+type Settings struct {
+	After    string   `json:"after,omitempty"`    // Duração mínima para notificar (ex.: "2m"); vazio desativa
+	Backends []string `json:"backends,omitempty"` // Vazio usa auto (e command, se houver Command)
+	Command  []string `json:"command,omitempty"`  // Executado pelo backend command, sem shell
+	// Webhooks são avisados conforme as próprias condições, independente de After
+	Webhooks []Webhook `json:"webhooks,omitempty"`
+}
+
+// Threshold interpreta After. Sem After, retorna zero.
+func (s Settings) Threshold() (time.Duration, error) {
+	if s.After == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s.After)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("notify.after inválido: %q (use, por exemplo, 2m ou 90s)", s.After)
+	}
+	return d, nil
+}
+
+// backends retorna os backends a usar, validados.
+func (s Settings) backends() ([]string, error) {
+	backends := s.Backends
+	if len(backends) == 0 {
+		backends = []string{BackendAuto}
+		if len(s.Command) > 0 {
+			backends = append(backends, BackendCommand)
+		}
+	}
+	for _, b := range backends {
+		switch b {
+		case BackendAuto, BackendDesktop, BackendOSC9, BackendOSC777, BackendBell:
+		case BackendCommand:
+			if len(s.Command) == 0 {
+				return nil, errors.New("backend command sem notify.command")
+			}
+		default:
+			return nil, fmt.Errorf("backend de notificação desconhecido: %q (use auto|desktop|osc9|osc777|bell|command)", b)
+		}
+	}
+	return backends, nil
+}
+
+// Message é uma notificação de fim de execução. Além do texto, traz os dados
+// da execução para comandos próprios e webhooks.
+type Message struct {
+	Title       string  `json:"title"`
+	Body        string  `json:"body"`
+	Status      string  `json:"status"` // success, failure ou interrupted
+	ExitCode    int     `json:"exit_code"`
+	DurationSec float64 `json:"duration_sec"`
+	MedianSec   float64 `json:"median_sec"` // Mediana das execuções anteriores; zero sem histórico
+	Project     string  `json:"project"`
+	Branch      string  `json:"branch,omitempty"`
+	Commit      string  `json:"commit,omitempty"`
+	Hostname    string  `json:"hostname,omitempty"`
+	Command     string  `json:"command"`
+	RunID       string  `json:"run_id"`
+}
+
+// Env retorna as variáveis de ambiente passadas ao backend command.
+func (m Message) Env() []string {
+	return []string{
+		"BMT_NOTIFY_TITLE=" + m.Title,
+		"BMT_NOTIFY_BODY=" + m.Body,
+		"BMT_STATUS=" + m.Status,
+		"BMT_EXIT_CODE=" + strconv.Itoa(m.ExitCode),
+		"BMT_DURATION_SEC=" + strconv.FormatFloat(m.DurationSec, 'f', 1, 64),
+		"BMT_MEDIAN_SEC=" + strconv.FormatFloat(m.MedianSec, 'f', 1, 64),
+		"BMT_PROJECT=" + m.Project,
+		"BMT_COMMAND=" + m.Command,
+		"BMT_RUN_ID=" + m.RunID,
+	}
+}
+
+// Sender envia mensagens pelos backends. Os campos permitem substituir o
+// ambiente nos testes.
+type Sender struct {
+	Terminal io.Writer // Recebe as sequências OSC e o sino; nil se não houver terminal
+	Getenv   func(string) string
+	LookPath func(string) (string, error)
+	// Run executa um comando externo com env acrescentado ao ambiente atual
+	Run func(ctx context.Context, args []string, env []string) error
+}
+
+// NewSender cria um Sender que escreve no terminal informado (ou em nenhum, se nil).
+func NewSender(terminal io.Writer) *Sender {
+	return &Sender{Terminal: terminal, Getenv: os.Getenv, LookPath: exec.LookPath, Run: runCommand}
+}
+
+func runCommand(ctx context.Context, args []string, env []string) error {
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Env = append(os.Environ(), env...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("%s: %v: %s", args[0], err, msg)
+		}
+		return fmt.Errorf("%s: %v", args[0], err)
+	}
+	return nil
+}
+
+// Send envia m por todos os backends de s e junta os erros.
+func (s *Sender) Send(settings Settings, m Message) error {
+	backends, err := settings.backends()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, b := range backends {
+		if err := s.send(b, settings, m); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", b, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Sender) send(backend string, settings Settings, m Message) error {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	switch backend {
+	case BackendAuto:
+		var err error
+		if s.graphical() && s.desktopTool() != "" {
+			err = s.desktop(ctx, m)
+		} else if s.Terminal != nil {
+			err = s.osc9(m)
+		}
+		if s.Terminal != nil {
+			s.bell()
+		}
+		return err
+	case BackendDesktop:
+		return s.desktop(ctx, m)
+	case BackendOSC9, BackendOSC777, BackendBell:
+		if s.Terminal == nil {
+			return errors.New("stderr não é um terminal")
+		}
+		switch backend {
+		case BackendOSC9:
+			return s.osc9(m)
+		case BackendOSC777:
+			return s.osc777(m)
+		}
+		return s.bell()
+	case BackendCommand:
+		return s.Run(ctx, settings.Command, m.Env())
+	}
+	return fmt.Errorf("backend desconhecido")
+}
+
+// graphical indica se há uma sessão gráfica para notificações do desktop.
+func (s *Sender) graphical() bool {
+	return s.Getenv("DISPLAY") != "" || s.Getenv("WAYLAND_DISPLAY") != ""
+}
+
+// desktopTool retorna o executável disponível para notificações do desktop.
+func (s *Sender) desktopTool() string {
+	for _, tool := range []string{"notify-send", "gdbus"} {
+		if _, err := s.LookPath(tool); err == nil {
+			return tool
+		}
+	}
+	return ""
+}
+
+func (s *Sender) desktop(ctx context.Context, m Message) error {
+	urgency := "normal"
+	if m.Status != "success" {
+		urgency = "critical"
+	}
+	switch s.desktopTool() {
+	case "notify-send":
+		return s.Run(ctx, []string{"notify-send", "--app-name=bmt", "--urgency=" + urgency, m.Title, m.Body}, nil)
+	case "gdbus":
+		// org.freedesktop.Notifications.Notify(app, replaces_id, icon, summary, body, actions, hints, timeout)
+		return s.Run(ctx, []string{"gdbus", "call", "--session",
+			"--dest", "org.freedesktop.Notifications",
+			"--object-path", "/org/freedesktop/Notifications",
+			"--method", "org.freedesktop.Notifications.Notify",
+			"bmt", "0", gvariantString(""), gvariantString(m.Title), gvariantString(m.Body), "[]", "{}", "-1"}, nil)
+	}
+	return errors.New("notify-send e gdbus não encontrados")
+}
+
+func (s *Sender) osc9(m Message) error {
+	_, err := fmt.Fprintf(s.Terminal, "\033]9;%s: %s\007", sanitize(m.Title), sanitize(m.Body))
+	return err
+}
+
+func (s *Sender) osc777(m Message) error {
+	// O título é delimitado por ';' na sequência
+	title := strings.ReplaceAll(sanitize(m.Title), ";", ",")
+	_, err := fmt.Fprintf(s.Terminal, "\033]777;notify;%s;%s\007", title, sanitize(m.Body))
+	return err
+}
+
+func (s *Sender) bell() error {
+	_, err := io.WriteString(s.Terminal, "\a")
+	return err
+}
+
+// sanitize junta as linhas e remove caracteres de controle, que encerrariam
+// ou corromperiam uma sequência de escape.
+func sanitize(text string) string {
+	text = strings.Join(strings.Fields(strings.ReplaceAll(text, "\n", " · ")), " ")
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, text)
+}
+
+// gvariantString escreve s como uma string GVariant entre aspas simples.
+func gvariantString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return "'" + strings.ReplaceAll(s, "'", `\'`) + "'"
+}
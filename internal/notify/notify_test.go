@@ -0,0 +1,157 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSender registra os comandos executados em vez de executá-los.
+func fakeSender(terminal *bytes.Buffer, env map[string]string, tools ...string) (*Sender, *[][]string) {
+	var calls [][]string
+	s := &Sender{
+		Getenv: func(k string) string { return env[k] },
+		LookPath: func(name string) (string, error) {
+			for _, t := range tools {
+				if t == name {
+					return "/usr/bin/" + name, nil
+				}
+			}
+			return "", errors.New("não encontrado")
+		},
+		Run: func(ctx context.Context, args []string, env []string) error {
+			calls = append(calls, append(append([]string(nil), args...), env...))
+			return nil
+		},
+	}
+	if terminal != nil {
+		s.Terminal = terminal
+	}
+	return s, &calls
+}
+
+func TestSettingsThreshold(t *testing.T) {
+	tests := []struct {
+		after   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"2m", 2 * time.Minute, false},
+		{"90s", 90 * time.Second, false},
+		{"dois minutos", 0, true},
+		{"-1m", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := Settings{After: tt.after}.Threshold()
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("Threshold(%q) = %v, %v; want %v, erro %v", tt.after, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestSend(t *testing.T) {
+	msg := Message{Title: "bmt: make falhou", Body: "Duração: 5min00s\nProjeto: backend", Status: "failure", ExitCode: 2, DurationSec: 300, RunID: "01ABC"}
+	graphical := map[string]string{"DISPLAY": ":0"}
+	tests := []struct {
+		name         string
+		settings     Settings
+		env          map[string]string
+		tools        []string
+		noTerminal   bool
+		wantTerminal string
+		wantCalls    [][]string
+		wantErr      string
+	}{
+		{
+			name:         "Auto numa sessão gráfica",
+			env:          graphical,
+			tools:        []string{"notify-send"},
+			wantTerminal: "\a",
+			wantCalls:    [][]string{{"notify-send", "--app-name=bmt", "--urgency=critical", msg.Title, msg.Body}},
+		},
+		{
+			name:         "Auto sem sessão gráfica usa OSC 9",
+			tools:        []string{"notify-send"},
+			wantTerminal: "\033]9;bmt: make falhou: Duração: 5min00s · Projeto: backend\007\a",
+		},
+		{
+			name:       "Auto sem terminal nem desktop",
+			noTerminal: true,
+		},
+		{
+			name:      "Desktop via gdbus",
+			settings:  Settings{Backends: []string{BackendDesktop}},
+			env:       graphical,
+			tools:     []string{"gdbus"},
+			wantCalls: [][]string{{"gdbus", "call", "--session", "--dest", "org.freedesktop.Notifications", "--object-path", "/org/freedesktop/Notifications", "--method", "org.freedesktop.Notifications.Notify", "bmt", "0", "''", "'bmt: make falhou'", "'Duração: 5min00s\nProjeto: backend'", "[]", "{}", "-1"}},
+		},
+		{
+			name:     "Desktop sem ferramentas",
+			settings: Settings{Backends: []string{BackendDesktop}},
+			wantErr:  "notify-send e gdbus não encontrados",
+		},
+		{
+			name:         "OSC 777 e sino",
+			settings:     Settings{Backends: []string{BackendOSC777, BackendBell}},
+			wantTerminal: "\033]777;notify;bmt: make falhou;Duração: 5min00s · Projeto: backend\007\a",
+		},
+		{
+			name:       "OSC sem terminal",
+			settings:   Settings{Backends: []string{BackendOSC9}},
+			noTerminal: true,
+			wantErr:    "stderr não é um terminal",
+		},
+		{
+			name:         "Comando próprio",
+			settings:     Settings{Command: []string{"ntfy", "publish", "builds"}},
+			wantTerminal: "\033]9;bmt: make falhou: Duração: 5min00s · Projeto: backend\007\a",
+			wantCalls:    [][]string{append([]string{"ntfy", "publish", "builds"}, msg.Env()...)},
+		},
+		{
+			name:     "Command sem comando",
+			settings: Settings{Backends: []string{BackendCommand}},
+			wantErr:  "backend command sem notify.command",
+		},
+		{
+			name:     "Backend desconhecido",
+			settings: Settings{Backends: []string{"pombo"}},
+			wantErr:  "desconhecido",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var terminal bytes.Buffer
+			term := &terminal
+			if tt.noTerminal {
+				term = nil
+			}
+			s, calls := fakeSender(term, tt.env, tt.tools...)
+			err := s.Send(tt.settings, msg)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("Send() erro: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("Send() erro = %v, want %q", err, tt.wantErr)
+			}
+			if terminal.String() != tt.wantTerminal {
+				t.Errorf("terminal = %q, want %q", terminal.String(), tt.wantTerminal)
+			}
+			if len(*calls) != 0 || len(tt.wantCalls) != 0 {
+				if !reflect.DeepEqual(*calls, tt.wantCalls) {
+					t.Errorf("comandos = %q, want %q", *calls, tt.wantCalls)
+				}
+			}
+		})
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	if got := sanitize("a\x1b]0;x\x07b\nc"); got != "a]0;xb · c" {
+		t.Errorf("sanitize() = %q", got)
+	}
+}
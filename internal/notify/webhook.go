@@ -0,0 +1,334 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Presets de payload de Webhook.Preset.
+const (
+	PresetJSON  = "json"  // o evento completo em JSON (padrão)
+	PresetSlack = "slack" // {"text": ...}, aceito por Slack, Mattermost, Rocket.Chat e afins
+)
+
+// Presets são os templates usados quando o webhook não informa template.
+var Presets = map[string]string{
+	PresetJSON:  `{{json .}}`,
+	PresetSlack: `{"text": {{json (printf "*%s*\n%s\nMotivo: %s" .Title .Body (join .Reasons ", "))}}}`,
+}
+
+// DefaultBackoff são as esperas entre as tentativas de entrega de um webhook
+// que respondeu 429 ou 5xx.
+var DefaultBackoff = []time.Duration{time.Second, 2 * time.Second, 4 * time.Second}
+
+// WebhookTimeout limita cada requisição.
+const WebhookTimeout = 5 * time.Second
+
+// DefaultMaxDelivery limita o tempo gasto com as tentativas de cada entrega;
+// o envio é síncrono, no fim do bmt run.
+const DefaultMaxDelivery = 5 * time.Second
+
+// MaxSpooled limita quantas entregas ficam guardadas; as mais antigas são descartadas.
+const MaxSpooled = 200
+
+// Webhook é um destino HTTP avisado quando uma execução atende a On.
+type Webhook struct {
+	Name     string            `json:"name,omitempty"`
+	URL      string            `json:"url"`
+	Preset   string            `json:"preset,omitempty"`   // json (padrão) ou slack
+	Template string            `json:"template,omitempty"` // text/template do corpo; tem prioridade sobre o preset
+	Headers  map[string]string `json:"headers,omitempty"`  // Content-Type padrão: application/json
+	On       Conditions        `json:"on"`
+}
+
+// Conditions define quando um webhook é avisado; basta uma ser atendida.
+type Conditions struct {
+	Failure      bool    `json:"failure,omitempty"`       // A execução falhou
+	SlowerThan   string  `json:"slower_than,omitempty"`   // Duração acima deste valor (ex.: "10m")
+	MedianFactor float64 `json:"median_factor,omitempty"` // Duração acima de median_factor × a mediana do mesmo comando
+}
+
+// Event é o dado passado ao template de um webhook.
+type Event struct {
+	Message
+	Webhook string   `json:"webhook,omitempty"`
+	Reasons []string `json:"reasons"` // Condições atendidas, em texto
+}
+
+func (w Webhook) label() string {
+	if w.Name != "" {
+		return w.Name
+	}
+	return w.URL
+}
+
+// Validate verifica a configuração do webhook.
+func (w Webhook) Validate() error {
+	if w.URL == "" {
+		return fmt.Errorf("webhook %s: url é obrigatória", w.label())
+	}
+	if w.Template == "" && w.Preset != "" && Presets[w.Preset] == "" {
+		return fmt.Errorf("webhook %s: preset desconhecido %q (use json|slack)", w.label(), w.Preset)
+	}
+	if _, err := w.template(); err != nil {
+		return fmt.Errorf("webhook %s: template inválido: %v", w.label(), err)
+	}
+	if !w.On.Failure && w.On.SlowerThan == "" && w.On.MedianFactor <= 0 {
+		return fmt.Errorf("webhook %s: informe ao menos uma condição em on (failure, slower_than ou median_factor)", w.label())
+	}
+	if w.On.SlowerThan != "" {
+		if d, err := time.ParseDuration(w.On.SlowerThan); err != nil || d <= 0 {
+			return fmt.Errorf("webhook %s: slower_than inválido: %q", w.label(), w.On.SlowerThan)
+		}
+	}
+	return nil
+}
+
+func (w Webhook) template() (*template.Template, error) {
+	text := w.Template
+	if text == "" {
+		preset := w.Preset
+		if preset == "" {
+			preset = PresetJSON
+		}
+		text = Presets[preset]
+	}
+	return template.New(w.label()).Funcs(template.FuncMap{
+		"json": func(v any) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+		"join": strings.Join,
+	}).Parse(text)
+}
+
+// Match retorna as condições atendidas por m, em texto. Uma configuração
+// inválida já foi recusada por Validate.
+func (c Conditions) Match(m Message) []string {
+	var reasons []string
+	if c.Failure && m.Status == "failure" {
+		reasons = append(reasons, fmt.Sprintf("falhou com código %d", m.ExitCode))
+	}
+	if d, err := time.ParseDuration(c.SlowerThan); err == nil && d > 0 && m.DurationSec > d.Seconds() {
+		reasons = append(reasons, "duração acima de "+c.SlowerThan)
+	}
+	if c.MedianFactor > 0 && m.MedianSec > 0 && m.DurationSec > c.MedianFactor*m.MedianSec {
+		reasons = append(reasons, fmt.Sprintf("%.1fx a mediana (limite %gx)", m.DurationSec/m.MedianSec, c.MedianFactor))
+	}
+	return reasons
+}
+
+// spooled é uma entrega guardada para reenvio.
+type spooled struct {
+	Webhook string            `json:"webhook"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body"`
+	Created time.Time         `json:"created"`
+}
+
+// WebhookSender entrega eventos aos webhooks, com novas tentativas e um spool
+// local para entregas que falharam por falta de rede.
+type WebhookSender struct {
+	Client      *http.Client
+	SpoolDir    string          // Vazio desativa o spool
+	Backoff     []time.Duration // Esperas entre tentativas
+	MaxDelivery time.Duration   // Tempo máximo de tentativas por entrega; zero não limita
+	Sleep       func(time.Duration)
+	Now         func() time.Time
+}
+
+// NewWebhookSender cria um WebhookSender com os padrões e o spool em spoolDir.
+func NewWebhookSender(spoolDir string) *WebhookSender {
+	return &WebhookSender{
+		Client:      &http.Client{Timeout: WebhookTimeout},
+		SpoolDir:    spoolDir,
+		Backoff:     DefaultBackoff,
+		MaxDelivery: DefaultMaxDelivery,
+		Sleep:       time.Sleep,
+		Now:         time.Now,
+	}
+}
+
+// DefaultSpoolDir retorna o diretório do spool (~/.cache/bmt/webhook-spool).
+func DefaultSpoolDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "bmt", "webhook-spool")
+}
+
+// Send reenvia o spool e depois avisa cada webhook cujas condições m atende.
+// Entregas que falham por rede ou erro do servidor vão para o spool; sem
+// conexão (o reenvio do spool falhou por rede), vão direto, sem tentar.
+func (s *WebhookSender) Send(hooks []Webhook, m Message) error {
+	var errs []error
+	offline, err := s.flush()
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, w := range hooks {
+		if err := w.Validate(); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		reasons := w.On.Match(m)
+		if len(reasons) == 0 {
+			continue
+		}
+		tmpl, _ := w.template()
+		var body bytes.Buffer
+		if err := tmpl.Execute(&body, Event{Message: m, Webhook: w.Name, Reasons: reasons}); err != nil {
+			errs = append(errs, fmt.Errorf("webhook %s: %v", w.label(), err))
+			continue
+		}
+		entry := spooled{Webhook: w.label(), URL: w.URL, Headers: w.Headers, Body: body.Bytes(), Created: s.Now()}
+		retryable, err := true, fmt.Errorf("webhook %s: sem conexão", entry.Webhook)
+		if !offline {
+			retryable, err = s.deliver(entry, len(s.Backoff))
+		}
+		if err == nil {
+			continue
+		}
+		if retryable && s.SpoolDir != "" {
+			if spoolErr := s.spool(entry); spoolErr != nil {
+				err = errors.Join(err, spoolErr)
+			} else {
+				err = fmt.Errorf("%v; guardado para reenvio", err)
+			}
+		}
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// deliver envia a requisição, tentando de novo até retries vezes, dentro de
+// MaxDelivery, quando o servidor responde 429 ou 5xx. Falhas de rede (DNS,
+// conexão, timeout) não são repetidas: sem rede, as esperas só atrasariam o
+// fim do comando. retryable indica se vale guardar para depois.
+func (s *WebhookSender) deliver(e spooled, retries int) (retryable bool, err error) {
+	start := s.Now()
+	for attempt := 0; ; attempt++ {
+		retryable, err = s.post(e)
+		if err == nil || !retryable || attempt >= retries || isNetworkError(err) {
+			return retryable, err
+		}
+		wait := s.Backoff[min(attempt, len(s.Backoff)-1)]
+		if s.MaxDelivery > 0 && s.Now().Add(wait).Sub(start) > s.MaxDelivery {
+			return retryable, err
+		}
+		s.Sleep(wait)
+	}
+}
+
+// isNetworkError indica se a requisição nem obteve resposta.
+func isNetworkError(err error) bool {
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+func (s *WebhookSender) post(e spooled) (retryable bool, err error) {
+	req, err := http.NewRequest(http.MethodPost, e.URL, bytes.NewReader(e.Body))
+	if err != nil {
+		return false, fmt.Errorf("webhook %s: %v", e.Webhook, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "bmt")
+	for k, v := range e.Headers {
+		req.Header.Set(k, os.ExpandEnv(v)) // Permite tokens em variáveis de ambiente
+	}
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("webhook %s: %w", e.Webhook, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retryable = resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retryable, fmt.Errorf("webhook %s: resposta %s", e.Webhook, resp.Status)
+}
+
+// spool guarda uma entrega, descartando as mais antigas acima de MaxSpooled.
+func (s *WebhookSender) spool(e spooled) error {
+	if err := os.MkdirAll(s.SpoolDir, 0o700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	// O nome ordena as entregas por criação
+	name := fmt.Sprintf("%s-%d.json", e.Created.UTC().Format("20060102T150405.000000000"), os.Getpid())
+	if err := os.WriteFile(filepath.Join(s.SpoolDir, name), data, 0o600); err != nil {
+		return err
+	}
+	files, _ := s.spooledFiles()
+	for len(files) > MaxSpooled {
+		os.Remove(files[0])
+		files = files[1:]
+	}
+	return nil
+}
+
+func (s *WebhookSender) spooledFiles() ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(s.SpoolDir, "*.json"))
+	sort.Strings(files)
+	return files, err
+}
+
+// Flush reenvia as entregas guardadas, da mais antiga para a mais nova, uma
+// tentativa cada. Para na primeira falha de rede, que indica que ainda não há
+// conexão; entregas recusadas pelo servidor são descartadas.
+func (s *WebhookSender) Flush() error {
+	_, err := s.flush()
+	return err
+}
+
+// flush é o Flush; offline indica que o reenvio parou numa falha de rede.
+func (s *WebhookSender) flush() (offline bool, err error) {
+	if s.SpoolDir == "" {
+		return false, nil
+	}
+	files, err := s.spooledFiles()
+	if err != nil {
+		return false, err
+	}
+	var errs []error
+	for _, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		var e spooled
+		if err := json.Unmarshal(data, &e); err != nil {
+			os.Remove(path)
+			errs = append(errs, fmt.Errorf("spool %s ilegível, descartado: %v", filepath.Base(path), err))
+			continue
+		}
+		retryable, err := s.post(e)
+		if err != nil && retryable {
+			errs = append(errs, fmt.Errorf("%v; %d entrega(s) continuam no spool", err, len(files)))
+			offline = isNetworkError(err)
+			break
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%v; entrega guardada descartada", err))
+		}
+		os.Remove(path)
+		files = files[1:]
+	}
+	return offline, errors.Join(errs...)
+}
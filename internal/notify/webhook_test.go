@@ -0,0 +1,250 @@
+package notify
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// webhookServer registra os corpos recebidos e responde com os status de
+// statuses, em ordem (200 quando acabam).
+func webhookServer(t *testing.T, statuses ...int) (*httptest.Server, *[]string) {
+	t.Helper()
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, r.Header.Get("Authorization")+"|"+string(b))
+		status := http.StatusOK
+		if len(statuses) > 0 {
+			status, statuses = statuses[0], statuses[1:]
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &bodies
+}
+
+func testWebhookSender(spoolDir string) (*WebhookSender, *[]time.Duration) {
+	var sleeps []time.Duration
+	s := NewWebhookSender(spoolDir)
+	s.Sleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+	s.Now = func() time.Time { return time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC) }
+	return s, &sleeps
+}
+
+var slowFailure = Message{Title: "bmt: make falhou (código 2)", Body: "Duração: 5min00s\nProjeto: backend", Status: "failure", ExitCode: 2, DurationSec: 300, MedianSec: 100, Project: "backend", Command: "make", RunID: "01ABC"}
+
+func TestConditionsMatch(t *testing.T) {
+	tests := []struct {
+		name string
+		on   Conditions
+		msg  Message
+		want []string
+	}{
+		{"Falha", Conditions{Failure: true}, slowFailure, []string{"falhou com código 2"}},
+		{"Sucesso não é falha", Conditions{Failure: true}, Message{Status: "success", DurationSec: 300}, nil},
+		{"Acima do limite absoluto", Conditions{SlowerThan: "2m"}, slowFailure, []string{"duração acima de 2m"}},
+		{"Abaixo do limite absoluto", Conditions{SlowerThan: "10m"}, slowFailure, nil},
+		{"Acima de k vezes a mediana", Conditions{MedianFactor: 1.5}, slowFailure, []string{"3.0x a mediana (limite 1.5x)"}},
+		{"Sem histórico não compara com a mediana", Conditions{MedianFactor: 1.5}, Message{Status: "success", DurationSec: 300}, nil},
+		{"Todas", Conditions{Failure: true, SlowerThan: "1m", MedianFactor: 2}, slowFailure, []string{"falhou com código 2", "duração acima de 1m", "3.0x a mediana (limite 2x)"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.on.Match(tt.msg); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Match() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWebhookValidate(t *testing.T) {
+	tests := []struct {
+		hook    Webhook
+		wantErr string
+	}{
+		{Webhook{URL: "http://x", On: Conditions{Failure: true}}, ""},
+		{Webhook{On: Conditions{Failure: true}}, "url é obrigatória"},
+		{Webhook{URL: "http://x"}, "ao menos uma condição"},
+		{Webhook{URL: "http://x", Preset: "teams", On: Conditions{Failure: true}}, "preset desconhecido"},
+		{Webhook{URL: "http://x", Template: "{{.Title", On: Conditions{Failure: true}}, "template inválido"},
+		{Webhook{URL: "http://x", On: Conditions{SlowerThan: "dez minutos"}}, "slower_than inválido"},
+	}
+	for _, tt := range tests {
+		err := tt.hook.Validate()
+		if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("Validate(%+v) = %v, want %q", tt.hook, err, tt.wantErr)
+		}
+	}
+}
+
+func TestWebhookSender_Payloads(t *testing.T) {
+	srv, bodies := webhookServer(t)
+	t.Setenv("BMT_TEST_TOKEN", "segredo")
+	s, _ := testWebhookSender("")
+	hooks := []Webhook{
+		{Name: "chat", URL: srv.URL, Preset: PresetSlack, On: Conditions{Failure: true}},
+		{Name: "ci", URL: srv.URL, Headers: map[string]string{"Authorization": "Bearer ${BMT_TEST_TOKEN}"}, On: Conditions{MedianFactor: 2}},
+		{Name: "próprio", URL: srv.URL, Template: `{"msg": {{json .Title}}, "ms": {{printf "%.0f" .DurationSec}}000}`, On: Conditions{Failure: true}},
+		{Name: "lento", URL: srv.URL, On: Conditions{SlowerThan: "1h"}}, // não atendida
+	}
+	if err := s.Send(hooks, slowFailure); err != nil {
+		t.Fatalf("Send() erro: %v", err)
+	}
+	if len(*bodies) != 3 {
+		t.Fatalf("recebidos %d webhooks, want 3: %q", len(*bodies), *bodies)
+	}
+
+	var slack struct{ Text string }
+	if err := json.Unmarshal([]byte(strings.TrimPrefix((*bodies)[0], "|")), &slack); err != nil {
+		t.Fatalf("payload slack inválido: %v", err)
+	}
+	if want := "*bmt: make falhou (código 2)*\nDuração: 5min00s\nProjeto: backend\nMotivo: falhou com código 2"; slack.Text != want {
+		t.Errorf("texto slack = %q, want %q", slack.Text, want)
+	}
+
+	auth, body, _ := strings.Cut((*bodies)[1], "|")
+	if auth != "Bearer segredo" {
+		t.Errorf("Authorization = %q", auth)
+	}
+	var event Event
+	if err := json.Unmarshal([]byte(body), &event); err != nil {
+		t.Fatalf("payload json inválido: %v", err)
+	}
+	if event.Webhook != "ci" || event.RunID != "01ABC" || event.MedianSec != 100 || !reflect.DeepEqual(event.Reasons, []string{"3.0x a mediana (limite 2x)"}) {
+		t.Errorf("evento = %+v", event)
+	}
+
+	if want := `|{"msg": "bmt: make falhou (código 2)", "ms": 300000}`; (*bodies)[2] != want {
+		t.Errorf("template próprio = %q, want %q", (*bodies)[2], want)
+	}
+}
+
+func TestWebhookSender_Retry(t *testing.T) {
+	srv, bodies := webhookServer(t, http.StatusServiceUnavailable, http.StatusBadGateway)
+	s, sleeps := testWebhookSender(t.TempDir())
+	if err := s.Send([]Webhook{{URL: srv.URL, On: Conditions{Failure: true}}}, slowFailure); err != nil {
+		t.Fatalf("Send() erro: %v", err)
+	}
+	if len(*bodies) != 3 {
+		t.Errorf("tentativas = %d, want 3", len(*bodies))
+	}
+	if want := []time.Duration{time.Second, 2 * time.Second}; !reflect.DeepEqual(*sleeps, want) {
+		t.Errorf("esperas = %v, want %v", *sleeps, want)
+	}
+}
+
+func TestWebhookSender_MaxDelivery(t *testing.T) {
+	srv, bodies := webhookServer(t, http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+	dir := t.TempDir()
+	s, sleeps := testWebhookSender(dir)
+	start := s.Now()
+	s.Now = func() time.Time {
+		now := start
+		for _, d := range *sleeps {
+			now = now.Add(d)
+		}
+		return now
+	}
+	s.MaxDelivery = 2 * time.Second
+	err := s.Send([]Webhook{{URL: srv.URL, On: Conditions{Failure: true}}}, slowFailure)
+	if err == nil || !strings.Contains(err.Error(), "guardado para reenvio") {
+		t.Fatalf("Send() erro = %v, want entrega guardada", err)
+	}
+	// A segunda espera (2s) passaria do limite
+	if len(*bodies) != 2 || !reflect.DeepEqual(*sleeps, []time.Duration{time.Second}) {
+		t.Errorf("tentativas = %d, esperas = %v; want 2 e [1s]", len(*bodies), *sleeps)
+	}
+}
+
+func TestWebhookSender_Offline(t *testing.T) {
+	dir := t.TempDir()
+	s, _ := testWebhookSender(dir)
+	offline := httptest.NewServer(http.NotFoundHandler())
+	offline.Close()
+	os.WriteFile(filepath.Join(dir, "20261019T110000.000000000-1.json"), []byte(`{"webhook":"ci","url":"`+offline.URL+`","body":{}}`), 0o600)
+
+	// O reenvio do spool falha por rede: os novos webhooks nem são tentados
+	srv, bodies := webhookServer(t)
+	err := s.Send([]Webhook{{URL: srv.URL, On: Conditions{Failure: true}}}, slowFailure)
+	if err == nil || !strings.Contains(err.Error(), "sem conexão; guardado para reenvio") {
+		t.Fatalf("Send() erro = %v, want entrega guardada sem tentativa", err)
+	}
+	if len(*bodies) != 0 {
+		t.Errorf("recebidos = %q, want nenhum", *bodies)
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "*.json")); len(files) != 2 {
+		t.Errorf("spool = %v, want 2 entregas", files)
+	}
+}
+
+func TestWebhookSender_ClientErrorIsNotRetried(t *testing.T) {
+	srv, bodies := webhookServer(t, http.StatusBadRequest)
+	dir := t.TempDir()
+	s, _ := testWebhookSender(dir)
+	err := s.Send([]Webhook{{URL: srv.URL, On: Conditions{Failure: true}}}, slowFailure)
+	if err == nil || !strings.Contains(err.Error(), "400") {
+		t.Fatalf("Send() erro = %v, want 400", err)
+	}
+	if len(*bodies) != 1 {
+		t.Errorf("tentativas = %d, want 1", len(*bodies))
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "*.json")); len(files) != 0 {
+		t.Errorf("spool = %v, want vazio", files)
+	}
+}
+
+func TestWebhookSender_Spool(t *testing.T) {
+	dir := t.TempDir()
+	s, sleeps := testWebhookSender(dir)
+
+	// Sem conexão: a entrega vai para o spool sem novas tentativas
+	offline := httptest.NewServer(http.NotFoundHandler())
+	offline.Close()
+	hook := Webhook{Name: "ci", URL: offline.URL, Headers: map[string]string{"Authorization": "Bearer ${BMT_TEST_TOKEN}"}, On: Conditions{Failure: true}}
+	err := s.Send([]Webhook{hook}, slowFailure)
+	if err == nil || !strings.Contains(err.Error(), "guardado para reenvio") {
+		t.Fatalf("Send() erro = %v, want entrega guardada", err)
+	}
+	if len(*sleeps) != 0 {
+		t.Errorf("esperas = %v, want nenhuma para falha de rede", *sleeps)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 1 {
+		t.Fatalf("spool = %v, want 1 entrega", files)
+	}
+	data, _ := os.ReadFile(files[0])
+	if strings.Contains(string(data), "segredo") {
+		t.Error("o spool não deve guardar variáveis de ambiente expandidas")
+	}
+
+	// Com conexão: a entrega guardada é reenviada antes da nova
+	t.Setenv("BMT_TEST_TOKEN", "segredo")
+	srv, bodies := webhookServer(t)
+	var spooledEntry spooled
+	json.Unmarshal(data, &spooledEntry)
+	spooledEntry.URL = srv.URL
+	data, _ = json.Marshal(spooledEntry)
+	os.WriteFile(files[0], data, 0o600)
+
+	hook.URL = srv.URL
+	if err := s.Send([]Webhook{hook}, Message{Status: "failure", ExitCode: 1, RunID: "02DEF"}); err != nil {
+		t.Fatalf("Send() erro: %v", err)
+	}
+	if len(*bodies) != 2 || !strings.Contains((*bodies)[0], "01ABC") || !strings.Contains((*bodies)[1], "02DEF") {
+		t.Errorf("recebidos = %q, want a entrega guardada e depois a nova", *bodies)
+	}
+	if !strings.HasPrefix((*bodies)[0], "Bearer segredo|") {
+		t.Errorf("reenvio sem o cabeçalho expandido: %q", (*bodies)[0])
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "*.json")); len(files) != 0 {
+		t.Errorf("spool = %v, want vazio", files)
+	}
+}
@@ -0,0 +1,203 @@
+package probe
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Parser extrai contadores numéricos da saída de uma probe.
+type Parser func(output []byte) (map[string]float64, error)
+
+// Parsers disponíveis por nome, para uso na configuração.
+var Parsers = map[string]Parser{
+	"ccache":  ParseCcache,
+	"sccache": ParseSccache,
+	"kv":      ParseKeyValue,
+	"json":    ParseJSON,
+}
+
+var errNoCounters = errors.New("nenhum contador numérico encontrado na saída")
+
+// ParseCcache interpreta `ccache --print-stats` (ccache 4.x, "chave<TAB>valor")
+// e, como alternativa, o texto de `ccache -s` das versões antigas.
+func ParseCcache(output []byte) (map[string]float64, error) {
+	raw := make(map[string]float64)
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		var key, value string
+		if k, v, ok := strings.Cut(line, "\t"); ok {
+			key, value = k, v
+		} else if i := strings.LastIndexAny(line, " "); i > 0 {
+			// "cache hit (direct)                  12"
+			key, value = strings.TrimSpace(line[:i]), line[i+1:]
+		} else {
+			continue
+		}
+		if v, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+			raw[strings.ToLower(key)] = v
+		}
+	}
+
+	out := make(map[string]float64)
+	hits, hasHits := sumKeys(raw, "direct_cache_hit", "preprocessed_cache_hit", "cache hit (direct)", "cache hit (preprocessed)")
+	misses, hasMisses := sumKeys(raw, "cache_miss", "cache miss")
+	if hasHits {
+		out[KeyHits] = hits
+	}
+	if hasMisses {
+		out[KeyMisses] = misses
+	}
+	if kib, ok := raw["cache_size_kibibyte"]; ok {
+		out[KeyCacheSizeBytes] = kib * 1024
+	}
+	if len(out) == 0 {
+		return nil, errNoCounters
+	}
+	return out, nil
+}
+
+// ParseSccache interpreta `sccache --show-stats --stats-format=json` e, como
+// alternativa, o texto padrão de `sccache --show-stats`.
+func ParseSccache(output []byte) (map[string]float64, error) {
+	if trimmed := bytes.TrimSpace(output); len(trimmed) > 0 && trimmed[0] == '{' {
+		return parseSccacheJSON(trimmed)
+	}
+
+	out := make(map[string]float64)
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 {
+			continue
+		}
+		label := strings.ToLower(fields[0] + " " + fields[1])
+		switch {
+		case label == "cache hits" && len(fields) == 3:
+			out[KeyHits], _ = strconv.ParseFloat(fields[2], 64)
+		case label == "cache misses" && len(fields) == 3:
+			out[KeyMisses], _ = strconv.ParseFloat(fields[2], 64)
+		case label == "cache size" && len(fields) == 4:
+			if size, err := parseSize(fields[2], fields[3]); err == nil {
+				out[KeyCacheSizeBytes] = size
+			}
+		}
+	}
+	if len(out) == 0 {
+		return nil, errNoCounters
+	}
+	return out, nil
+}
+
+func parseSccacheJSON(data []byte) (map[string]float64, error) {
+	var doc struct {
+		Stats struct {
+			CacheHits   struct{ Counts map[string]float64 } `json:"cache_hits"`
+			CacheMisses struct{ Counts map[string]float64 } `json:"cache_misses"`
+		} `json:"stats"`
+		CacheSize *float64 `json:"cache_size"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	out := map[string]float64{KeyHits: 0, KeyMisses: 0}
+	for _, n := range doc.Stats.CacheHits.Counts {
+		out[KeyHits] += n
+	}
+	for _, n := range doc.Stats.CacheMisses.Counts {
+		out[KeyMisses] += n
+	}
+	if doc.CacheSize != nil {
+		out[KeyCacheSizeBytes] = *doc.CacheSize
+	}
+	return out, nil
+}
+
+// ParseKeyValue interpreta linhas "chave=valor" (ou "chave: valor"),
+// ignorando valores não numéricos.
+func ParseKeyValue(output []byte) (map[string]float64, error) {
+	out := make(map[string]float64)
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			key, value, ok = strings.Cut(line, ":")
+		}
+		if !ok {
+			continue
+		}
+		if v, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+			out[strings.TrimSpace(key)] = v
+		}
+	}
+	if len(out) == 0 {
+		return nil, errNoCounters
+	}
+	return out, nil
+}
+
+// ParseJSON extrai os números de um objeto JSON; objetos aninhados viram
+// chaves com ponto (ex.: {"cache": {"hits": 3}} -> "cache.hits").
+func ParseJSON(output []byte) (map[string]float64, error) {
+	var doc map[string]any
+	if err := json.Unmarshal(output, &doc); err != nil {
+		return nil, err
+	}
+	out := make(map[string]float64)
+	flattenJSON("", doc, out)
+	if len(out) == 0 {
+		return nil, errNoCounters
+	}
+	return out, nil
+}
+
+func flattenJSON(prefix string, v any, out map[string]float64) {
+	switch v := v.(type) {
+	case float64:
+		out[prefix] = v
+	case map[string]any:
+		for k, child := range v {
+			if prefix != "" {
+				k = prefix + "." + k
+			}
+			flattenJSON(k, child, out)
+		}
+	}
+}
+
+// sumKeys soma os valores de keys presentes em m.
+func sumKeys(m map[string]float64, keys ...string) (float64, bool) {
+	var sum float64
+	found := false
+	for _, k := range keys {
+		if v, ok := m[k]; ok {
+			sum += v
+			found = true
+		}
+	}
+	return sum, found
+}
+
+// parseSize converte "1.5" "GiB" em bytes.
+func parseSize(value, unit string) (float64, error) {
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+	units := map[string]float64{
+		"bytes": 1, "b": 1,
+		"kib": 1 << 10, "mib": 1 << 20, "gib": 1 << 30, "tib": 1 << 40,
+		"kb": 1e3, "mb": 1e6, "gb": 1e9, "tb": 1e12,
+	}
+	mult, ok := units[strings.ToLower(unit)]
+	if !ok {
+		return 0, fmt.Errorf("unidade desconhecida: %s", unit)
+	}
+	return v * mult, nil
+}
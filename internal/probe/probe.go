@@ -0,0 +1,106 @@
+// Package probe executa comandos antes e depois da execução medida e extrai
+// contadores numéricos da saída deles (ex.: estatísticas do ccache).
+package probe
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Nomes canônicos usados pelos parsers de cache, para que relatórios
+// comparem ccache, sccache e probes próprias.
+const (
+	KeyHits           = "hits"
+	KeyMisses         = "misses"
+	KeyCacheSizeBytes = "cache_size_bytes"
+)
+
+// DefaultTimeout limita cada execução de uma probe.
+const DefaultTimeout = 10 * time.Second
+
+// Probe é um comando executado antes e depois da execução medida. O delta dos
+// contadores extraídos pelo parser é gravado na métrica.
+type Probe struct {
+	Name    string   `json:"name"`
+	Command []string `json:"command,omitempty"` // Opcional para os parsers com comando padrão
+	Parser  string   `json:"parser"`            // ccache, sccache, kv ou json
+}
+
+// DefaultCommands são os comandos usados quando uma probe de ccache ou
+// sccache não informa command.
+var DefaultCommands = map[string][]string{
+	"ccache":  {"ccache", "--print-stats"},
+	"sccache": {"sccache", "--show-stats", "--stats-format=json"},
+}
+
+func (p Probe) command() []string {
+	if len(p.Command) > 0 {
+		return p.Command
+	}
+	return DefaultCommands[p.Parser]
+}
+
+// Validate verifica se a probe pode ser executada.
+func (p Probe) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("probe sem name")
+	}
+	if _, ok := Parsers[p.Parser]; !ok {
+		return fmt.Errorf("probe %s: parser desconhecido %q (use %s)", p.Name, p.Parser, strings.Join(ParserNames(), "|"))
+	}
+	if len(p.command()) == 0 {
+		return fmt.Errorf("probe %s: command é obrigatório", p.Name)
+	}
+	return nil
+}
+
+// Exec executa um comando e retorna seu stdout.
+type Exec func(ctx context.Context, args []string) ([]byte, error)
+
+// DefaultExec executa o comando diretamente, sem shell.
+func DefaultExec(ctx context.Context, args []string) ([]byte, error) {
+	return exec.CommandContext(ctx, args[0], args[1:]...).Output()
+}
+
+// Snapshot executa a probe e retorna os contadores extraídos.
+func (p Probe) Snapshot(ctx context.Context, run Exec) (map[string]float64, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, DefaultTimeout)
+	defer cancel()
+	out, err := run(ctx, p.command())
+	if err != nil {
+		return nil, fmt.Errorf("probe %s: %v", p.Name, err)
+	}
+	counters, err := Parsers[p.Parser](out)
+	if err != nil {
+		return nil, fmt.Errorf("probe %s: %v", p.Name, err)
+	}
+	return counters, nil
+}
+
+// Delta retorna after - before para os contadores presentes nas duas leituras.
+func Delta(before, after map[string]float64) map[string]float64 {
+	delta := make(map[string]float64)
+	for k, a := range after {
+		if b, ok := before[k]; ok {
+			delta[k] = a - b
+		}
+	}
+	return delta
+}
+
+// ParserNames lista os parsers disponíveis, em ordem alfabética.
+func ParserNames() []string {
+	names := make([]string, 0, len(Parsers))
+	for name := range Parsers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,157 @@
+package probe
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseCcache(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   map[string]float64
+	}{
+		{
+			name: "print-stats (ccache 4.x)",
+			output: "stats_updated_timestamp\t1700000000\n" +
+				"direct_cache_hit\t120\n" +
+				"preprocessed_cache_hit\t30\n" +
+				"cache_miss\t50\n" +
+				"cache_size_kibibyte\t2048\n" +
+				"files_in_cache\t400\n",
+			want: map[string]float64{KeyHits: 150, KeyMisses: 50, KeyCacheSizeBytes: 2048 * 1024},
+		},
+		{
+			name: "ccache -s (versões antigas)",
+			output: "cache directory                     /home/u/.ccache\n" +
+				"cache hit (direct)                    12\n" +
+				"cache hit (preprocessed)               3\n" +
+				"cache miss                             5\n" +
+				"cache size                          1.2 GB\n",
+			want: map[string]float64{KeyHits: 15, KeyMisses: 5},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCcache([]byte(tt.output))
+			if err != nil {
+				t.Fatalf("ParseCcache() erro: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseCcache() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ParseCcache([]byte("ccache: command not found\n")); err == nil {
+		t.Error("ParseCcache() sem contadores deveria falhar")
+	}
+}
+
+func TestParseSccache(t *testing.T) {
+	jsonOut := `{"stats": {"compile_requests": 40,
+		"cache_hits": {"counts": {"C/C++": 10, "Rust": 5}, "adv_counts": {}},
+		"cache_misses": {"counts": {"Rust": 7}, "adv_counts": {}}},
+		"cache_location": "Local disk", "cache_size": 1048576, "max_cache_size": 10737418240}`
+	got, err := ParseSccache([]byte(jsonOut))
+	if err != nil {
+		t.Fatalf("ParseSccache(json) erro: %v", err)
+	}
+	want := map[string]float64{KeyHits: 15, KeyMisses: 7, KeyCacheSizeBytes: 1048576}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseSccache(json) = %v, want %v", got, want)
+	}
+
+	text := "Compile requests                     40\n" +
+		"Cache hits                           15\n" +
+		"Cache hits (C/C++)                   10\n" +
+		"Cache misses                          7\n" +
+		"Cache hits rate                   68.18 %\n" +
+		"Cache size                          1.5 MiB\n"
+	got, err = ParseSccache([]byte(text))
+	if err != nil {
+		t.Fatalf("ParseSccache(texto) erro: %v", err)
+	}
+	want = map[string]float64{KeyHits: 15, KeyMisses: 7, KeyCacheSizeBytes: 1.5 * 1024 * 1024}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseSccache(texto) = %v, want %v", got, want)
+	}
+}
+
+func TestParseKeyValue(t *testing.T) {
+	got, err := ParseKeyValue([]byte("hits=10\nmisses: 2\nversion=v1.2\n# comentário\n"))
+	if err != nil {
+		t.Fatalf("ParseKeyValue() erro: %v", err)
+	}
+	want := map[string]float64{"hits": 10, "misses": 2}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseKeyValue() = %v, want %v", got, want)
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	got, err := ParseJSON([]byte(`{"hits": 3, "cache": {"size": 100, "name": "x"}, "list": [1, 2]}`))
+	if err != nil {
+		t.Fatalf("ParseJSON() erro: %v", err)
+	}
+	want := map[string]float64{"hits": 3, "cache.size": 100}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseJSON() = %v, want %v", got, want)
+	}
+	if _, err := ParseJSON([]byte("não é json")); err == nil {
+		t.Error("ParseJSON() com entrada inválida deveria falhar")
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	var gotArgs []string
+	run := func(ctx context.Context, args []string) ([]byte, error) {
+		gotArgs = args
+		return []byte("direct_cache_hit\t4\ncache_miss\t1\n"), nil
+	}
+	counters, err := Probe{Name: "ccache", Parser: "ccache"}.Snapshot(context.Background(), run)
+	if err != nil {
+		t.Fatalf("Snapshot() erro: %v", err)
+	}
+	if !reflect.DeepEqual(gotArgs, DefaultCommands["ccache"]) {
+		t.Errorf("comando = %v, want o padrão %v", gotArgs, DefaultCommands["ccache"])
+	}
+	if counters[KeyHits] != 4 || counters[KeyMisses] != 1 {
+		t.Errorf("contadores = %v", counters)
+	}
+
+	failing := func(ctx context.Context, args []string) ([]byte, error) { return nil, errors.New("exit status 1") }
+	if _, err := (Probe{Name: "x", Command: []string{"x"}, Parser: "kv"}).Snapshot(context.Background(), failing); err == nil || !strings.Contains(err.Error(), "probe x") {
+		t.Errorf("Snapshot() com comando falhando: erro = %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		probe   Probe
+		wantErr bool
+	}{
+		{Probe{Name: "ccache", Parser: "ccache"}, false},
+		{Probe{Name: "mine", Command: []string{"stats"}, Parser: "json"}, false},
+		{Probe{Name: "mine", Parser: "json"}, true},
+		{Probe{Name: "mine", Command: []string{"stats"}, Parser: "yaml"}, true},
+		{Probe{Command: []string{"stats"}, Parser: "kv"}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.probe.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%+v) erro = %v, wantErr %v", tt.probe, err, tt.wantErr)
+		}
+	}
+}
+
+func TestDelta(t *testing.T) {
+	before := map[string]float64{KeyHits: 10, KeyMisses: 5, KeyCacheSizeBytes: 1000}
+	after := map[string]float64{KeyHits: 25, KeyMisses: 6, KeyCacheSizeBytes: 900, "new": 1}
+	want := map[string]float64{KeyHits: 15, KeyMisses: 1, KeyCacheSizeBytes: -100}
+	if got := Delta(before, after); !reflect.DeepEqual(got, want) {
+		t.Errorf("Delta() = %v, want %v", got, want)
+	}
+}
@@ -2,16 +2,67 @@ package runner
 
 import (
 	"context"
+	"dev-metrics/internal/metrics"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"time"
 )
 
+// Options configura uma execução de RunWithOptions.
+type Options struct {
+	// SampleInterval ativa a amostragem da árvore de processos via procfs (Linux).
+	// Zero desativa.
+	SampleInterval time.Duration
+	// TopTools limita quantos executáveis são retornados em Result.Tools. Zero usa DefaultTopTools.
+	TopTools int
+	// OutputTail é quantos bytes do final da saída (stdout e stderr) guardar em
+	// Result.Output. Zero desativa a captura e liga o comando direto ao terminal.
+	OutputTail int
+	// Phases ativa os marcadores de fase na saída (::bmt-phase name=x::).
+	// Implica a captura da saída.
+	Phases bool
+	// PhaseFD passa ao comando um descritor, anunciado em BMT_PHASE_FD, para
+	// marcadores de fase fora da saída; sozinho, não captura a saída.
+	PhaseFD bool
+	// Status, se não nil, é desenhada enquanto o comando roda. Implica a
+	// captura da saída, para que a linha não se misture a ela.
+	Status *StatusLine
+	// Env é acrescentado ao ambiente herdado pelo comando (ex.: BMT_PARENT_RUN_ID).
+	Env []string
+}
+
+// DefaultTopTools é o número padrão de executáveis guardados no detalhamento por ferramenta.
+const DefaultTopTools = 10
+
+// Result contém o resultado de uma execução.
+type Result struct {
+	DurationSec float64
+	ExitCode    int
+	CPUSec      float64                  // user+sys de toda a árvore (contabilidade final via wait4)
+	Sampling    *metrics.SamplingSummary // nil se a amostragem estiver desativada ou indisponível
+	Samples     []metrics.ProcessSample
+	Tools       []metrics.ToolUsage // CPU por executável, apenas com amostragem
+	IO          *metrics.IOStats    // Bytes de armazenamento sempre (Unix); demais contadores com amostragem
+
+	Output          []byte // Final da saída, com OutputTail
+	OutputTruncated bool   // Parte do início da saída foi descartada
+
+	Phases []metrics.Phase // Se o comando emitiu marcadores de fase
+}
+
 // Run executa o comando e retorna a duração em segundos e o código de saída
 func Run(ctx context.Context, args []string) (float64, int) {
+	res := RunWithOptions(ctx, args, Options{})
+	return res.DurationSec, res.ExitCode
+}
+
+// RunWithOptions executa o comando conforme opts e retorna o resultado da execução.
+func RunWithOptions(ctx context.Context, args []string, opts Options) Result {
 	if len(args) == 0 {
-		return 0, 1
+		return Result{ExitCode: 1}
 	}
 
 	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
@@ -20,21 +71,131 @@ func Run(ctx context.Context, args []string) (float64, int) {
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	cmd.Stdin = os.Stdin
+	if len(opts.Env) > 0 {
+		cmd.Env = append(os.Environ(), opts.Env...)
+	}
 
 	startTime := time.Now()
-	err := cmd.Run()
+
+	var tail *tailBuffer
+	if opts.OutputTail > 0 {
+		tail = newTailBuffer(opts.OutputTail)
+	}
+	var phases *phaseTracker
+	if opts.Phases || opts.PhaseFD {
+		phases = newPhaseTracker(startTime)
+	}
+	if opts.PhaseFD {
+		if err := phases.attachFD(cmd); err != nil {
+			fmt.Fprintf(os.Stderr, "[Metrics Error] %s indisponível: %v\n", EnvPhaseFD, err)
+		}
+	}
+	var capture *outputCapture
+	if tail != nil || opts.Phases || opts.Status != nil {
+		capture = captureOutput(cmd, func() io.Writer {
+			var sinks []io.Writer
+			if tail != nil {
+				sinks = append(sinks, tail)
+			}
+			if opts.Phases {
+				sinks = append(sinks, newLineWriter(phases.outputLine))
+			}
+			return io.MultiWriter(sinks...)
+		}, opts.Status)
+	}
+
+	err := cmd.Start()
+	if capture != nil {
+		capture.started()
+	}
+	if phases != nil {
+		phases.started()
+	}
+	if err == nil && opts.Status != nil {
+		opts.Status.start(startTime)
+	}
+
+	var smp *sampler
+	stop := make(chan struct{})
+	sampled := make(chan struct{})
+	if err == nil && opts.SampleInterval > 0 && samplingSupported() {
+		smp = newSampler(cmd.Process.Pid, opts.SampleInterval, startTime)
+		go func() {
+			defer close(sampled)
+			smp.run(stop)
+		}()
+	} else {
+		close(sampled)
+	}
+
+	if err == nil {
+		err = cmd.Wait()
+		if errors.Is(err, exec.ErrWaitDelay) {
+			// O comando terminou com sucesso; só algum descendente ainda segurava a saída.
+			err = nil
+		}
+	}
 	duration := time.Since(startTime).Seconds()
+	close(stop)
+	<-sampled
+	if capture != nil {
+		capture.finish()
+	}
+	if opts.Status != nil {
+		opts.Status.finish()
+	}
 
-	exitCode := 0
+	res := Result{DurationSec: duration}
+	if cmd.ProcessState != nil {
+		// Inclui os descendentes já aguardados (wait4), mesmo os que nunca foram amostrados.
+		res.CPUSec = (cmd.ProcessState.UserTime() + cmd.ProcessState.SystemTime()).Seconds()
+		if read, write, ok := blockIO(cmd.ProcessState); ok {
+			res.IO = &metrics.IOStats{ReadBytes: read, WriteBytes: write}
+		}
+	}
 	if err != nil {
 		if exitError, ok := err.(*exec.ExitError); ok {
-			exitCode = exitError.ExitCode()
+			res.ExitCode = exitError.ExitCode()
+			if code, ok := signalExitCode(exitError.ProcessState); ok {
+				res.ExitCode = code
+			}
 		} else {
 			// Caso o comando nem seja encontrado
 			fmt.Fprintf(os.Stderr, "Erro ao iniciar processo: %v\n", err)
-			exitCode = 127
+			res.ExitCode = 127
 		}
 	}
 
-	return duration, exitCode
+	if tail != nil {
+		res.Output, res.OutputTruncated = tail.Tail()
+	}
+	if phases != nil {
+		res.Phases = phases.finish(duration)
+	}
+
+	if smp != nil {
+		res.Sampling = smp.summary()
+		res.Samples = smp.samples
+		topN := opts.TopTools
+		if topN <= 0 {
+			topN = DefaultTopTools
+		}
+		res.Tools = smp.tools(topN, res.CPUSec)
+		res.IO = mergeIO(smp.ioStats(), res.IO)
+	}
+	return res
+}
+
+// mergeIO combina os contadores amostrados com a contabilidade final. O rusage
+// inclui processos curtos demais para serem amostrados, então prevalece nos
+// bytes de armazenamento quando for maior.
+func mergeIO(sampled, final *metrics.IOStats) *metrics.IOStats {
+	if sampled == nil {
+		return final
+	}
+	if final != nil {
+		sampled.ReadBytes = max(sampled.ReadBytes, final.ReadBytes)
+		sampled.WriteBytes = max(sampled.WriteBytes, final.WriteBytes)
+	}
+	return sampled
 }
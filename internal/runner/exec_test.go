@@ -3,6 +3,9 @@ package runner_test
 import (
 	"context"
 	"dev-metrics/internal/runner"
+	"os"
+	"runtime"
+	"strings"
 	"testing"
 	"time"
 )
@@ -96,3 +99,134 @@ func TestRun_ContextCancellation(t *testing.T) {
 		t.Errorf("Run() with cancelled context should not return exit code 0")
 	}
 }
+
+func TestRunWithOptions_Sampling(t *testing.T) {
+	if _, err := os.Stat("/proc/self/stat"); err != nil {
+		t.Skip("procfs indisponível")
+	}
+
+	res := runner.RunWithOptions(context.Background(), []string{"sh", "-c", "sleep 0.3; true"}, runner.Options{SampleInterval: 50 * time.Millisecond})
+	if res.ExitCode != 0 {
+		t.Fatalf("ExitCode = %d", res.ExitCode)
+	}
+	if res.Sampling == nil {
+		t.Fatal("Sampling = nil, want resumo da amostragem")
+	}
+	if res.Sampling.Samples == 0 || res.Sampling.PeakProcs < 1 || len(res.Samples) != res.Sampling.Samples {
+		t.Errorf("Sampling = %+v, len(Samples) = %d", res.Sampling, len(res.Samples))
+	}
+	found := false
+	for _, tool := range res.Tools {
+		found = found || tool.Name == "sleep"
+	}
+	if !found {
+		t.Errorf("Tools = %+v, want entrada para sleep", res.Tools)
+	}
+}
+
+func TestRunWithOptions_CPUAccounting(t *testing.T) {
+	// O trabalho acontece num neto (subshell) que nunca é amostrado; a
+	// contabilidade final precisa incluí-lo mesmo assim.
+	res := runner.RunWithOptions(context.Background(), []string{"sh", "-c", "(i=0; while [ $i -lt 20000 ]; do i=$((i+1)); done)"}, runner.Options{})
+	if res.ExitCode != 0 {
+		t.Fatalf("ExitCode = %d", res.ExitCode)
+	}
+	if res.CPUSec <= 0 {
+		t.Errorf("CPUSec = %v, want > 0", res.CPUSec)
+	}
+	if runtime.GOOS != "windows" && res.IO == nil {
+		t.Error("IO = nil, want contadores do rusage")
+	}
+}
+
+func TestRunWithOptions_NoSampling(t *testing.T) {
+	res := runner.RunWithOptions(context.Background(), []string{"true"}, runner.Options{})
+	if res.Sampling != nil || res.Samples != nil {
+		t.Errorf("Sampling deveria ser nil sem SampleInterval: %+v", res.Sampling)
+	}
+}
+
+func TestRunWithOptions_OutputTail(t *testing.T) {
+	res := runner.RunWithOptions(context.Background(), []string{"sh", "-c", "echo saida; echo erro >&2; exit 2"}, runner.Options{OutputTail: 1024})
+	if res.ExitCode != 2 {
+		t.Fatalf("ExitCode = %d, want 2", res.ExitCode)
+	}
+	// stdout e stderr usam pipes separados, então a ordem entre eles não é garantida.
+	if out := string(res.Output); len(out) != len("saida\nerro\n") || !strings.Contains(out, "saida\n") || !strings.Contains(out, "erro\n") || res.OutputTruncated {
+		t.Errorf("Output = %q (truncated %v), want stdout e stderr", res.Output, res.OutputTruncated)
+	}
+
+	res = runner.RunWithOptions(context.Background(), []string{"sh", "-c", "seq 997 1000"}, runner.Options{OutputTail: 12})
+	// Os 12 últimos bytes são "98\n999\n1000\n"; a linha cortada é descartada.
+	if string(res.Output) != "999\n1000\n" || !res.OutputTruncated {
+		t.Errorf("Output = %q (truncated %v), want só as linhas completas", res.Output, res.OutputTruncated)
+	}
+
+	res = runner.RunWithOptions(context.Background(), []string{"true"}, runner.Options{})
+	if res.Output != nil {
+		t.Errorf("Output = %q, want nil sem OutputTail", res.Output)
+	}
+}
+
+func TestRunWithOptions_OutputHeldByBackgroundChild(t *testing.T) {
+	// Um descendente em background herda o stdout; o bmt não deve esperar por ele.
+	start := time.Now()
+	res := runner.RunWithOptions(context.Background(), []string{"sh", "-c", "sleep 5 & echo pronto"}, runner.Options{OutputTail: 1024})
+	if elapsed := time.Since(start); elapsed > 4*time.Second {
+		t.Errorf("RunWithOptions levou %v, want < 4s", elapsed)
+	}
+	if res.ExitCode != 0 || string(res.Output) != "pronto\n" {
+		t.Errorf("ExitCode = %d, Output = %q", res.ExitCode, res.Output)
+	}
+}
+
+func TestRunWithOptions_SignalExitCode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("sinais Unix")
+	}
+	// Como nos shells, morte por sinal vira 128+sinal (SIGKILL = 9, típico do OOM killer).
+	res := runner.RunWithOptions(context.Background(), []string{"sh", "-c", "kill -9 $$"}, runner.Options{})
+	if res.ExitCode != 137 {
+		t.Errorf("ExitCode = %d, want 137", res.ExitCode)
+	}
+}
+
+func TestRunWithOptions_Phases(t *testing.T) {
+	script := `echo "::bmt-phase name=configure::"; sleep 0.1; echo "::bmt-phase name=compile::"; sleep 0.2`
+	res := runner.RunWithOptions(context.Background(), []string{"sh", "-c", script}, runner.Options{Phases: true})
+	if len(res.Phases) != 2 || res.Phases[0].Name != "configure" || res.Phases[1].Name != "compile" {
+		t.Fatalf("Phases = %+v", res.Phases)
+	}
+	if res.Phases[1].DurationSec < 0.15 || res.Phases[0].DurationSec+res.Phases[1].DurationSec > res.DurationSec+0.05 {
+		t.Errorf("durações inconsistentes: %+v (total %v)", res.Phases, res.DurationSec)
+	}
+	if res.Output != nil {
+		t.Errorf("Output = %q, want nil sem OutputTail", res.Output)
+	}
+}
+
+func TestRunWithOptions_PhaseFD(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("ExtraFiles não é suportado no Windows")
+	}
+	script := `echo configure >&$BMT_PHASE_FD; echo name=compile >&$BMT_PHASE_FD; echo end >&$BMT_PHASE_FD`
+	res := runner.RunWithOptions(context.Background(), []string{"sh", "-c", script}, runner.Options{PhaseFD: true})
+	if res.ExitCode != 0 {
+		t.Fatalf("ExitCode = %d", res.ExitCode)
+	}
+	if len(res.Phases) != 2 || res.Phases[0].Name != "configure" || res.Phases[1].Name != "compile" {
+		t.Errorf("Phases = %+v", res.Phases)
+	}
+}
+
+func TestRunWithOptions_Env(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("usa sh")
+	}
+	t.Setenv("BMT_TEST_HERDADA", "sim")
+	opts := runner.Options{OutputTail: 1024, Env: []string{"BMT_PARENT_RUN_ID=01PAI"}}
+	res := runner.RunWithOptions(context.Background(), []string{"sh", "-c", `echo "$BMT_PARENT_RUN_ID $BMT_TEST_HERDADA"`}, opts)
+	if string(res.Output) != "01PAI sim\n" {
+		t.Errorf("Output = %q, want a variável acrescentada e as herdadas", res.Output)
+	}
+}
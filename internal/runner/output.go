@@ -0,0 +1,141 @@
+package runner
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// DefaultOutputTail é o tamanho padrão, em bytes, do final da saída guardado em memória.
+const DefaultOutputTail = 64 * 1024
+
+// outputGrace limita quanto tempo esperamos pela saída depois que o comando
+// termina, caso algum descendente continue vivo segurando o terminal ou o pipe.
+const outputGrace = time.Second
+
+// tailBuffer guarda os últimos bytes escritos num buffer circular de tamanho fixo.
+// Pode receber escritas concorrentes de stdout e stderr.
+type tailBuffer struct {
+	mu    sync.Mutex
+	buf   []byte
+	pos   int
+	total int64
+}
+
+func newTailBuffer(size int) *tailBuffer {
+	return &tailBuffer{buf: make([]byte, size)}
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	n := len(p)
+	t.total += int64(n)
+	if len(t.buf) == 0 {
+		return n, nil
+	}
+	if len(p) > len(t.buf) {
+		p = p[len(p)-len(t.buf):]
+	}
+	c := copy(t.buf[t.pos:], p)
+	copy(t.buf, p[c:])
+	t.pos = (t.pos + len(p)) % len(t.buf)
+	return n, nil
+}
+
+// Tail retorna uma cópia do conteúdo guardado, em ordem. Se parte da saída foi
+// descartada, a primeira linha (provavelmente cortada) é removida e truncated é true.
+func (t *tailBuffer) Tail() (out []byte, truncated bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.total <= int64(len(t.buf)) {
+		return bytes.Clone(t.buf[:t.total]), false
+	}
+	out = make([]byte, 0, len(t.buf))
+	out = append(out, t.buf[t.pos:]...)
+	out = append(out, t.buf[:t.pos]...)
+	if i := bytes.IndexByte(out, '\n'); i >= 0 && i < len(out)-1 {
+		out = out[i+1:]
+	}
+	return out, true
+}
+
+// outputCapture conecta stdout e stderr do comando ao terminal e a writers
+// adicionais (o final da saída, os marcadores de fase). Cada stream do bmt que
+// for um terminal vira um pseudo-terminal próprio, para manter cores e o
+// comportamento interativo sem misturar stderr no stdout (e quebrar 2>); os
+// demais usam pipes.
+type outputCapture struct {
+	newSink func() io.Writer
+	ptys    []*ptyStream
+}
+
+// ptyStream copia a saída de um pseudo-terminal para o terminal do bmt.
+type ptyStream struct {
+	term      *os.File  // Terminal do bmt de onde vem o tamanho da janela
+	dst       io.Writer // Terminal do bmt, possivelmente via linha de status
+	master    *os.File
+	slave     *os.File
+	done      chan struct{}
+	stopWinch func()
+}
+
+// captureOutput configura cmd. newSink é chamada uma vez por stream, para que
+// writers com estado (ex.: divisão em linhas) não misturem stdout e stderr.
+// Com status, a saída passa pela linha de status antes de chegar ao terminal.
+func captureOutput(cmd *exec.Cmd, newSink func() io.Writer, status *StatusLine) *outputCapture {
+	return captureStreams(cmd, os.Stdout, os.Stderr, newSink, status)
+}
+
+func captureStreams(cmd *exec.Cmd, stdout, stderr *os.File, newSink func() io.Writer, status *StatusLine) *outputCapture {
+	c := &outputCapture{newSink: newSink}
+	cmd.Stdout = c.connect(cmd, stdout, status)
+	cmd.Stderr = c.connect(cmd, stderr, status)
+	return c
+}
+
+// connect retorna o destino do comando para um stream do bmt: o escravo de um
+// pseudo-terminal se term for um terminal, ou um pipe para term e o sink.
+func (c *outputCapture) connect(cmd *exec.Cmd, term *os.File, status *StatusLine) io.Writer {
+	dst := io.Writer(term)
+	if status != nil {
+		dst = status.guard(term)
+	}
+	if isTerminal(term) {
+		if master, slave, err := openPTY(); err == nil {
+			copyWinsize(term, master)
+			c.ptys = append(c.ptys, &ptyStream{term: term, dst: dst, master: master, slave: slave})
+			return slave
+		}
+	}
+	cmd.WaitDelay = outputGrace
+	return io.MultiWriter(dst, c.newSink())
+}
+
+// started deve ser chamado logo após cmd.Start, com ou sem erro.
+func (c *outputCapture) started() {
+	for _, p := range c.ptys {
+		p.slave.Close() // só o comando deve manter o escravo aberto
+		p.stopWinch = forwardWinsize(p.term, p.master)
+		p.done = make(chan struct{})
+		go func(p *ptyStream, sink io.Writer) {
+			defer close(p.done)
+			// Termina com EIO quando todos os processos fecham o escravo.
+			io.Copy(io.MultiWriter(p.dst, sink), p.master)
+		}(p, c.newSink())
+	}
+}
+
+// finish espera a cópia da saída terminar, no máximo por outputGrace.
+func (c *outputCapture) finish() {
+	deadline := time.Now().Add(outputGrace)
+	for _, p := range c.ptys {
+		p.stopWinch()
+		p.master.SetReadDeadline(deadline)
+		<-p.done
+		p.master.Close()
+	}
+}
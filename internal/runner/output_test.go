@@ -0,0 +1,113 @@
+package runner
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTailBuffer(t *testing.T) {
+	tb := newTailBuffer(16)
+	tb.Write([]byte("linha 1\n"))
+	if got, truncated := tb.Tail(); string(got) != "linha 1\n" || truncated {
+		t.Errorf("Tail() = %q, %v", got, truncated)
+	}
+
+	tb.Write([]byte("linha 2\nlinha 3\n"))
+	// Restam os 16 últimos bytes: "linha 2\nlinha 3\n"; nada foi cortado no meio da linha.
+	got, truncated := tb.Tail()
+	if string(got) != "linha 3\n" || !truncated {
+		t.Errorf("Tail() após estouro = %q, %v; want linha parcial descartada", got, truncated)
+	}
+
+	tb.Write([]byte(strings.Repeat("x", 40) + "\nfim"))
+	if got, _ := tb.Tail(); string(got) != "fim" {
+		t.Errorf("Tail() com escrita maior que o buffer = %q, want %q", got, "fim")
+	}
+}
+
+func TestTailBuffer_NoNewline(t *testing.T) {
+	tb := newTailBuffer(4)
+	tb.Write([]byte("abcdefgh"))
+	if got, truncated := tb.Tail(); string(got) != "efgh" || !truncated {
+		t.Errorf("Tail() = %q, %v; want \"efgh\" quando não há quebra de linha", got, truncated)
+	}
+}
+
+func TestOpenPTY(t *testing.T) {
+	master, slave, err := openPTY()
+	if err != nil {
+		t.Skipf("pseudo-terminal indisponível: %v", err)
+	}
+	defer master.Close()
+
+	if !isTerminal(slave) {
+		t.Error("isTerminal(slave) = false")
+	}
+	if _, err := slave.Write([]byte("oi\n")); err != nil {
+		t.Fatal(err)
+	}
+	slave.Close()
+
+	master.SetReadDeadline(time.Now().Add(2 * time.Second))
+	buf := make([]byte, 64)
+	n, err := master.Read(buf)
+	if err != nil {
+		t.Fatalf("Read(master) erro: %v", err)
+	}
+	// O terminal converte \n em \r\n (ONLCR).
+	if got := string(buf[:n]); got != "oi\r\n" {
+		t.Errorf("Read(master) = %q, want %q", got, "oi\r\n")
+	}
+
+	f, err := os.CreateTemp(t.TempDir(), "arquivo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if isTerminal(f) {
+		t.Error("isTerminal(arquivo comum) = true")
+	}
+}
+
+func TestCaptureStreams_StderrSeparado(t *testing.T) {
+	master, term, err := openPTY()
+	if err != nil {
+		t.Skipf("pseudo-terminal indisponível: %v", err)
+	}
+	defer master.Close()
+	defer term.Close()
+	errFile, err := os.CreateTemp(t.TempDir(), "stderr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer errFile.Close()
+
+	sink := newTailBuffer(1024)
+	cmd := exec.Command("sh", "-c", `[ -t 1 ] && echo "out tty"; [ -t 2 ] || echo "err pipe" >&2`)
+	c := captureStreams(cmd, term, errFile, func() io.Writer { return sink }, nil)
+	err = cmd.Start()
+	c.started()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd.Wait()
+	c.finish()
+
+	master.SetReadDeadline(time.Now().Add(2 * time.Second))
+	buf := make([]byte, 256)
+	n, _ := master.Read(buf)
+	// Os dois pseudo-terminais convertem \n em \r\n.
+	if got := string(buf[:n]); got != "out tty\r\r\n" {
+		t.Errorf("terminal = %q, want só o stdout via pseudo-terminal", got)
+	}
+	if got, _ := os.ReadFile(errFile.Name()); string(got) != "err pipe\n" {
+		t.Errorf("stderr = %q, want só o stderr via pipe", got)
+	}
+	if got, _ := sink.Tail(); !strings.Contains(string(got), "out tty") || !strings.Contains(string(got), "err pipe") {
+		t.Errorf("sink = %q, want os dois streams", got)
+	}
+}
@@ -0,0 +1,175 @@
+package runner
+
+import (
+	"bufio"
+	"bytes"
+	"dev-metrics/internal/metrics"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EnvPhaseFD é a variável que informa ao comando medido o descritor onde ele
+// pode escrever marcadores de fase (ex.: echo compile >&$BMT_PHASE_FD).
+const EnvPhaseFD = "BMT_PHASE_FD"
+
+// phaseMarker casa "::bmt-phase name=<fase>::" e "::bmt-phase end::".
+var phaseMarker = regexp.MustCompile(`::bmt-phase (?:name=([^:]+)|(end))::`)
+
+// parsePhaseMarker procura um marcador de fase numa linha da saída. Um nome
+// vazio com ok=true encerra a fase atual.
+func parsePhaseMarker(line string) (name string, ok bool) {
+	m := phaseMarker.FindStringSubmatch(line)
+	if m == nil {
+		return "", false
+	}
+	return strings.TrimSpace(m[1]), true
+}
+
+// parsePhaseFDLine interpreta uma linha escrita em BMT_PHASE_FD: o marcador
+// completo, "name=<fase>", "end" ou apenas o nome da fase.
+func parsePhaseFDLine(line string) (name string, ok bool) {
+	if name, ok := parsePhaseMarker(line); ok {
+		return name, true
+	}
+	line = strings.TrimSpace(line)
+	switch {
+	case line == "":
+		return "", false
+	case line == "end":
+		return "", true
+	}
+	return strings.TrimSpace(strings.TrimPrefix(line, "name=")), true
+}
+
+// phaseTracker monta a lista de fases a partir dos marcadores. Cada marcador
+// encerra a fase anterior; a última fase termina junto com o comando.
+type phaseTracker struct {
+	mu     sync.Mutex
+	begin  time.Time
+	phases []metrics.Phase
+	open   bool
+
+	r, w *os.File // pipe de BMT_PHASE_FD
+	done chan struct{}
+}
+
+func newPhaseTracker(begin time.Time) *phaseTracker {
+	return &phaseTracker{begin: begin}
+}
+
+// markAt encerra a fase aberta em at (segundos desde o início) e, se name não
+// for vazio, inicia uma nova.
+func (t *phaseTracker) markAt(name string, at float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.open {
+		last := &t.phases[len(t.phases)-1]
+		last.DurationSec = max(0, at-last.StartSec)
+		t.open = false
+	}
+	if name != "" {
+		t.phases = append(t.phases, metrics.Phase{Name: name, StartSec: at})
+		t.open = true
+	}
+}
+
+func (t *phaseTracker) mark(name string) {
+	t.markAt(name, time.Since(t.begin).Seconds())
+}
+
+// outputLine recebe cada linha da saída do comando.
+func (t *phaseTracker) outputLine(line string) {
+	if name, ok := parsePhaseMarker(line); ok {
+		t.mark(name)
+	}
+}
+
+// attachFD passa ao comando a ponta de escrita de um pipe e anuncia o
+// descritor em BMT_PHASE_FD. Não suportado no Windows.
+func (t *phaseTracker) attachFD(cmd *exec.Cmd) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	r, w, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	t.r, t.w = r, w
+	fd := 3 + len(cmd.ExtraFiles)
+	cmd.ExtraFiles = append(cmd.ExtraFiles, w)
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%d", EnvPhaseFD, fd))
+	return nil
+}
+
+// started deve ser chamado logo após cmd.Start, com ou sem erro.
+func (t *phaseTracker) started() {
+	if t.r == nil {
+		return
+	}
+	t.w.Close() // só o comando deve manter a ponta de escrita aberta
+	t.done = make(chan struct{})
+	go func() {
+		defer close(t.done)
+		scanner := bufio.NewScanner(t.r)
+		for scanner.Scan() {
+			if name, ok := parsePhaseFDLine(scanner.Text()); ok {
+				t.mark(name)
+			}
+		}
+	}()
+}
+
+// finish encerra a leitura do descritor (no máximo outputGrace após o fim do
+// comando) e fecha a última fase em total segundos.
+func (t *phaseTracker) finish(total float64) []metrics.Phase {
+	if t.r != nil {
+		t.r.SetReadDeadline(time.Now().Add(outputGrace))
+		<-t.done
+		t.r.Close()
+	}
+	t.markAt("", total)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.phases
+}
+
+// lineWriter chama fn para cada linha completa escrita. Não é seguro para
+// escritas concorrentes; use um por stream.
+type lineWriter struct {
+	buf []byte
+	fn  func(string)
+}
+
+// maxMarkerLine limita o buffer de uma linha sem quebra (saída binária, barras de progresso).
+const maxMarkerLine = 64 * 1024
+
+func newLineWriter(fn func(string)) *lineWriter {
+	return &lineWriter{fn: fn}
+}
+
+func (l *lineWriter) Write(p []byte) (int, error) {
+	l.buf = append(l.buf, p...)
+	rest := l.buf
+	for {
+		i := bytes.IndexByte(rest, '\n')
+		if i < 0 {
+			break
+		}
+		l.fn(string(bytes.TrimRight(rest[:i], "\r")))
+		rest = rest[i+1:]
+	}
+	if len(rest) > maxMarkerLine {
+		rest = nil
+	}
+	l.buf = append(l.buf[:0], rest...)
+	return len(p), nil
+}
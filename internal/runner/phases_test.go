@@ -0,0 +1,82 @@
+package runner
+
+import (
+	"dev-metrics/internal/metrics"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParsePhaseMarker(t *testing.T) {
+	tests := []struct {
+		line   string
+		want   string
+		wantOK bool
+	}{
+		{"::bmt-phase name=compile::", "compile", true},
+		{"\x1b[1m[ci] ::bmt-phase name=unit tests::\x1b[0m", "unit tests", true},
+		{"::bmt-phase end::", "", true},
+		{"bmt-phase name=compile", "", false},
+		{"compilando...", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := parsePhaseMarker(tt.line)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("parsePhaseMarker(%q) = %q, %v; want %q, %v", tt.line, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestParsePhaseFDLine(t *testing.T) {
+	tests := []struct {
+		line   string
+		want   string
+		wantOK bool
+	}{
+		{"compile", "compile", true},
+		{"name=package\n", "package", true},
+		{"::bmt-phase name=test::", "test", true},
+		{"end", "", true},
+		{"   ", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := parsePhaseFDLine(tt.line)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("parsePhaseFDLine(%q) = %q, %v; want %q, %v", tt.line, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestPhaseTracker(t *testing.T) {
+	tr := newPhaseTracker(time.Now())
+	tr.markAt("configure", 0)
+	tr.markAt("compile", 2)
+	tr.markAt("", 10) // end: intervalo sem fase até o próximo marcador
+	tr.markAt("test", 12)
+
+	got := tr.finish(15)
+	want := []metrics.Phase{
+		{Name: "configure", StartSec: 0, DurationSec: 2},
+		{Name: "compile", StartSec: 2, DurationSec: 8},
+		{Name: "test", StartSec: 12, DurationSec: 3},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("phases = %+v\nwant %+v", got, want)
+	}
+}
+
+func TestLineWriter(t *testing.T) {
+	var lines []string
+	lw := newLineWriter(func(l string) { lines = append(lines, l) })
+	lw.Write([]byte("um\r\ndo"))
+	lw.Write([]byte("is\n"))
+	lw.Write([]byte("tres\nincompleta"))
+
+	want := []string{"um", "dois", "tres"}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("linhas = %q, want %q", lines, want)
+	}
+	if string(lw.buf) != "incompleta" {
+		t.Errorf("buffer = %q, want %q", lw.buf, "incompleta")
+	}
+}
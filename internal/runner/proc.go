@@ -0,0 +1,179 @@
+package runner
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// ProcRoot é a raiz do procfs. Variável para permitir testes com fixtures.
+var ProcRoot = "/proc"
+
+// clockTicks é o valor de USER_HZ usado pelo kernel nos contadores de /proc/<pid>/stat.
+// É fixo em 100 em todas as arquiteturas suportadas pelo Linux.
+const clockTicks = 100
+
+// procStat contém os campos de /proc/<pid>/stat usados pelo bmt.
+type procStat struct {
+	pid        int
+	comm       string
+	ppid       int
+	cpuTicks   uint64 // utime + stime
+	childTicks uint64 // cutime + cstime: filhos já terminados e aguardados
+	startTime  uint64 // em ticks desde o boot; junto com pid identifica o processo
+	rssPages   uint64
+	blkioTicks uint64 // delayacct_blkio_ticks: espera por I/O de bloco (requer delayacct no kernel)
+}
+
+// procIO contém os contadores de /proc/<pid>/io.
+type procIO struct {
+	rchar      uint64 // bytes passados a read() e afins, incluindo cache e sistemas de arquivos de rede
+	wchar      uint64
+	syscr      uint64
+	syscw      uint64
+	readBytes  uint64 // bytes efetivamente lidos do armazenamento
+	writeBytes uint64
+}
+
+// procKey identifica um processo mesmo que o pid seja reutilizado.
+type procKey struct {
+	pid       int
+	startTime uint64
+}
+
+func (s procStat) key() procKey { return procKey{pid: s.pid, startTime: s.startTime} }
+
+// parseProcStat interpreta o conteúdo de /proc/<pid>/stat.
+func parseProcStat(data []byte) (procStat, error) {
+	var st procStat
+	open := bytes.IndexByte(data, '(')
+	close := bytes.LastIndexByte(data, ')')
+	if open < 0 || close < open {
+		return st, errors.New("formato de stat inválido")
+	}
+	pid, err := strconv.Atoi(string(bytes.TrimSpace(data[:open])))
+	if err != nil {
+		return st, err
+	}
+	st.pid = pid
+	st.comm = string(data[open+1 : close])
+
+	// Campos a partir do 3 (state); o campo N fica em rest[N-3].
+	rest := bytes.Fields(data[close+1:])
+	if len(rest) < 22 {
+		return st, errors.New("stat com campos insuficientes")
+	}
+	field := func(n int) uint64 {
+		v, _ := strconv.ParseUint(string(rest[n-3]), 10, 64)
+		return v
+	}
+	ppid, _ := strconv.Atoi(string(rest[1]))
+	st.ppid = ppid
+	st.cpuTicks = field(14) + field(15)
+	st.childTicks = field(16) + field(17)
+	st.startTime = field(22)
+	st.rssPages = field(24)
+	if len(rest) >= 40 { // kernels anteriores ao 2.6.18 não têm o campo 42
+		st.blkioTicks = field(42)
+	}
+	return st, nil
+}
+
+// parseProcIO interpreta o conteúdo de /proc/<pid>/io ("chave: valor" por linha).
+func parseProcIO(data []byte) (procIO, error) {
+	var io procIO
+	found := false
+	for _, line := range bytes.Split(data, []byte{'\n'}) {
+		key, value, ok := bytes.Cut(line, []byte{':'})
+		if !ok {
+			continue
+		}
+		v, err := strconv.ParseUint(string(bytes.TrimSpace(value)), 10, 64)
+		if err != nil {
+			return io, fmt.Errorf("io: valor inválido para %s: %w", key, err)
+		}
+		found = true
+		switch string(key) {
+		case "rchar":
+			io.rchar = v
+		case "wchar":
+			io.wchar = v
+		case "syscr":
+			io.syscr = v
+		case "syscw":
+			io.syscw = v
+		case "read_bytes":
+			io.readBytes = v
+		case "write_bytes":
+			io.writeBytes = v
+		}
+	}
+	if !found {
+		return io, errors.New("formato de io inválido")
+	}
+	return io, nil
+}
+
+// readProcIO lê /proc/<pid>/io. Falha se o processo terminou ou se o arquivo não
+// é legível (ele exige as mesmas permissões de ptrace).
+func readProcIO(root string, pid int) (procIO, bool) {
+	data, err := os.ReadFile(filepath.Join(root, strconv.Itoa(pid), "io"))
+	if err != nil {
+		return procIO{}, false
+	}
+	io, err := parseProcIO(data)
+	return io, err == nil
+}
+
+// readProcTable lê o stat de todos os processos visíveis em root.
+// Processos que terminam durante a leitura são ignorados.
+func readProcTable(root string) (map[int]procStat, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+	table := make(map[int]procStat, len(entries))
+	for _, e := range entries {
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(root, e.Name(), "stat"))
+		if err != nil {
+			continue
+		}
+		st, err := parseProcStat(data)
+		if err != nil {
+			continue
+		}
+		table[pid] = st
+	}
+	return table, nil
+}
+
+// descendants retorna root e todos os seus descendentes presentes na tabela.
+func descendants(table map[int]procStat, root int) []procStat {
+	children := make(map[int][]int)
+	for pid, st := range table {
+		children[st.ppid] = append(children[st.ppid], pid)
+	}
+	var out []procStat
+	queue := []int{root}
+	seen := map[int]bool{}
+	for len(queue) > 0 {
+		pid := queue[0]
+		queue = queue[1:]
+		if seen[pid] {
+			continue
+		}
+		seen[pid] = true
+		if st, ok := table[pid]; ok {
+			out = append(out, st)
+		}
+		queue = append(queue, children[pid]...)
+	}
+	return out
+}
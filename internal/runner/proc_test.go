@@ -0,0 +1,234 @@
+package runner
+
+import (
+	"dev-metrics/internal/metrics"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestParseProcStat(t *testing.T) {
+	st, err := parseProcStat([]byte("102 (my (weird) tool) R 101 100 1 0 -1 4194560 0 0 0 0 7 3 4 1 20 0 1 0 520 1000 50 0 0"))
+	if err != nil {
+		t.Fatalf("parseProcStat() erro: %v", err)
+	}
+	if st.pid != 102 || st.comm != "my (weird) tool" || st.ppid != 101 {
+		t.Errorf("pid/comm/ppid = %d/%q/%d", st.pid, st.comm, st.ppid)
+	}
+	if st.cpuTicks != 10 || st.childTicks != 5 || st.startTime != 520 || st.rssPages != 50 {
+		t.Errorf("cpuTicks/childTicks/startTime/rssPages = %d/%d/%d/%d", st.cpuTicks, st.childTicks, st.startTime, st.rssPages)
+	}
+
+	if _, err := parseProcStat([]byte("garbage")); err == nil {
+		t.Error("esperava erro para stat inválido")
+	}
+}
+
+func TestReadProcTableAndDescendants(t *testing.T) {
+	table, err := readProcTable("testdata/proc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table) != 5 {
+		t.Fatalf("len(table) = %d, want 5 (entradas não numéricas ignoradas)", len(table))
+	}
+
+	var pids []int
+	for _, st := range descendants(table, 100) {
+		pids = append(pids, st.pid)
+	}
+	sort.Ints(pids)
+	if len(pids) != 3 || pids[0] != 100 || pids[1] != 101 || pids[2] != 102 {
+		t.Errorf("descendants(100) = %v, want [100 101 102]", pids)
+	}
+}
+
+func TestSampler_ObserveAndSummary(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := &sampler{pid: 10, interval: time.Second, cpus: 4, pageSize: 4096, start: start, last: start, prev: map[procKey]uint64{}}
+
+	// 1s: dois processos usando 100 ticks cada (2 núcleos).
+	s.observe(map[int]procStat{
+		10: {pid: 10, ppid: 1, cpuTicks: 100, startTime: 1, rssPages: 10},
+		11: {pid: 11, ppid: 10, cpuTicks: 100, startTime: 2, rssPages: 10},
+		99: {pid: 99, ppid: 1, cpuTicks: 5000, startTime: 3, rssPages: 1000},
+	}, start.Add(time.Second))
+	// 2s: só o filho mais 100 ticks (1 núcleo); o pid 11 foi reutilizado por outro processo.
+	s.observe(map[int]procStat{
+		10: {pid: 10, ppid: 1, cpuTicks: 100, startTime: 1, rssPages: 10},
+		11: {pid: 11, ppid: 10, cpuTicks: 0, startTime: 7, rssPages: 5},
+		12: {pid: 12, ppid: 11, cpuTicks: 100, startTime: 8, rssPages: 5},
+	}, start.Add(2*time.Second))
+
+	if len(s.samples) != 2 {
+		t.Fatalf("len(samples) = %d, want 2", len(s.samples))
+	}
+	if s.samples[0].CPUs != 2 || s.samples[0].Procs != 2 || s.samples[0].RSSBytes != 20*4096 {
+		t.Errorf("samples[0] = %+v", s.samples[0])
+	}
+	if s.samples[1].CPUs != 1 || s.samples[1].Procs != 3 {
+		t.Errorf("samples[1] = %+v", s.samples[1])
+	}
+
+	sum := s.summary()
+	if sum.AvgParallelism != 1.5 || sum.PeakParallelism != 2 || sum.PeakProcs != 3 {
+		t.Errorf("summary = %+v", sum)
+	}
+	if sum.IdleFraction != 1-1.5/4 {
+		t.Errorf("IdleFraction = %v, want %v", sum.IdleFraction, 1-1.5/4)
+	}
+	if sum.PeakRSSBytes != 20*4096 {
+		t.Errorf("PeakRSSBytes = %d", sum.PeakRSSBytes)
+	}
+}
+
+func TestSampler_EmptySummary(t *testing.T) {
+	s := &sampler{}
+	if s.summary() != nil {
+		t.Error("summary() sem amostras deveria ser nil")
+	}
+}
+
+func TestSampler_Tools(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := &sampler{pid: 10, interval: time.Second, start: start, last: start, prev: map[procKey]uint64{}}
+
+	// startTime em ticks: o raiz começou em 1000, o primeiro cc1plus 1s depois.
+	s.observe(map[int]procStat{
+		10: {pid: 10, ppid: 1, comm: "make", cpuTicks: 5, startTime: 1000},
+		11: {pid: 11, ppid: 10, comm: "cc1plus", cpuTicks: 150, startTime: 1100},
+	}, start.Add(2*time.Second))
+	s.observe(map[int]procStat{
+		10: {pid: 10, ppid: 1, comm: "make", cpuTicks: 10, startTime: 1000},
+		12: {pid: 12, ppid: 10, comm: "cc1plus", cpuTicks: 50, startTime: 1250},
+		13: {pid: 13, ppid: 10, comm: "ld", cpuTicks: 20, startTime: 1300},
+	}, start.Add(4*time.Second))
+
+	got := s.tools(0, 0)
+	want := []metrics.ToolUsage{
+		{Name: "cc1plus", CPUSec: 2, WallSec: 1 + 1.5, Procs: 2},
+		{Name: "ld", CPUSec: 0.2, WallSec: 1, Procs: 1},
+		{Name: "make", CPUSec: 0.1, WallSec: 4, Procs: 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("tools() = %+v\nwant %+v", got, want)
+	}
+
+	if top := s.tools(1, 0); len(top) != 1 || top[0].Name != "cc1plus" {
+		t.Errorf("tools(1) = %+v", top)
+	}
+}
+
+func TestSampler_ReapedChildren(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := &sampler{pid: 10, interval: time.Second, start: start, last: start, prev: map[procKey]uint64{}}
+
+	s.observe(map[int]procStat{
+		10: {pid: 10, ppid: 1, comm: "make", cpuTicks: 5, startTime: 1000},
+		11: {pid: 11, ppid: 10, comm: "cc1plus", cpuTicks: 50, startTime: 1000},
+	}, start.Add(time.Second))
+	// O cc1plus terminou com 80 ticks: 30 depois da última amostra.
+	s.observe(map[int]procStat{
+		10: {pid: 10, ppid: 1, comm: "make", cpuTicks: 10, childTicks: 80, startTime: 1000},
+		12: {pid: 12, ppid: 10, comm: "ld", cpuTicks: 20, startTime: 1150},
+	}, start.Add(2*time.Second))
+	// O ld terminou com 30 ticks.
+	s.observe(map[int]procStat{
+		10: {pid: 10, ppid: 1, comm: "make", cpuTicks: 10, childTicks: 110, startTime: 1000},
+	}, start.Add(3*time.Second))
+
+	if len(s.samples) != 3 || s.samples[1].CPUs != 0.55 || s.samples[2].CPUs != 0.1 {
+		t.Errorf("samples = %+v, want CPUs 0.55 e 0.1 com a CPU dos filhos aguardados", s.samples)
+	}
+
+	got := s.tools(0, 1.5)
+	names := make(map[string]float64)
+	for _, u := range got {
+		names[u.Name] = u.CPUSec
+	}
+	if names["cc1plus"] != 0.8 || names["ld"] != 0.3 || names["make"] != 0.1 {
+		t.Errorf("tools() = %+v, want a última fatia atribuída a cada filho", got)
+	}
+	if rest := names[unattributedTool]; rest < 0.299 || rest > 0.301 {
+		t.Errorf("%s = %v, want 0.3", unattributedTool, rest)
+	}
+	if got[0].Name != "cc1plus" {
+		t.Errorf("tools()[0] = %q, want cc1plus", got[0].Name)
+	}
+}
+
+func TestParseProcStat_BlkioTicks(t *testing.T) {
+	// Linha completa de um kernel atual: o campo 42 é delayacct_blkio_ticks.
+	line := "300 (ld) D 1 300 1 0 -1 4194304 90 0 0 0 12 3 0 0 20 0 1 0 900 1000 40 18446744073709551615 1 1 0 0 0 0 0 0 0 0 0 0 17 2 0 0 250 0 0 0 0 0 0 0 0 0 0"
+	st, err := parseProcStat([]byte(line))
+	if err != nil {
+		t.Fatalf("parseProcStat() erro: %v", err)
+	}
+	if st.blkioTicks != 250 {
+		t.Errorf("blkioTicks = %d, want 250", st.blkioTicks)
+	}
+}
+
+func TestReadProcIO(t *testing.T) {
+	got, ok := readProcIO("testdata/proc", 101)
+	if !ok {
+		t.Fatal("readProcIO(101) falhou")
+	}
+	want := procIO{rchar: 4096000, wchar: 1024, syscr: 120, syscw: 4, readBytes: 8192}
+	if got != want {
+		t.Errorf("readProcIO(101) = %+v, want %+v", got, want)
+	}
+	if _, ok := readProcIO("testdata/proc", 100); ok {
+		t.Error("readProcIO(100) sem arquivo io deveria falhar")
+	}
+	if _, err := parseProcIO([]byte("rchar: muitos\n")); err == nil {
+		t.Error("esperava erro para valor inválido")
+	}
+}
+
+func TestSampler_IOStats(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	reads := map[int]procIO{
+		10: {rchar: 100, wchar: 10, syscr: 2, syscw: 1, readBytes: 4096},
+		11: {rchar: 1000, syscr: 10, writeBytes: 8192},
+	}
+	s := &sampler{pid: 10, start: start, last: start, prev: map[procKey]uint64{}, readIO: func(pid int) (procIO, bool) {
+		io, ok := reads[pid]
+		return io, ok
+	}}
+
+	if s.ioStats() != nil {
+		t.Error("ioStats() sem amostras deveria ser nil")
+	}
+	s.observe(map[int]procStat{
+		10: {pid: 10, ppid: 1, startTime: 1, blkioTicks: 50},
+		11: {pid: 11, ppid: 10, startTime: 2, blkioTicks: 25},
+	}, start.Add(time.Second))
+	// O pid 11 terminou; a última leitura dele continua contando.
+	reads[10] = procIO{rchar: 300, wchar: 10, syscr: 5, syscw: 1, readBytes: 4096}
+	delete(reads, 11)
+	s.observe(map[int]procStat{
+		10: {pid: 10, ppid: 1, startTime: 1, blkioTicks: 50},
+	}, start.Add(2*time.Second))
+
+	got := *s.ioStats()
+	want := metrics.IOStats{
+		ReadBytes: 4096, WriteBytes: 8192, ReadChars: 1300, WriteChars: 10,
+		ReadSyscalls: 15, WriteSyscalls: 1, BlkioWaitSec: 0.75,
+	}
+	if got != want {
+		t.Errorf("ioStats() = %+v, want %+v", got, want)
+	}
+}
+
+func TestMergeIO(t *testing.T) {
+	final := &metrics.IOStats{ReadBytes: 100, WriteBytes: 5}
+	if got := mergeIO(nil, final); got != final {
+		t.Errorf("mergeIO(nil, final) = %+v", got)
+	}
+	got := mergeIO(&metrics.IOStats{ReadBytes: 50, WriteBytes: 10, ReadChars: 7}, final)
+	if *got != (metrics.IOStats{ReadBytes: 100, WriteBytes: 10, ReadChars: 7}) {
+		t.Errorf("mergeIO() = %+v", got)
+	}
+}
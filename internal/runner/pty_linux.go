@@ -0,0 +1,91 @@
+//go:build linux
+
+package runner
+
+import (
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"unsafe"
+)
+
+// openPTY abre um par mestre/escravo de pseudo-terminal via /dev/ptmx.
+func openPTY() (master, slave *os.File, err error) {
+	master, err = os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	var unlock int32
+	if err := ioctl(master, syscall.TIOCSPTLCK, unsafe.Pointer(&unlock)); err != nil {
+		master.Close()
+		return nil, nil, err
+	}
+	var n uint32
+	if err := ioctl(master, syscall.TIOCGPTN, unsafe.Pointer(&n)); err != nil {
+		master.Close()
+		return nil, nil, err
+	}
+	slave, err = os.OpenFile("/dev/pts/"+strconv.Itoa(int(n)), os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		master.Close()
+		return nil, nil, err
+	}
+	return master, slave, nil
+}
+
+// isTerminal indica se f é um terminal.
+func isTerminal(f *os.File) bool {
+	var t syscall.Termios
+	return ioctl(f, syscall.TCGETS, unsafe.Pointer(&t)) == nil
+}
+
+// copyWinsize aplica o tamanho da janela de from em to. Erros são ignorados:
+// sem o tamanho o comando apenas usa o padrão de 80 colunas.
+func copyWinsize(from, to *os.File) {
+	var ws [4]uint16 // struct winsize
+	if ioctl(from, syscall.TIOCGWINSZ, unsafe.Pointer(&ws)) == nil {
+		ioctl(to, syscall.TIOCSWINSZ, unsafe.Pointer(&ws))
+	}
+}
+
+// ioctl usa SyscallConn para não colocar o arquivo em modo bloqueante, o que
+// impediria interromper leituras com deadlines.
+func ioctl(f *os.File, req uintptr, arg unsafe.Pointer) error {
+	conn, err := f.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var errno syscall.Errno
+	err = conn.Control(func(fd uintptr) {
+		_, _, errno = syscall.Syscall(syscall.SYS_IOCTL, fd, req, uintptr(arg))
+	})
+	if err != nil {
+		return err
+	}
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// forwardWinsize replica em to as mudanças de tamanho da janela de from até stop ser chamada.
+func forwardWinsize(from, to *os.File) (stop func()) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGWINCH)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ch:
+				copyWinsize(from, to)
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(ch)
+		close(done)
+	}
+}
@@ -0,0 +1,23 @@
+//go:build !linux
+
+package runner
+
+import (
+	"errors"
+	"os"
+)
+
+// openPTY só é suportado no Linux; nas demais plataformas a saída é capturada por pipes.
+func openPTY() (master, slave *os.File, err error) {
+	return nil, nil, errors.New("pseudo-terminal não suportado nesta plataforma")
+}
+
+// isTerminal indica se f é um dispositivo de caracteres (aproximação de isatty).
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+func copyWinsize(from, to *os.File) {}
+
+func forwardWinsize(from, to *os.File) (stop func()) { return func() {} }
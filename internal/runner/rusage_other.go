@@ -0,0 +1,14 @@
+//go:build !unix
+
+package runner
+
+import "os"
+
+// blockIO não é suportado fora de sistemas Unix.
+func blockIO(ps *os.ProcessState) (read, write uint64, ok bool) {
+	return 0, 0, false
+}
+
+func signalExitCode(ps *os.ProcessState) (int, bool) {
+	return 0, false
+}
@@ -0,0 +1,29 @@
+//go:build unix
+
+package runner
+
+import (
+	"os"
+	"syscall"
+)
+
+// blockIO retorna os bytes lidos e escritos no armazenamento pelo processo e
+// pelos descendentes que ele aguardou, a partir do rusage de wait4.
+func blockIO(ps *os.ProcessState) (read, write uint64, ok bool) {
+	ru, ok := ps.SysUsage().(*syscall.Rusage)
+	if !ok || ru == nil {
+		return 0, 0, false
+	}
+	// ru_inblock/ru_oublock contam blocos de 512 bytes.
+	return uint64(ru.Inblock) * 512, uint64(ru.Oublock) * 512, true
+}
+
+// signalExitCode retorna 128+sinal se o processo foi terminado por um sinal,
+// como fazem os shells (ex.: 137 para SIGKILL, típico do OOM killer).
+func signalExitCode(ps *os.ProcessState) (int, bool) {
+	ws, ok := ps.Sys().(syscall.WaitStatus)
+	if !ok || !ws.Signaled() {
+		return 0, false
+	}
+	return 128 + int(ws.Signal()), true
+}
@@ -0,0 +1,291 @@
+package runner
+
+import (
+	"dev-metrics/internal/metrics"
+	"os"
+	"runtime"
+	"sort"
+	"time"
+)
+
+// sampler percorre periodicamente o procfs e registra o uso de CPU, o número
+// de processos vivos e o RSS da árvore de processos do comando medido.
+type sampler struct {
+	root     string
+	pid      int
+	interval time.Duration
+	cpus     int
+	pageSize uint64
+
+	start   time.Time
+	last    time.Time
+	prev    map[procKey]uint64 // ticks de CPU na amostra anterior
+	samples []metrics.ProcessSample
+
+	procs     map[procKey]*procTrack
+	rootStart uint64 // startTime (em ticks) do processo raiz, quando conhecido
+
+	readIO func(pid int) (procIO, bool) // nil desativa a coleta de I/O
+}
+
+// procTrack acompanha um processo da árvore ao longo das amostras.
+type procTrack struct {
+	comm        string
+	pid         int
+	ppid        int
+	startOffset float64 // segundos desde o início do comando
+	lastSeen    float64
+	cpuTicks    uint64
+	childTicks  uint64
+	lateTicks   uint64 // CPU depois da última amostra, descoberta quando o pai aguardou o processo
+	blkioTicks  uint64
+	io          procIO // última leitura de /proc/<pid>/io
+}
+
+func newSampler(pid int, interval time.Duration, start time.Time) *sampler {
+	return &sampler{
+		root:     ProcRoot,
+		pid:      pid,
+		interval: interval,
+		cpus:     runtime.NumCPU(),
+		pageSize: uint64(os.Getpagesize()),
+		start:    start,
+		last:     start,
+		prev:     make(map[procKey]uint64),
+		procs:    make(map[procKey]*procTrack),
+		readIO:   func(pid int) (procIO, bool) { return readProcIO(ProcRoot, pid) },
+	}
+}
+
+// samplingSupported indica se há um procfs legível para amostrar.
+func samplingSupported() bool {
+	_, err := os.Stat(ProcRoot + "/self/stat")
+	return err == nil
+}
+
+// run amostra até stop ser fechado.
+func (s *sampler) run(stop <-chan struct{}) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			table, err := readProcTable(s.root)
+			if err != nil {
+				return
+			}
+			s.observe(table, now)
+		}
+	}
+}
+
+// observe registra uma amostra a partir de uma leitura da tabela de processos.
+// A CPU do intervalo soma o crescimento dos ticks de cada processo vivo e a
+// CPU ainda não vista dos filhos que terminaram (ver reaped).
+func (s *sampler) observe(table map[int]procStat, now time.Time) {
+	tree := descendants(table, s.pid)
+	if len(tree) == 0 {
+		return
+	}
+
+	offset := now.Sub(s.start).Seconds()
+	if root, ok := table[s.pid]; ok {
+		s.rootStart = root.startTime
+	}
+
+	cur := make(map[procKey]uint64, len(tree))
+	var ticks, rss uint64
+	for _, st := range tree {
+		k := st.key()
+		cur[k] = st.cpuTicks
+		if st.cpuTicks > s.prev[k] {
+			ticks += st.cpuTicks - s.prev[k]
+		}
+		rss += st.rssPages * s.pageSize
+	}
+	ticks += s.reaped(tree, cur)
+	for _, st := range tree {
+		s.track(st.key(), st, offset)
+	}
+
+	elapsed := now.Sub(s.last).Seconds()
+	var used float64
+	if elapsed > 0 {
+		used = float64(ticks) / clockTicks / elapsed
+	}
+	s.samples = append(s.samples, metrics.ProcessSample{
+		OffsetSec: offset,
+		CPUs:      used,
+		Procs:     len(tree),
+		RSSBytes:  rss,
+	})
+	s.prev = cur
+	s.last = now
+}
+
+// reaped estima a CPU dos filhos que terminaram desde a amostra anterior e
+// ainda não foi contada: o crescimento de cutime+cstime de cada processo da
+// árvore, descontado o que já tinha sido visto dos filhos que sumiram. Com um
+// único filho sumido, o restante (sua última fatia) é atribuído a ele; o resto
+// (processos curtos demais para serem amostrados) entra só no total.
+func (s *sampler) reaped(tree []procStat, cur map[procKey]uint64) uint64 {
+	gone := make(map[int][]*procTrack) // Processos que sumiram, por ppid
+	for k := range s.prev {
+		if _, ok := cur[k]; ok {
+			continue
+		}
+		if tr := s.procs[k]; tr != nil {
+			gone[tr.ppid] = append(gone[tr.ppid], tr)
+		}
+	}
+	var total uint64
+	for _, st := range tree {
+		var before uint64
+		if tr := s.procs[st.key()]; tr != nil {
+			before = tr.childTicks
+		}
+		if st.childTicks <= before {
+			continue
+		}
+		grown := st.childTicks - before
+		children := gone[st.pid]
+		var seen uint64
+		for _, c := range children {
+			seen += seenTicks(c, gone, 0)
+		}
+		if grown <= seen {
+			continue
+		}
+		total += grown - seen
+		if len(children) == 1 {
+			children[0].lateTicks += grown - seen
+		}
+	}
+	return total
+}
+
+// seenTicks é a CPU já contada de um processo que sumiu e dos descendentes
+// que sumiram junto com ele, cujos ticks também chegam ao pai via cutime.
+func seenTicks(tr *procTrack, gone map[int][]*procTrack, depth int) uint64 {
+	n := tr.cpuTicks + tr.childTicks
+	if depth < 64 { // Proteção contra ciclos por reuso de pid
+		for _, c := range gone[tr.pid] {
+			n += seenTicks(c, gone, depth+1)
+		}
+	}
+	return n
+}
+
+// summary resume as amostras coletadas. Retorna nil se nenhuma amostra foi feita.
+func (s *sampler) summary() *metrics.SamplingSummary {
+	if len(s.samples) == 0 {
+		return nil
+	}
+	sum := &metrics.SamplingSummary{
+		IntervalSec: s.interval.Seconds(),
+		Samples:     len(s.samples),
+	}
+	var total float64
+	for _, smp := range s.samples {
+		total += smp.CPUs
+		sum.PeakParallelism = max(sum.PeakParallelism, smp.CPUs)
+		sum.PeakProcs = max(sum.PeakProcs, smp.Procs)
+		sum.PeakRSSBytes = max(sum.PeakRSSBytes, smp.RSSBytes)
+	}
+	sum.AvgParallelism = total / float64(len(s.samples))
+	if s.cpus > 0 {
+		sum.IdleFraction = min(1, max(0, 1-sum.AvgParallelism/float64(s.cpus)))
+	}
+	return sum
+}
+
+// track atualiza o acompanhamento de um processo. O início do processo é
+// estimado pelo startTime relativo ao do processo raiz.
+func (s *sampler) track(k procKey, st procStat, offset float64) {
+	if s.procs == nil {
+		s.procs = make(map[procKey]*procTrack)
+	}
+	tr, ok := s.procs[k]
+	if !ok {
+		tr = &procTrack{startOffset: offset}
+		if s.rootStart > 0 && st.startTime >= s.rootStart {
+			tr.startOffset = float64(st.startTime-s.rootStart) / clockTicks
+		}
+		s.procs[k] = tr
+	}
+	tr.comm = st.comm // exec() troca o nome do processo; vale o último visto
+	tr.pid, tr.ppid = st.pid, st.ppid
+	tr.lastSeen = offset
+	tr.cpuTicks = st.cpuTicks
+	tr.childTicks = st.childTicks
+	tr.blkioTicks = st.blkioTicks
+	if s.readIO != nil {
+		if io, ok := s.readIO(st.pid); ok {
+			tr.io = io
+		}
+	}
+}
+
+// ioStats soma a última leitura de I/O de cada processo visto. Os contadores
+// de um processo não incluem os filhos, então a soma não conta nada em dobro.
+// Retorna nil se nenhum processo foi amostrado.
+func (s *sampler) ioStats() *metrics.IOStats {
+	if len(s.procs) == 0 {
+		return nil
+	}
+	st := &metrics.IOStats{}
+	var blkio uint64
+	for _, tr := range s.procs {
+		st.ReadBytes += tr.io.readBytes
+		st.WriteBytes += tr.io.writeBytes
+		st.ReadChars += tr.io.rchar
+		st.WriteChars += tr.io.wchar
+		st.ReadSyscalls += tr.io.syscr
+		st.WriteSyscalls += tr.io.syscw
+		blkio += tr.blkioTicks
+	}
+	st.BlkioWaitSec = float64(blkio) / clockTicks
+	return st
+}
+
+// unattributedTool agrupa a CPU do comando que não pôde ser atribuída a um
+// executável, como a de processos que viveram menos que o intervalo de amostragem.
+const unattributedTool = "(unattributed)"
+
+// tools agrega CPU e tempo de vida por nome de executável e retorna os topN
+// maiores consumidores de CPU. A diferença entre cpuSec, a CPU total do
+// comando, e a soma atribuída vira a entrada unattributedTool.
+func (s *sampler) tools(topN int, cpuSec float64) []metrics.ToolUsage {
+	byName := make(map[string]*metrics.ToolUsage)
+	for _, tr := range s.procs {
+		u, ok := byName[tr.comm]
+		if !ok {
+			u = &metrics.ToolUsage{Name: tr.comm}
+			byName[tr.comm] = u
+		}
+		u.CPUSec += float64(tr.cpuTicks+tr.lateTicks) / clockTicks
+		u.WallSec += max(0, tr.lastSeen-tr.startOffset)
+		u.Procs++
+		cpuSec -= float64(tr.cpuTicks+tr.lateTicks) / clockTicks
+	}
+	if cpuSec >= 1.0/clockTicks {
+		byName[unattributedTool] = &metrics.ToolUsage{Name: unattributedTool, CPUSec: cpuSec}
+	}
+
+	out := make([]metrics.ToolUsage, 0, len(byName))
+	for _, u := range byName {
+		out = append(out, *u)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].CPUSec != out[j].CPUSec {
+			return out[i].CPUSec > out[j].CPUSec
+		}
+		return out[i].Name < out[j].Name
+	})
+	if topN > 0 && len(out) > topN {
+		out = out[:topN]
+	}
+	return out
+}
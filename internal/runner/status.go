@@ -0,0 +1,112 @@
+package runner
+
+import (
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultStatusInterval é o intervalo padrão entre atualizações da linha de status.
+const DefaultStatusInterval = time.Second
+
+// clearLine volta ao início da linha e apaga até o fim (ANSI).
+const clearLine = "\r\033[K"
+
+// StatusLine mantém uma linha de status no terminal enquanto o comando roda,
+// sem corromper a saída dele: a linha é apagada antes de cada escrita do
+// comando e só volta a ser desenhada quando a saída está no início de uma
+// linha. Comandos que redesenham a própria linha com \r (ex.: ninja) deixam a
+// linha de status escondida.
+type StatusLine struct {
+	Out      io.Writer                          // Onde desenhar, normalmente os.Stderr
+	Interval time.Duration                      // Zero usa DefaultStatusInterval
+	Text     func(elapsed time.Duration) string // Conteúdo da linha
+
+	mu      sync.Mutex
+	shown   bool
+	midLine bool // a última escrita do comando não terminou em quebra de linha
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// IsTerminal indica se f é um terminal, para decidir se vale desenhar a linha de status.
+func IsTerminal(f *os.File) bool {
+	return isTerminal(f)
+}
+
+// start desenha a linha periodicamente a partir de begin, até finish.
+func (s *StatusLine) start(begin time.Time) {
+	interval := s.Interval
+	if interval <= 0 {
+		interval = DefaultStatusInterval
+	}
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+				s.draw(time.Since(begin))
+			}
+		}
+	}()
+}
+
+// finish para as atualizações e apaga a linha, antes do rodapé do bmt.
+func (s *StatusLine) finish() {
+	if s.stop == nil {
+		return
+	}
+	close(s.stop)
+	<-s.done
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hide()
+}
+
+func (s *StatusLine) draw(elapsed time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.midLine {
+		return
+	}
+	io.WriteString(s.Out, clearLine+s.Text(elapsed))
+	s.shown = true
+}
+
+// hide apaga a linha, se desenhada. Deve ser chamada com mu travado.
+func (s *StatusLine) hide() {
+	if s.shown {
+		io.WriteString(s.Out, clearLine)
+		s.shown = false
+	}
+}
+
+// guard retorna um writer que repassa a saída do comando para w, apagando a
+// linha de status antes.
+func (s *StatusLine) guard(w io.Writer) io.Writer {
+	return &statusGuard{status: s, w: w}
+}
+
+type statusGuard struct {
+	status *StatusLine
+	w      io.Writer
+}
+
+func (g *statusGuard) Write(p []byte) (int, error) {
+	s := g.status
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hide()
+	n, err := g.w.Write(p)
+	if n > 0 {
+		s.midLine = p[n-1] != '\n'
+	}
+	return n, err
+}
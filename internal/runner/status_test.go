@@ -0,0 +1,53 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStatusLine_Guard(t *testing.T) {
+	// Linha de status e saída do comando no mesmo "terminal"
+	var term bytes.Buffer
+	s := &StatusLine{Out: &term, Text: func(elapsed time.Duration) string { return "[status]" }}
+	out := s.guard(&term)
+
+	s.draw(0)
+	out.Write([]byte("linha 1\n"))
+	s.draw(0)
+	out.Write([]byte("progresso 10%"))
+	s.draw(0) // no meio de uma linha do comando: não desenha
+	out.Write([]byte("\rprogresso 20%\n"))
+	s.draw(0)
+	s.finish()
+
+	want := clearLine + "[status]" + clearLine + "linha 1\n" +
+		clearLine + "[status]" + clearLine + "progresso 10%" +
+		"\rprogresso 20%\n" +
+		clearLine + "[status]"
+	if got := term.String(); got != want {
+		t.Errorf("terminal = %q, want %q", got, want)
+	}
+}
+
+func TestRunWithOptions_Status(t *testing.T) {
+	var term bytes.Buffer
+	status := &StatusLine{
+		Out:      &term,
+		Interval: 20 * time.Millisecond,
+		Text:     func(elapsed time.Duration) string { return "[rodando]" },
+	}
+	res := RunWithOptions(context.Background(), []string{"sleep", "0.2"}, Options{Status: status})
+	if res.ExitCode != 0 {
+		t.Fatalf("ExitCode = %d", res.ExitCode)
+	}
+	got := term.String()
+	if !strings.Contains(got, "[rodando]") {
+		t.Errorf("linha de status não desenhada: %q", got)
+	}
+	if !strings.HasSuffix(got, clearLine) {
+		t.Errorf("linha de status não apagada ao final: %q", got)
+	}
+}
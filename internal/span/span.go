@@ -0,0 +1,144 @@
+// Package span guarda os spans abertos por bmt span start até o bmt span stop
+// correspondente. Cada span é um arquivo num diretório de estado, para que
+// funcione entre shells e sobreviva a reinicializações.
+package span
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// EnvStateDir sobrepõe o diretório de estado.
+const EnvStateDir = "BMT_STATE_DIR"
+
+var (
+	ErrOpen    = errors.New("span já está aberto")
+	ErrNotOpen = errors.New("span não está aberto")
+)
+
+// Span é um intervalo nomeado em aberto.
+type Span struct {
+	Name    string    `json:"name"`
+	Project string    `json:"project"`
+	Branch  string    `json:"branch,omitempty"`
+	Commit  string    `json:"commit,omitempty"`
+	LogPath string    `json:"log_path"` // Log que recebe o registro no stop
+	Started time.Time `json:"started"`
+}
+
+// Store é o diretório com os spans abertos.
+type Store struct {
+	Dir string
+}
+
+// DefaultDir retorna o diretório dos spans:
+// 1. $BMT_STATE_DIR/spans
+// 2. $XDG_STATE_HOME/bmt/spans (ou ~/.local/state/bmt/spans)
+func DefaultDir() (string, error) {
+	if dir := os.Getenv(EnvStateDir); dir != "" {
+		return filepath.Join(dir, "spans"), nil
+	}
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "bmt", "spans"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "state", "bmt", "spans"), nil
+}
+
+func (s Store) path(name string) string {
+	return filepath.Join(s.Dir, url.PathEscape(name)+".json")
+}
+
+// ValidateName recusa nomes vazios ou com quebras de linha.
+func ValidateName(name string) error {
+	if strings.TrimSpace(name) == "" || strings.ContainsAny(name, "\r\n") {
+		return fmt.Errorf("nome de span inválido: %q", name)
+	}
+	return nil
+}
+
+// Start abre sp. Retorna ErrOpen se já houver um span aberto com o mesmo nome.
+func (s Store) Start(sp Span) error {
+	if err := ValidateName(sp.Name); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(sp)
+	if err != nil {
+		return err
+	}
+	// O_EXCL evita que dois shells abram o mesmo span ao mesmo tempo
+	f, err := os.OpenFile(s.path(sp.Name), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("%w: %s", ErrOpen, sp.Name)
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	return f.Close()
+}
+
+// Get lê o span aberto com o nome informado.
+func (s Store) Get(name string) (Span, error) {
+	var sp Span
+	data, err := os.ReadFile(s.path(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return sp, fmt.Errorf("%w: %s", ErrNotOpen, name)
+	}
+	if err != nil {
+		return sp, err
+	}
+	if err := json.Unmarshal(data, &sp); err != nil {
+		return sp, fmt.Errorf("estado do span %s ilegível: %v", name, err)
+	}
+	return sp, nil
+}
+
+// Remove fecha o span sem registrá-lo.
+func (s Store) Remove(name string) error {
+	err := os.Remove(s.path(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: %s", ErrNotOpen, name)
+	}
+	return err
+}
+
+// List retorna os spans abertos, do mais antigo para o mais novo.
+func (s Store) List() ([]Span, error) {
+	files, err := filepath.Glob(filepath.Join(s.Dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var spans []Span
+	for _, path := range files {
+		name, err := url.PathUnescape(strings.TrimSuffix(filepath.Base(path), ".json"))
+		if err != nil {
+			continue
+		}
+		sp, err := s.Get(name)
+		if err != nil {
+			return nil, err
+		}
+		spans = append(spans, sp)
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].Started.Before(spans[j].Started) })
+	return spans, nil
+}
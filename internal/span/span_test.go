@@ -0,0 +1,59 @@
+package span
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStore(t *testing.T) {
+	store := Store{Dir: t.TempDir()}
+	started := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	review := Span{Name: "code review", Project: "backend", LogPath: "/logs/a.jsonl", Started: started}
+	deploy := Span{Name: "deploy/prod", Project: "backend", Started: started.Add(-time.Hour)}
+
+	for _, sp := range []Span{review, deploy} {
+		if err := store.Start(sp); err != nil {
+			t.Fatalf("Start(%q) erro: %v", sp.Name, err)
+		}
+	}
+	if err := store.Start(review); !errors.Is(err, ErrOpen) {
+		t.Errorf("Start() repetido = %v, want ErrOpen", err)
+	}
+	if err := store.Start(Span{Name: " "}); err == nil {
+		t.Error("Start() com nome vazio deveria falhar")
+	}
+
+	// Outro Store no mesmo diretório simula outro shell
+	got, err := Store{Dir: store.Dir}.Get("code review")
+	if err != nil || !got.Started.Equal(started) || got.LogPath != "/logs/a.jsonl" {
+		t.Errorf("Get() = %+v, %v", got, err)
+	}
+
+	spans, err := store.List()
+	if err != nil || len(spans) != 2 || spans[0].Name != "deploy/prod" || spans[1].Name != "code review" {
+		t.Errorf("List() = %+v, %v; want deploy/prod e depois code review", spans, err)
+	}
+
+	if err := store.Remove("code review"); err != nil {
+		t.Fatalf("Remove() erro: %v", err)
+	}
+	if _, err := store.Get("code review"); !errors.Is(err, ErrNotOpen) {
+		t.Errorf("Get() após Remove = %v, want ErrNotOpen", err)
+	}
+	if err := store.Remove("code review"); !errors.Is(err, ErrNotOpen) {
+		t.Errorf("Remove() repetido = %v, want ErrNotOpen", err)
+	}
+}
+
+func TestDefaultDir(t *testing.T) {
+	t.Setenv(EnvStateDir, "/estado")
+	if got, _ := DefaultDir(); got != "/estado/spans" {
+		t.Errorf("DefaultDir() com %s = %q", EnvStateDir, got)
+	}
+	t.Setenv(EnvStateDir, "")
+	t.Setenv("XDG_STATE_HOME", "/xdg")
+	if got, _ := DefaultDir(); got != "/xdg/bmt/spans" {
+		t.Errorf("DefaultDir() com XDG_STATE_HOME = %q", got)
+	}
+}
@@ -0,0 +1,82 @@
+package stats
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// MannWhitneyU aplica o teste de Mann-Whitney U (não paramétrico) às amostras
+// a e b. Retorna U de a e o p-valor bicaudal pela aproximação normal, com
+// correção de continuidade e para empates. Sem dispersão, p é 1.
+func MannWhitneyU(a, b []float64) (u, p float64) {
+	n1, n2 := float64(len(a)), float64(len(b))
+	if n1 == 0 || n2 == 0 {
+		return 0, 1
+	}
+	type sample struct {
+		value float64
+		fromA bool
+	}
+	all := make([]sample, 0, len(a)+len(b))
+	for _, v := range a {
+		all = append(all, sample{v, true})
+	}
+	for _, v := range b {
+		all = append(all, sample{v, false})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].value < all[j].value })
+
+	// Valores empatados recebem a média dos postos que ocupam
+	var rankSumA, ties float64
+	for i := 0; i < len(all); {
+		j := i
+		for j < len(all) && all[j].value == all[i].value {
+			j++
+		}
+		rank := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			if all[k].fromA {
+				rankSumA += rank
+			}
+		}
+		t := float64(j - i)
+		ties += t*t*t - t
+		i = j
+	}
+
+	n := n1 + n2
+	u = rankSumA - n1*(n1+1)/2
+	mean := n1 * n2 / 2
+	variance := n1 * n2 / 12 * ((n + 1) - ties/(n*(n-1)))
+	if variance <= 0 {
+		return u, 1
+	}
+	z := (math.Abs(u-mean) - 0.5) / math.Sqrt(variance)
+	if z < 0 {
+		z = 0
+	}
+	return u, math.Erfc(z / math.Sqrt2)
+}
+
+// BootstrapRatioCI estima o intervalo de confiança da razão stat(a)/stat(b)
+// pelo método percentil: reamostra a e b com reposição resamples vezes.
+// confidence fica entre 0 e 1 (ex.: 0.95).
+func BootstrapRatioCI(a, b []float64, stat func([]float64) float64, resamples int, confidence float64, rng *rand.Rand) (lo, hi float64) {
+	if len(a) == 0 || len(b) == 0 || resamples <= 0 {
+		return math.NaN(), math.NaN()
+	}
+	resample := func(dst, src []float64) []float64 {
+		for i := range dst {
+			dst[i] = src[rng.Intn(len(src))]
+		}
+		return dst
+	}
+	bufA, bufB := make([]float64, len(a)), make([]float64, len(b))
+	ratios := make([]float64, resamples)
+	for i := range ratios {
+		ratios[i] = stat(resample(bufA, a)) / stat(resample(bufB, b))
+	}
+	tail := (1 - confidence) / 2 * 100
+	return Percentile(ratios, tail), Percentile(ratios, 100-tail)
+}
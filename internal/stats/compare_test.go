@@ -0,0 +1,62 @@
+package stats
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestMannWhitneyU(t *testing.T) {
+	tests := []struct {
+		name  string
+		a, b  []float64
+		wantU float64
+		pLow  float64 // p esperado dentro de [pLow, pHigh]
+		pHigh float64
+	}{
+		{
+			name:  "Amostras separadas",
+			a:     []float64{10, 11, 12, 13, 14, 15, 16, 17},
+			b:     []float64{20, 21, 22, 23, 24, 25, 26, 27},
+			wantU: 0,
+			pLow:  0, pHigh: 0.001,
+		},
+		{
+			name:  "Amostras misturadas",
+			a:     []float64{1, 3, 5, 7},
+			b:     []float64{2, 4, 6, 8},
+			wantU: 6,
+			pLow:  0.6, pHigh: 1,
+		},
+		{
+			name:  "Todos iguais",
+			a:     []float64{5, 5, 5},
+			b:     []float64{5, 5, 5},
+			wantU: 4.5,
+			pLow:  1, pHigh: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, p := MannWhitneyU(tt.a, tt.b)
+			if u != tt.wantU {
+				t.Errorf("U = %v, want %v", u, tt.wantU)
+			}
+			if p < tt.pLow || p > tt.pHigh {
+				t.Errorf("p = %v, want entre %v e %v", p, tt.pLow, tt.pHigh)
+			}
+		})
+	}
+}
+
+func TestBootstrapRatioCI(t *testing.T) {
+	a := []float64{20, 21, 19, 20.5, 19.5, 20.2, 20.8, 19.7}
+	b := []float64{10, 10.5, 9.5, 10.2, 9.8, 10.1, 10.4, 9.9}
+	lo, hi := BootstrapRatioCI(a, b, Median, 2000, 0.95, rand.New(rand.NewSource(1)))
+	if !(lo < 2 && 2 < hi) || lo < 1.8 || hi > 2.2 {
+		t.Errorf("IC = [%v, %v], want em torno de 2", lo, hi)
+	}
+	if lo, hi := BootstrapRatioCI(nil, b, Median, 100, 0.95, rand.New(rand.NewSource(1))); !math.IsNaN(lo) || !math.IsNaN(hi) {
+		t.Errorf("IC sem amostras = [%v, %v], want NaN", lo, hi)
+	}
+}
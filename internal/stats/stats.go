@@ -0,0 +1,119 @@
+// Package stats reúne as estatísticas descritivas usadas pelos relatórios.
+package stats
+
+import (
+	"math"
+	"sort"
+)
+
+// Mean retorna a média aritmética de values (0 se vazio).
+func Mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// Percentile retorna o percentil p (0-100) de values, interpolando linearmente
+// entre as duas amostras mais próximas. values não é modificado.
+func Percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	return percentileSorted(sorted, p)
+}
+
+// Median retorna a mediana de values.
+func Median(values []float64) float64 {
+	return Percentile(values, 50)
+}
+
+func percentileSorted(sorted []float64, p float64) float64 {
+	if p <= 0 {
+		return sorted[0]
+	}
+	if p >= 100 {
+		return sorted[len(sorted)-1]
+	}
+	rank := p / 100 * float64(len(sorted)-1)
+	lo := int(math.Floor(rank))
+	hi := int(math.Ceil(rank))
+	return sorted[lo] + (sorted[hi]-sorted[lo])*(rank-float64(lo))
+}
+
+// Summary resume uma distribuição de valores.
+type Summary struct {
+	Count  int
+	Mean   float64
+	Min    float64
+	P10    float64
+	Median float64
+	P90    float64
+	Max    float64
+}
+
+// Summarize calcula Summary para values (zero se vazio).
+func Summarize(values []float64) Summary {
+	if len(values) == 0 {
+		return Summary{}
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	return Summary{
+		Count:  len(sorted),
+		Mean:   Mean(sorted),
+		Min:    sorted[0],
+		P10:    percentileSorted(sorted, 10),
+		Median: percentileSorted(sorted, 50),
+		P90:    percentileSorted(sorted, 90),
+		Max:    sorted[len(sorted)-1],
+	}
+}
+
+// StdDev retorna o desvio padrão amostral de values (0 com menos de dois valores).
+func StdDev(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	mean := Mean(values)
+	var sum float64
+	for _, v := range values {
+		sum += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(sum / float64(len(values)-1))
+}
+
+// OutlierThreshold é o z-score modificado a partir do qual um valor é
+// considerado outlier (Iglewicz e Hoaglin).
+const OutlierThreshold = 3.5
+
+// Outliers retorna os índices de values cujo z-score modificado, baseado na
+// mediana e no desvio absoluto mediano, passa de OutlierThreshold. Sem
+// dispersão (desvio absoluto mediano zero) nenhum valor é outlier.
+func Outliers(values []float64) []int {
+	if len(values) < 3 {
+		return nil
+	}
+	median := Median(values)
+	deviations := make([]float64, len(values))
+	for i, v := range values {
+		deviations[i] = math.Abs(v - median)
+	}
+	mad := Median(deviations)
+	if mad == 0 {
+		return nil
+	}
+	var idx []int
+	for i, d := range deviations {
+		if 0.6745*d/mad > OutlierThreshold {
+			idx = append(idx, i)
+		}
+	}
+	return idx
+}
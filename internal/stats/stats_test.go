@@ -0,0 +1,82 @@
+package stats
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPercentile(t *testing.T) {
+	values := []float64{40, 10, 30, 20}
+	tests := []struct {
+		p    float64
+		want float64
+	}{
+		{0, 10},
+		{25, 17.5},
+		{50, 25},
+		{90, 37},
+		{100, 40},
+	}
+	for _, tt := range tests {
+		if got := Percentile(values, tt.p); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Percentile(%v) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+	if values[0] != 40 {
+		t.Error("Percentile() não deveria reordenar a entrada")
+	}
+	if !math.IsNaN(Percentile(nil, 50)) {
+		t.Error("Percentile(vazio) deveria ser NaN")
+	}
+	if got := Median([]float64{7}); got != 7 {
+		t.Errorf("Median([7]) = %v", got)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	got := Summarize([]float64{5, 1, 3, 2, 4})
+	want := Summary{Count: 5, Mean: 3, Min: 1, P10: 1.4, Median: 3, P90: 4.6, Max: 5}
+	if got.Count != want.Count || got.Min != want.Min || got.Max != want.Max ||
+		math.Abs(got.Mean-want.Mean) > 1e-9 || math.Abs(got.P10-want.P10) > 1e-9 ||
+		math.Abs(got.Median-want.Median) > 1e-9 || math.Abs(got.P90-want.P90) > 1e-9 {
+		t.Errorf("Summarize() = %+v, want %+v", got, want)
+	}
+	if (Summarize(nil) != Summary{}) {
+		t.Error("Summarize(vazio) deveria ser zero")
+	}
+}
+
+func TestStdDev(t *testing.T) {
+	if got := StdDev([]float64{2, 4, 4, 4, 5, 5, 7, 9}); math.Abs(got-2.138) > 0.001 {
+		t.Errorf("StdDev = %v, want ~2.138", got)
+	}
+	if got := StdDev([]float64{5}); got != 0 {
+		t.Errorf("StdDev de um valor = %v, want 0", got)
+	}
+}
+
+func TestOutliers(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   []int
+	}{
+		{"Sem outliers", []float64{10, 10.2, 9.9, 10.1, 10.3}, nil},
+		{"Execução lenta", []float64{10, 10.2, 9.9, 25, 10.1, 10.3}, []int{3}},
+		{"Sem dispersão", []float64{10, 10, 10, 10, 12}, nil},
+		{"Poucos valores", []float64{1, 100}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Outliers(tt.values)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Outliers = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Outliers = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+// Package toolchain identifica as versões das ferramentas de build instaladas
+// (compiladores, cmake, go...) e resume o conjunto em uma impressão digital.
+package toolchain
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Tool é uma ferramenta cuja versão entra na impressão digital. Command é
+// executado com o executável encontrado no PATH para Command[0].
+type Tool struct {
+	Name    string   `json:"name"`
+	Command []string `json:"command"`
+}
+
+// DefaultTools são consultadas quando a configuração não define toolchain.
+// Ferramentas que não estão no PATH são ignoradas.
+var DefaultTools = []Tool{
+	{Name: "gcc", Command: []string{"gcc", "--version"}},
+	{Name: "clang", Command: []string{"clang", "--version"}},
+	{Name: "cmake", Command: []string{"cmake", "--version"}},
+	{Name: "ninja", Command: []string{"ninja", "--version"}},
+	{Name: "make", Command: []string{"make", "--version"}},
+	{Name: "go", Command: []string{"go", "version"}},
+	{Name: "rustc", Command: []string{"rustc", "--version"}},
+}
+
+// Timeout limita cada consulta de versão.
+const Timeout = 10 * time.Second
+
+// Version é a versão detectada de uma ferramenta: a primeira linha não vazia
+// da saída do comando.
+type Version struct {
+	Name    string `json:"name"`
+	Path    string `json:"path"`
+	Version string `json:"version"`
+}
+
+// Fingerprint resume as versões em um hash curto, independente da ordem.
+// Retorna "" se nenhuma ferramenta foi encontrada.
+func Fingerprint(versions []Version) string {
+	if len(versions) == 0 {
+		return ""
+	}
+	lines := make([]string, len(versions))
+	for i, v := range versions {
+		lines[i] = v.Name + "=" + v.Version
+	}
+	sort.Strings(lines)
+	sum := sha256.Sum256([]byte(strings.Join(lines, "\n")))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// cacheEntry guarda a versão de um executável enquanto ele não muda no disco.
+type cacheEntry struct {
+	ModTime time.Time `json:"mod_time"`
+	Size    int64     `json:"size"`
+	Version string    `json:"version"`
+}
+
+// Detector consulta as versões das ferramentas. Como rodar `gcc --version` a
+// cada build custa caro, o resultado é guardado em cache por máquina e só é
+// refeito quando o executável muda (caminho, data de modificação ou tamanho).
+type Detector struct {
+	CachePath string // Vazio desativa o cache
+	LookPath  func(file string) (string, error)
+	Stat      func(name string) (os.FileInfo, error)
+	Exec      func(ctx context.Context, args []string) ([]byte, error)
+}
+
+// NewDetector cria um Detector com cache em DefaultCachePath.
+func NewDetector() *Detector {
+	cachePath, _ := DefaultCachePath()
+	return &Detector{
+		CachePath: cachePath,
+		LookPath:  exec.LookPath,
+		Stat:      os.Stat,
+		Exec: func(ctx context.Context, args []string) ([]byte, error) {
+			// Algumas ferramentas escrevem a versão em stderr
+			return exec.CommandContext(ctx, args[0], args[1:]...).CombinedOutput()
+		},
+	}
+}
+
+// DefaultCachePath retorna o cache local de versões (~/.cache/bmt/toolchain.json).
+func DefaultCachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "bmt", "toolchain.json"), nil
+}
+
+// Detect retorna as versões das ferramentas encontradas, na ordem de tools.
+// Ferramentas ausentes ou cujo comando falha são ignoradas; o erro retornado
+// se refere apenas ao cache e não invalida as versões.
+func (d *Detector) Detect(tools []Tool) ([]Version, error) {
+	cache := d.loadCache()
+	changed := false
+	var versions []Version
+	for _, tool := range tools {
+		if len(tool.Command) == 0 {
+			continue
+		}
+		path, err := d.LookPath(tool.Command[0])
+		if err != nil {
+			continue
+		}
+		// Stat segue links simbólicos, então trocar o alvo (ex.: update-alternatives)
+		// invalida o cache; o comando roda pelo link porque executáveis como os
+		// proxies do rustup dependem de argv[0]
+		info, err := d.Stat(path)
+		if err != nil {
+			continue
+		}
+
+		key := path + " " + strings.Join(tool.Command[1:], " ")
+		entry, ok := cache[key]
+		if !ok || !entry.ModTime.Equal(info.ModTime()) || entry.Size != info.Size() {
+			version, err := d.version(path, tool.Command[1:])
+			if err != nil {
+				continue
+			}
+			entry = cacheEntry{ModTime: info.ModTime(), Size: info.Size(), Version: version}
+			cache[key] = entry
+			changed = true
+		}
+		versions = append(versions, Version{Name: tool.Name, Path: path, Version: entry.Version})
+	}
+	if changed {
+		return versions, d.saveCache(cache)
+	}
+	return versions, nil
+}
+
+func (d *Detector) version(path string, args []string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	defer cancel()
+	out, err := d.Exec(ctx, append([]string{path}, args...))
+	if err != nil {
+		return "", err
+	}
+	for _, line := range bytes.Split(out, []byte{'\n'}) {
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			return string(line), nil
+		}
+	}
+	return "", errors.New("saída vazia")
+}
+
+func (d *Detector) loadCache() map[string]cacheEntry {
+	cache := make(map[string]cacheEntry)
+	if d.CachePath == "" {
+		return cache
+	}
+	data, err := os.ReadFile(d.CachePath)
+	if err != nil {
+		return cache
+	}
+	// Um cache corrompido é descartado e refeito
+	if json.Unmarshal(data, &cache) != nil {
+		return make(map[string]cacheEntry)
+	}
+	return cache
+}
+
+func (d *Detector) saveCache(cache map[string]cacheEntry) error {
+	if d.CachePath == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(d.CachePath), 0755); err != nil {
+		return fmt.Errorf("cache de toolchain: %v", err)
+	}
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Grava em um temporário e renomeia, para que execuções simultâneas não leiam um arquivo pela metade
+	tmp := fmt.Sprintf("%s.%d.tmp", d.CachePath, os.Getpid())
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("cache de toolchain: %v", err)
+	}
+	if err := os.Rename(tmp, d.CachePath); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("cache de toolchain: %v", err)
+	}
+	return nil
+}
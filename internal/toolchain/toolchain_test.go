@@ -0,0 +1,109 @@
+package toolchain
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDetector_Detect(t *testing.T) {
+	dir := t.TempDir()
+	gcc := filepath.Join(dir, "gcc")
+	cmake := filepath.Join(dir, "cmake")
+	for _, f := range []string{gcc, cmake} {
+		if err := os.WriteFile(f, []byte("bin"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	outputs := map[string]string{
+		gcc:   "\ngcc (GCC) 13.2.0\nCopyright (C) 2023 Free Software Foundation, Inc.\n",
+		cmake: "cmake version 3.28.1\n\nCMake suite maintained by Kitware\n",
+	}
+	var calls []string
+	d := &Detector{
+		CachePath: filepath.Join(dir, "cache", "toolchain.json"),
+		LookPath: func(file string) (string, error) {
+			switch file {
+			case "gcc":
+				return gcc, nil
+			case "cmake":
+				return cmake, nil
+			case "broken":
+				return cmake, nil
+			}
+			return "", errors.New("not found")
+		},
+		Stat: os.Stat,
+		Exec: func(ctx context.Context, args []string) ([]byte, error) {
+			calls = append(calls, args[0])
+			if len(args) > 1 && args[1] == "--fail" {
+				return nil, errors.New("exit status 1")
+			}
+			return []byte(outputs[args[0]]), nil
+		},
+	}
+	tools := []Tool{
+		{Name: "gcc", Command: []string{"gcc", "--version"}},
+		{Name: "clang", Command: []string{"clang", "--version"}},
+		{Name: "cmake", Command: []string{"cmake", "--version"}},
+		{Name: "broken", Command: []string{"broken", "--fail"}},
+	}
+	want := []Version{
+		{Name: "gcc", Path: gcc, Version: "gcc (GCC) 13.2.0"},
+		{Name: "cmake", Path: cmake, Version: "cmake version 3.28.1"},
+	}
+
+	got, err := d.Detect(tools)
+	if err != nil {
+		t.Fatalf("Detect() erro: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Detect() = %+v, want %+v", got, want)
+	}
+	if len(calls) != 3 {
+		t.Errorf("comandos executados = %v, want 3", calls)
+	}
+
+	// Segunda detecção vem do cache (a ferramenta que falha é tentada de novo)
+	calls = nil
+	if got, _ := d.Detect(tools); !reflect.DeepEqual(got, want) {
+		t.Errorf("Detect() com cache = %+v, want %+v", got, want)
+	}
+	if !reflect.DeepEqual(calls, []string{cmake}) {
+		t.Errorf("comandos executados com cache = %v, want só o que falhou", calls)
+	}
+
+	// Atualizar o executável invalida o cache
+	calls = nil
+	outputs[gcc] = "gcc (GCC) 14.1.0\n"
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(gcc, later, later); err != nil {
+		t.Fatal(err)
+	}
+	got, _ = d.Detect(tools)
+	if got[0].Version != "gcc (GCC) 14.1.0" {
+		t.Errorf("versão após atualização = %q", got[0].Version)
+	}
+	if Fingerprint(got) == Fingerprint(want) {
+		t.Error("Fingerprint() deveria mudar com a versão do gcc")
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	a := []Version{{Name: "gcc", Version: "gcc 13"}, {Name: "go", Version: "go1.25"}}
+	b := []Version{{Name: "go", Version: "go1.25", Path: "/outro/go"}, {Name: "gcc", Version: "gcc 13"}}
+	if Fingerprint(a) != Fingerprint(b) {
+		t.Error("Fingerprint() deveria ignorar a ordem e o caminho")
+	}
+	if len(Fingerprint(a)) != 12 {
+		t.Errorf("Fingerprint() = %q, want 12 caracteres", Fingerprint(a))
+	}
+	if Fingerprint(nil) != "" {
+		t.Error("Fingerprint(nil) deveria ser vazio")
+	}
+}
@@ -4,13 +4,26 @@ import (
 	"dev-metrics/internal/metrics" // Ajuste o import conforme seu module
 	"fmt"
 	"io"
+	"regexp"
 )
 
 func RenderReportTable(w io.Writer, report *metrics.FullReport, totalUnit metrics.DurationUnit) {
 	avgHeader := "Média (auto)"
 	totalHeader := "Total"
+	groupLabel := "Projeto"
+	switch report.GroupBy {
+	case metrics.GroupByTool:
+		groupLabel = "Ferramenta"
+		totalHeader = "CPU"
+	case metrics.GroupByPhase:
+		groupLabel = "Fase"
+	case metrics.GroupByHost:
+		groupLabel = "Máquina"
+	case metrics.GroupByTarget:
+		groupLabel = "Alvo"
+	}
 	if totalUnit != metrics.DurationAuto {
-		totalHeader = fmt.Sprintf("Total (%s)", metrics.DurationUnitLabel(totalUnit))
+		totalHeader = fmt.Sprintf("%s (%s)", totalHeader, metrics.DurationUnitLabel(totalUnit))
 	}
 
 	// Mostrar intervalo do relatório se fornecido
@@ -27,7 +40,7 @@ func RenderReportTable(w io.Writer, report *metrics.FullReport, totalUnit metric
 	}
 
 	for _, proj := range report.Projects {
-		fmt.Fprintf(w, "\n%-12s : %-12s\n", "Projeto", proj.Name)
+		fmt.Fprintf(w, "\n%-12s : %-12s\n", groupLabel, proj.Name)
 		fmt.Fprintln(w, "====================================================")
 		fmt.Fprintf(w, "%-12s | %-12s | %-12s | %-10s\n", "Semana", totalHeader, avgHeader, "Builds")
 		fmt.Fprintln(w, "----------------------------------------------------")
@@ -55,4 +68,303 @@ func RenderReportTable(w io.Writer, report *metrics.FullReport, totalUnit metric
 	fmt.Fprintf(w, "%-12s | %-12s | %-12s | %-10d\n",
 		"", globalTotalStr, "-", report.GlobalBuilds)
 	fmt.Fprintln(w, "====================================================")
+
+	if report.GroupBy == metrics.GroupByTool {
+		if len(report.Projects) == 0 {
+			fmt.Fprintln(w, "\nNenhum registro com detalhamento por ferramenta (use 'bmt run --sample').")
+		} else {
+			fmt.Fprintln(w, "\nTotais em tempo de CPU; Builds conta as execuções em que a ferramenta apareceu.")
+		}
+	}
+	if report.GroupBy == metrics.GroupByPhase && len(report.Projects) == 0 {
+		fmt.Fprintln(w, "\nNenhum registro com fases (emita '::bmt-phase name=<fase>::' na saída do comando).")
+	}
+	if report.GroupBy == metrics.GroupByTarget {
+		if len(report.Projects) == 0 {
+			fmt.Fprintln(w, "\nNenhum alvo registrado (meça o build com 'bmt make' ou 'bmt run ninja', ou importe com 'bmt ninja-log').")
+		} else {
+			fmt.Fprintln(w, "\nBuilds conta as execuções do alvo; Relatório Geral conta os builds com alvos (bmt make ou .ninja_log).")
+		}
+	}
+
+	renderContended(w, report.Contended)
+	if report.Skipped > 0 || report.InvalidTimestamps > 0 {
+		fmt.Fprintf(w, "\nAviso: %d linhas inválidas e %d registros com timestamp inválido foram ignorados (verifique com 'bmt fsck').\n",
+			report.Skipped, report.InvalidTimestamps)
+	}
+}
+
+// RenderIOTable escreve o relatório de I/O, uma linha por execução.
+func RenderIOTable(w io.Writer, report *metrics.IOReport) {
+	fmt.Fprintf(w, "%-20s | %-12s | %-10s | %-10s | %-10s | %-10s | %-10s | %-10s | %s\n",
+		"Data", "Projeto", "Duração", "Lido", "Escrito", "rchar", "wchar", "Espera I/O", "Comando")
+	fmt.Fprintln(w, "---------------------------------------------------------------------------------------------------------------------")
+	for _, b := range report.Builds {
+		fmt.Fprintf(w, "%-20s | %-12s | %-10s | %-10s | %-10s | %-10s | %-10s | %-10s | %s\n",
+			b.Timestamp, truncate(b.Project, 12),
+			metrics.FormatDuration(b.DurationSec, metrics.DurationAuto, true),
+			formatBytes(b.ReadBytes), formatBytes(b.WriteBytes), formatBytes(b.ReadChars), formatBytes(b.WriteChars),
+			metrics.FormatDuration(b.BlkioWaitSec, metrics.DurationAuto, true), truncate(b.Command, 40))
+	}
+	fmt.Fprintln(w, "---------------------------------------------------------------------------------------------------------------------")
+	t := report.Total
+	fmt.Fprintf(w, "%-20s | %-12s | %-10s | %-10s | %-10s | %-10s | %-10s | %-10s |\n",
+		"Total", fmt.Sprintf("%d builds", len(report.Builds)), "",
+		formatBytes(t.ReadBytes), formatBytes(t.WriteBytes), formatBytes(t.ReadChars), formatBytes(t.WriteChars),
+		metrics.FormatDuration(t.BlkioWaitSec, metrics.DurationAuto, true))
+
+	if len(report.Builds) == 0 {
+		fmt.Fprintln(w, "\nNenhum registro com dados de I/O no período.")
+	}
+	if report.WithoutIO > 0 {
+		fmt.Fprintf(w, "\n%d registros sem dados de I/O foram omitidos.\n", report.WithoutIO)
+	}
+	renderContended(w, report.Contended)
+	if report.Skipped > 0 || report.InvalidTimestamps > 0 {
+		fmt.Fprintf(w, "\nAviso: %d linhas inválidas e %d registros com timestamp inválido foram ignorados (verifique com 'bmt fsck').\n",
+			report.Skipped, report.InvalidTimestamps)
+	}
+}
+
+// renderContended avisa quantas execuções foram ignoradas por contenção.
+func renderContended(w io.Writer, n int) {
+	if n > 0 {
+		fmt.Fprintf(w, "\n%d execuções sob contenção foram ignoradas (carga alta, outros builds, bateria ou throttling).\n", n)
+	}
+}
+
+// formatBytes formata uma quantidade de bytes em base 1024.
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := uint64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// truncate corta s em n runas, indicando o corte com "…".
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
+
+// RenderFailureTable escreve o tempo perdido com falhas por projeto e classe.
+func RenderFailureTable(w io.Writer, report *metrics.FailureReport, totalUnit metrics.DurationUnit) {
+	lostHeader := "Tempo perdido"
+	if totalUnit != metrics.DurationAuto {
+		lostHeader = fmt.Sprintf("%s (%s)", lostHeader, metrics.DurationUnitLabel(totalUnit))
+	}
+	formatLost := func(sec float64) string {
+		return metrics.FormatDuration(sec, totalUnit, totalUnit == metrics.DurationAuto)
+	}
+
+	project := ""
+	for i, e := range report.Entries {
+		if i == 0 || e.Project != project {
+			project = e.Project
+			fmt.Fprintf(w, "\n%-12s : %-12s\n", "Projeto", project)
+			fmt.Fprintln(w, "====================================================")
+			fmt.Fprintf(w, "%-18s | %-16s | %-10s\n", "Classe", lostHeader, "Falhas")
+			fmt.Fprintln(w, "----------------------------------------------------")
+		}
+		fmt.Fprintf(w, "%-18s | %-16s | %-10d\n", e.Class, formatLost(e.LostSec), e.Count)
+	}
+
+	fmt.Fprintf(w, "\nFalhas por classe: \n")
+	fmt.Fprintln(w, "====================================================")
+	fmt.Fprintf(w, "%-18s | %-16s | %-10s\n", "Classe", lostHeader, "Falhas")
+	fmt.Fprintln(w, "----------------------------------------------------")
+	for _, c := range report.ByClass {
+		fmt.Fprintf(w, "%-18s | %-16s | %-10d\n", c.Class, formatLost(c.LostSec), c.Count)
+	}
+	fmt.Fprintln(w, "----------------------------------------------------")
+	fmt.Fprintf(w, "%-18s | %-16s | %d de %d execuções\n", "Total", formatLost(report.LostSec), report.Failed, report.Runs)
+	fmt.Fprintln(w, "====================================================")
+
+	renderContended(w, report.Contended)
+	if report.Skipped > 0 || report.InvalidTimestamps > 0 {
+		fmt.Fprintf(w, "\nAviso: %d linhas inválidas e %d registros com timestamp inválido foram ignorados (verifique com 'bmt fsck').\n",
+			report.Skipped, report.InvalidTimestamps)
+	}
+}
+
+// RenderFlakyTable lista os comandos que falharam e passaram no mesmo commit,
+// pelo maior tempo perdido com as falhas.
+func RenderFlakyTable(w io.Writer, report *metrics.FlakyReport, totalUnit metrics.DurationUnit) {
+	lostHeader := "Tempo perdido"
+	if totalUnit != metrics.DurationAuto {
+		lostHeader = fmt.Sprintf("%s (%s)", lostHeader, metrics.DurationUnitLabel(totalUnit))
+	}
+	formatLost := func(sec float64) string {
+		return metrics.FormatDuration(sec, totalUnit, totalUnit == metrics.DurationAuto)
+	}
+
+	if len(report.Entries) == 0 {
+		fmt.Fprintln(w, "\nNenhum comando instável: nenhum comando falhou e passou no mesmo commit.")
+	} else {
+		fmt.Fprintf(w, "\nComandos instáveis (falharam e passaram no mesmo commit): \n")
+		fmt.Fprintln(w, "====================================================")
+		fmt.Fprintf(w, "%-12s | %-9s | %-16s | %-6s | %-8s | %s\n", "Projeto", "Commit", lostHeader, "Falhas", "Sucessos", "Comando")
+		fmt.Fprintln(w, "----------------------------------------------------")
+		for _, e := range report.Entries {
+			fmt.Fprintf(w, "%-12s | %-9s | %-16s | %-6d | %-8d | %s\n", e.Project, e.Commit, formatLost(e.WastedSec), e.Failures, e.Successes, e.Command)
+		}
+		fmt.Fprintln(w, "----------------------------------------------------")
+		fmt.Fprintf(w, "%-12s | %-9s | %-16s | %d comandos\n", "Total", "", formatLost(report.WastedSec), len(report.Entries))
+		fmt.Fprintln(w, "====================================================")
+	}
+
+	if report.Skipped > 0 || report.InvalidTimestamps > 0 {
+		fmt.Fprintf(w, "\nAviso: %d linhas inválidas e %d registros com timestamp inválido foram ignorados (verifique com 'bmt fsck').\n",
+			report.Skipped, report.InvalidTimestamps)
+	}
+}
+
+// RenderCacheTable escreve a taxa de acerto de cache por projeto e a duração
+// média dos builds em cada faixa de acerto.
+func RenderCacheTable(w io.Writer, report *metrics.CacheReport) {
+	for _, e := range report.Entries {
+		fmt.Fprintf(w, "\n%-12s : %-12s\n", "Projeto", e.Project)
+		fmt.Fprintf(w, "%-12s : %-12s\n", "Cache", e.Probe)
+		fmt.Fprintf(w, "%-12s : %.1f%% (%.0f acertos, %.0f falhas em %d builds)\n", "Acerto", e.HitRate*100, e.Hits, e.Misses, e.Builds)
+		fmt.Fprintln(w, "====================================================")
+		fmt.Fprintf(w, "%-12s | %-12s | %-10s\n", "Acerto", "Média (auto)", "Builds")
+		fmt.Fprintln(w, "----------------------------------------------------")
+		for _, b := range e.Buckets {
+			avg := "-"
+			if b.Count > 0 {
+				avg = metrics.FormatDuration(b.AvgDurationSec, metrics.DurationAuto, true)
+			}
+			fmt.Fprintf(w, "%-12s | %-12s | %-10d\n", b.Label, avg, b.Count)
+		}
+		fmt.Fprintln(w, "====================================================")
+	}
+
+	if len(report.Entries) == 0 {
+		fmt.Fprintln(w, "\nNenhum build com contadores de cache (configure uma probe de ccache ou sccache).")
+	}
+	if report.WithoutCache > 0 {
+		fmt.Fprintf(w, "\n%d builds sem contadores de cache foram omitidos.\n", report.WithoutCache)
+	}
+	renderContended(w, report.Contended)
+	if report.Skipped > 0 || report.InvalidTimestamps > 0 {
+		fmt.Fprintf(w, "\nAviso: %d linhas inválidas e %d registros com timestamp inválido foram ignorados (verifique com 'bmt fsck').\n",
+			report.Skipped, report.InvalidTimestamps)
+	}
+}
+
+// RenderToolchainTable escreve a distribuição das durações de cada projeto
+// por toolchain, em ordem cronológica, com as ferramentas que mudaram.
+func RenderToolchainTable(w io.Writer, report *metrics.ToolchainReport) {
+	format := func(sec float64) string { return metrics.FormatDuration(sec, metrics.DurationAuto, true) }
+	for _, proj := range report.Projects {
+		fmt.Fprintf(w, "\n%-12s : %-12s\n", "Projeto", proj.Name)
+		fmt.Fprintln(w, "==========================================================================================")
+		fmt.Fprintf(w, "%-12s | %-23s | %-6s | %-10s | %-10s | %-10s | %s\n", "Toolchain", "Período", "Builds", "p10", "Mediana", "p90", "Δ mediana")
+		fmt.Fprintln(w, "------------------------------------------------------------------------------------------")
+		for i, p := range proj.Periods {
+			delta := "-"
+			if i > 0 {
+				if prev := proj.Periods[i-1].Duration.Median; prev > 0 {
+					delta = fmt.Sprintf("%+.1f%%", (p.Duration.Median-prev)/prev*100)
+				}
+			}
+			period := p.FirstRun.Format("2006-01-02") + " a " + p.LastRun.Format("2006-01-02")
+			fmt.Fprintf(w, "%-12s | %-23s | %-6d | %-10s | %-10s | %-10s | %s\n", p.Fingerprint, period, p.Duration.Count,
+				format(p.Duration.P10), format(p.Duration.Median), format(p.Duration.P90), delta)
+			for _, c := range p.Changes {
+				switch {
+				case c.From == "":
+					fmt.Fprintf(w, "  + %s %s\n", c.Tool, shortVersion(c.To))
+				case c.To == "":
+					fmt.Fprintf(w, "  - %s %s\n", c.Tool, shortVersion(c.From))
+				default:
+					fmt.Fprintf(w, "  ~ %s %s -> %s\n", c.Tool, shortVersion(c.From), shortVersion(c.To))
+				}
+			}
+			if i > 0 && p.Versions == nil {
+				fmt.Fprintln(w, "  (versões desconhecidas: impressão digital ausente de toolchains.jsonl)")
+			}
+		}
+		fmt.Fprintln(w, "==========================================================================================")
+	}
+
+	if len(report.Projects) == 0 {
+		fmt.Fprintln(w, "\nNenhum build com impressão digital de toolchain no período.")
+	}
+	if report.WithoutToolchain > 0 {
+		fmt.Fprintf(w, "\n%d builds sem impressão digital de toolchain foram omitidos.\n", report.WithoutToolchain)
+	}
+	renderContended(w, report.Contended)
+	if report.Skipped > 0 || report.InvalidTimestamps > 0 {
+		fmt.Fprintf(w, "\nAviso: %d linhas inválidas e %d registros com timestamp inválido foram ignorados (verifique com 'bmt fsck').\n",
+			report.Skipped, report.InvalidTimestamps)
+	}
+}
+
+var versionNumber = regexp.MustCompile(`\d+(\.\d+)+`)
+
+// shortVersion extrai o número de versão da primeira linha de --version
+// ("gcc (GCC) 13.2.0" -> "13.2.0"); sem número, retorna a linha inteira.
+func shortVersion(line string) string {
+	if v := versionNumber.FindString(line); v != "" {
+		return v
+	}
+	return line
+}
+
+// sparkBlocks são os níveis do histórico, do menor ao maior.
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline desenha values como barras relativas ao maior valor.
+func sparkline(values []float64) string {
+	peak := 0.0
+	for _, v := range values {
+		peak = max(peak, v)
+	}
+	out := make([]rune, len(values))
+	for i, v := range values {
+		level := 0
+		if peak > 0 {
+			level = int(v / peak * float64(len(sparkBlocks)-1))
+		}
+		out[i] = sparkBlocks[level]
+	}
+	return string(out)
+}
+
+// RenderTargetTable escreve os alvos mais lentos com a tendência da duração.
+func RenderTargetTable(w io.Writer, report *metrics.TargetReport) {
+	format := func(sec float64) string { return metrics.FormatDuration(sec, metrics.DurationAuto, true) }
+	if len(report.Entries) == 0 {
+		fmt.Fprintln(w, "\nNenhum alvo registrado (meça o build com 'bmt make' ou 'bmt run ninja', ou importe com 'bmt ninja-log').")
+	} else {
+		fmt.Fprintf(w, "\nAlvos mais lentos (duração média em %d builds): \n", report.Builds)
+		fmt.Fprintln(w, "==========================================================================================")
+		fmt.Fprintf(w, "%-12s | %-6s | %-10s | %-10s | %-9s | %-9s | %s\n", "Projeto", "Builds", "Média", "Último", "Tendência", "Histórico", "Alvo")
+		fmt.Fprintln(w, "------------------------------------------------------------------------------------------")
+		for _, e := range report.Entries {
+			trend := "-"
+			if e.HasTrend {
+				trend = fmt.Sprintf("%+.1f%%", e.TrendPct)
+			}
+			fmt.Fprintf(w, "%-12s | %-6d | %-10s | %-10s | %-9s | %-9s | %s\n", truncate(e.Project, 12), e.Builds, format(e.AvgSec),
+				format(e.LastSec), trend, sparkline(e.History), e.Name)
+		}
+		fmt.Fprintln(w, "==========================================================================================")
+		fmt.Fprintln(w, "Tendência compara a média da metade recente dos builds com a da metade antiga (a partir de 4 builds).")
+	}
+	renderContended(w, report.Contended)
+	if report.Skipped > 0 || report.InvalidTimestamps > 0 {
+		fmt.Fprintf(w, "\nAviso: %d linhas inválidas e %d registros com timestamp inválido foram ignorados (verifique com 'bmt fsck').\n",
+			report.Skipped, report.InvalidTimestamps)
+	}
 }
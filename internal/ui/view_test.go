@@ -3,7 +3,9 @@ package ui_test
 import (
 	"bytes"
 	"dev-metrics/internal/metrics"
+	"dev-metrics/internal/stats"
 	"dev-metrics/internal/ui"
+	"strings"
 	"testing"
 	"time"
 )
@@ -128,6 +130,74 @@ func TestRenderReportTable(t *testing.T) {
 				"| 3min20s | -| 2",
 			},
 		},
+		{
+			name: "Com linhas ignoradas",
+			report: &metrics.FullReport{
+				Skipped:           3,
+				InvalidTimestamps: 1,
+			},
+			totalUnit: metrics.DurationSeconds,
+			wantSnips: []string{
+				"Aviso: 3 linhas inválidas e 1 registros com timestamp inválido foram ignorados",
+			},
+		},
+		{
+			name: "Agrupado por ferramenta",
+			report: &metrics.FullReport{
+				Projects: []metrics.ProjectSummary{
+					{
+						Name:          "cc1plus",
+						Weeks:         []metrics.WeeklySummary{{WeekLabel: "2026-W02", BuildStats: metrics.BuildStats{TotalDuration: 120, Count: 3}, AvgDuration: 40}},
+						TotalDuration: 120,
+						TotalBuilds:   3,
+					},
+				},
+				GlobalDuration: 120,
+				GlobalBuilds:   3,
+				ReportOptions:  metrics.ReportOptions{GroupBy: metrics.GroupByTool},
+			},
+			totalUnit: metrics.DurationSeconds,
+			wantSnips: []string{
+				"Ferramenta : cc1plus",
+				"Semana | CPU (s) | Média (auto) | Builds",
+				"2026-W02 | 120.0 | 40.0 s | 3 ",
+				"Totais em tempo de CPU",
+			},
+		},
+		{
+			name: "Agrupado por fase",
+			report: &metrics.FullReport{
+				Projects: []metrics.ProjectSummary{
+					{
+						Name:          "backend / compile",
+						Weeks:         []metrics.WeeklySummary{{WeekLabel: "2026-W02", BuildStats: metrics.BuildStats{TotalDuration: 60, Count: 2}, AvgDuration: 30}},
+						TotalDuration: 60,
+						TotalBuilds:   2,
+					},
+				},
+				ReportOptions: metrics.ReportOptions{GroupBy: metrics.GroupByPhase},
+			},
+			totalUnit: metrics.DurationSeconds,
+			wantSnips: []string{
+				"Fase : backend / compile",
+				"Semana | Total (s) | Média (auto) | Builds",
+				"2026-W02 | 60.0 | 30.0 s | 2 ",
+			},
+		},
+		{
+			name:      "Agrupado por fase sem registros",
+			report:    &metrics.FullReport{ReportOptions: metrics.ReportOptions{GroupBy: metrics.GroupByPhase}},
+			totalUnit: metrics.DurationSeconds,
+			wantSnips: []string{"Nenhum registro com fases"},
+		},
+		{
+			name:      "Agrupado por ferramenta sem registros",
+			report:    &metrics.FullReport{ReportOptions: metrics.ReportOptions{GroupBy: metrics.GroupByTool}},
+			totalUnit: metrics.DurationSeconds,
+			wantSnips: []string{
+				"Nenhum registro com detalhamento por ferramenta",
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -141,3 +211,181 @@ func TestRenderReportTable(t *testing.T) {
 		})
 	}
 }
+
+func TestRenderIOTable(t *testing.T) {
+	report := &metrics.IOReport{
+		Builds: []metrics.BuildIO{
+			{Timestamp: "2026-01-05T10:00:00Z", Project: "backend", Command: "make -j8", DurationSec: 90,
+				IOStats: metrics.IOStats{ReadBytes: 3 * 1024 * 1024, WriteBytes: 512, ReadChars: 2 * 1024 * 1024 * 1024}},
+		},
+		Total:     metrics.IOStats{ReadBytes: 3 * 1024 * 1024, WriteBytes: 512, ReadChars: 2 * 1024 * 1024 * 1024},
+		WithoutIO: 4,
+	}
+	var buf bytes.Buffer
+	ui.RenderIOTable(&buf, report)
+	got := buf.String()
+	snips := []string{
+		"Data | Projeto | Duração | Lido | Escrito | rchar | wchar | Espera I/O | Comando",
+		"2026-01-05T10:00:00Z | backend | 1min30s | 3.0 MB | 512 B | 2.0 GB | 0 B |",
+		"| make -j8",
+		"Total | 1 builds |",
+		"4 registros sem dados de I/O foram omitidos.",
+	}
+	if all, missing := containsAllSnips(got, snips); !all {
+		t.Errorf("RenderIOTable() output:\n%s\nMissing snippet: %s", got, missing)
+	}
+
+	buf.Reset()
+	ui.RenderIOTable(&buf, &metrics.IOReport{})
+	if !containsIgnoreSpaces(buf.String(), "Nenhum registro com dados de I/O no período.") {
+		t.Errorf("RenderIOTable() vazio:\n%s", buf.String())
+	}
+}
+
+func TestRenderFailureTable(t *testing.T) {
+	report := &metrics.FailureReport{
+		Entries: []metrics.FailureSummary{
+			{Project: "backend", Class: "test_failure", Count: 1, LostSec: 300},
+			{Project: "backend", Class: "compiler_error", Count: 2, LostSec: 150},
+			{Project: "frontend", Class: "unknown", Count: 1, LostSec: 20},
+		},
+		ByClass: []metrics.FailureSummary{
+			{Class: "test_failure", Count: 1, LostSec: 300},
+			{Class: "compiler_error", Count: 2, LostSec: 150},
+			{Class: "unknown", Count: 1, LostSec: 20},
+		},
+		Runs: 6, Failed: 4, LostSec: 470,
+	}
+	var buf bytes.Buffer
+	ui.RenderFailureTable(&buf, report, metrics.DurationSeconds)
+	snips := []string{
+		"Projeto : backend",
+		"Classe | Tempo perdido (s) | Falhas",
+		"test_failure | 300.0 | 1",
+		"compiler_error | 150.0 | 2",
+		"Projeto : frontend",
+		"Falhas por classe:",
+		"Total | 470.0 | 4 de 6 execuções",
+	}
+	if all, missing := containsAllSnips(buf.String(), snips); !all {
+		t.Errorf("RenderFailureTable() output:\n%s\nMissing snippet: %s", buf.String(), missing)
+	}
+}
+
+func TestRenderFlakyTable(t *testing.T) {
+	report := &metrics.FlakyReport{
+		Entries: []metrics.FlakyCommand{
+			{Project: "backend", Commit: "abc1234", Command: "[ctest -j8]", Failures: 2, Successes: 1, WastedSec: 300},
+			{Project: "frontend", Commit: "def5678", Command: "[npm test]", Failures: 1, Successes: 3, WastedSec: 20},
+		},
+		WastedSec: 320,
+	}
+	var buf bytes.Buffer
+	ui.RenderFlakyTable(&buf, report, metrics.DurationSeconds)
+	snips := []string{
+		"Comandos instáveis (falharam e passaram no mesmo commit):",
+		"Projeto | Commit | Tempo perdido (s) | Falhas | Sucessos | Comando",
+		"backend | abc1234 | 300.0 | 2 | 1 | [ctest -j8]",
+		"frontend | def5678 | 20.0 | 1 | 3 | [npm test]",
+		"Total | | 320.0 | 2 comandos",
+	}
+	if all, missing := containsAllSnips(buf.String(), snips); !all {
+		t.Errorf("RenderFlakyTable() output:\n%s\nMissing snippet: %s", buf.String(), missing)
+	}
+
+	buf.Reset()
+	ui.RenderFlakyTable(&buf, &metrics.FlakyReport{}, metrics.DurationAuto)
+	if !strings.Contains(buf.String(), "Nenhum comando instável") {
+		t.Errorf("RenderFlakyTable() vazio:\n%s", buf.String())
+	}
+}
+
+func TestRenderTargetTable(t *testing.T) {
+	report := &metrics.TargetReport{
+		Entries: []metrics.TargetTrend{
+			{Project: "engine", Name: "src/render.cc.o", Builds: 4, AvgSec: 12.5, LastSec: 15, TrendPct: 50, HasTrend: true, History: []float64{10, 10, 15, 20}},
+			{Project: "engine", Name: "gen", Builds: 1, AvgSec: 5, LastSec: 5, History: []float64{5}},
+		},
+		Builds: 4,
+	}
+	var buf bytes.Buffer
+	ui.RenderTargetTable(&buf, report)
+	snips := []string{
+		"Alvos mais lentos (duração média em 4 builds):",
+		"Projeto | Builds | Média | Último | Tendência | Histórico | Alvo",
+		"engine | 4 | 12.5 s | 15.0 s | +50.0% | ▄▄▆█ | src/render.cc.o",
+		"engine | 1 | 5.0 s | 5.0 s | - | █ | gen",
+	}
+	if all, missing := containsAllSnips(buf.String(), snips); !all {
+		t.Errorf("RenderTargetTable() output:\n%s\nMissing snippet: %s", buf.String(), missing)
+	}
+
+	buf.Reset()
+	ui.RenderTargetTable(&buf, &metrics.TargetReport{})
+	if !strings.Contains(buf.String(), "Nenhum alvo registrado") {
+		t.Errorf("RenderTargetTable() vazio:\n%s", buf.String())
+	}
+}
+
+func TestRenderCacheTable(t *testing.T) {
+	report := &metrics.CacheReport{
+		Entries: []metrics.CacheSummary{{
+			Project: "backend", Probe: "ccache", Builds: 3, Hits: 205, Misses: 95, HitRate: 205.0 / 300,
+			Buckets: []metrics.CacheBucket{
+				{Label: "0-25%", Count: 1, AvgDurationSec: 600},
+				{Label: "25-50%"},
+				{Label: "50-75%"},
+				{Label: "75-100%", Count: 2, AvgDurationSec: 50},
+			},
+		}},
+		WithoutCache: 2,
+	}
+	var buf bytes.Buffer
+	ui.RenderCacheTable(&buf, report)
+	snips := []string{
+		"Projeto : backend",
+		"Cache : ccache",
+		"Acerto : 68.3% (205 acertos, 95 falhas em 3 builds)",
+		"0-25% | 10min00s | 1",
+		"25-50% | - | 0",
+		"75-100% | 50.0 s | 2",
+		"2 builds sem contadores de cache foram omitidos.",
+	}
+	if all, missing := containsAllSnips(buf.String(), snips); !all {
+		t.Errorf("RenderCacheTable() output:\n%s\nMissing snippet: %s", buf.String(), missing)
+	}
+}
+
+func TestRenderToolchainTable(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 10, 0, 0, 0, time.UTC) }
+	report := &metrics.ToolchainReport{
+		Projects: []metrics.ToolchainProject{{Name: "backend", Periods: []metrics.ToolchainPeriod{
+			{Fingerprint: "aaa", FirstRun: day(1), LastRun: day(2), Versions: map[string]string{"gcc": "gcc (GCC) 12.2.0"},
+				Duration: stats.Summary{Count: 2, P10: 100, Median: 110, P90: 120}},
+			{Fingerprint: "bbb", FirstRun: day(3), LastRun: day(4), Versions: map[string]string{"gcc": "gcc (GCC) 13.2.0", "go": "go version go1.22.1 linux/amd64"},
+				Changes: []metrics.ToolchainChange{
+					{Tool: "gcc", From: "gcc (GCC) 12.2.0", To: "gcc (GCC) 13.2.0"},
+					{Tool: "go", To: "go version go1.22.1 linux/amd64"},
+				},
+				Duration: stats.Summary{Count: 2, P10: 200, Median: 220, P90: 240}},
+			{Fingerprint: "ccc", FirstRun: day(5), LastRun: day(5), Duration: stats.Summary{Count: 1, P10: 110, Median: 110, P90: 110}},
+		}}},
+		WithoutToolchain: 3,
+	}
+	var buf bytes.Buffer
+	ui.RenderToolchainTable(&buf, report)
+	snips := []string{
+		"Projeto : backend",
+		"Toolchain | Período | Builds | p10 | Mediana | p90 | Δ mediana",
+		"aaa | 2024-01-01 a 2024-01-02 | 2 | 1min40s | 1min50s | 2min00s | -",
+		"bbb | 2024-01-03 a 2024-01-04 | 2 | 3min20s | 3min40s | 4min00s | +100.0%",
+		"~ gcc 12.2.0 -> 13.2.0",
+		"+ go 1.22.1",
+		"ccc | 2024-01-05 a 2024-01-05 | 1 | 1min50s | 1min50s | 1min50s | -50.0%",
+		"(versões desconhecidas",
+		"3 builds sem impressão digital de toolchain foram omitidos.",
+	}
+	if all, missing := containsAllSnips(buf.String(), snips); !all {
+		t.Errorf("RenderToolchainTable() output:\n%s\nMissing snippet: %s", buf.String(), missing)
+	}
+}